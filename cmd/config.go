@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cateiru/system-prompt-gen/internal/config"
+	"github.com/cateiru/system-prompt-gen/internal/i18n"
+	"github.com/cateiru/system-prompt-gen/internal/util"
+)
+
+var (
+	showResolved  bool
+	convertTo     string
+	convertOutput string
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect settings",
+	Long:  "system-prompt-gen config provides subcommands to inspect and convert settings files.",
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show settings",
+	Long:  "system-prompt-gen config show prints settings.toml.\nWith --resolved, files listed in extends are merged and the origin of every value is shown.\nThe profile selected with --profile, SPG_* environment variables and --set are applied to the resolved settings.",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runConfigShow(cmd); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var configConvertCmd = &cobra.Command{
+	Use:   "convert",
+	Short: "Convert settings to another format",
+	Long:  "system-prompt-gen config convert converts the settings file to TOML, YAML or JSON.\nThe output format is taken from --to, or from the extension of --output.\nComments are not preserved, and files listed in extends are not merged.",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runConfigConvert(cmd); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	configShowCmd.Flags().BoolVar(&showResolved, "resolved", false, "Merge extended settings and show the origin of every value")
+	configConvertCmd.Flags().StringVar(&convertTo, "to", "", "Output format (toml, yaml or json)")
+	configConvertCmd.Flags().StringVarP(&convertOutput, "output", "o", "", "Write the converted settings to this file instead of stdout")
+
+	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configConvertCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+func runConfigShow(cmd *cobra.Command) error {
+	// i18nシステムの初期化
+	if err := i18n.Initialize(language); err != nil {
+		// i18n初期化に失敗した場合でも処理を続行
+		fmt.Fprintf(os.Stderr, "Warning: Failed to initialize i18n: %v\n", err)
+	}
+
+	settingsPath := config.FindSettingsFile(settingFile)
+	if _, err := os.Stat(settingsPath); os.IsNotExist(err) {
+		return fmt.Errorf("%s", i18n.T("config_file_not_found", map[string]any{"Path": settingsPath}))
+	}
+
+	if !showResolved {
+		content, err := os.ReadFile(settingsPath)
+		if err != nil {
+			return fmt.Errorf("%s", i18n.T("config_load_error", map[string]any{"Error": err.Error()}))
+		}
+		fmt.Fprint(cmd.OutOrStdout(), string(content))
+		return nil
+	}
+
+	resolved, err := config.ResolveSettingsWithRoot(settingsPath, projectRoot)
+	if err != nil {
+		return fmt.Errorf("%s", i18n.T("config_load_error", map[string]any{"Error": err.Error()}))
+	}
+
+	if profile != "" {
+		if err := resolved.ApplyProfile(profile); err != nil {
+			return fmt.Errorf("%s", i18n.T("config_load_error", map[string]any{"Error": err.Error()}))
+		}
+	}
+
+	overrides, unknownEnv, err := config.ParseOverrides(os.Environ(), setValues)
+	if err != nil {
+		return fmt.Errorf("%s", i18n.T("config_load_error", map[string]any{"Error": err.Error()}))
+	}
+	printUnknownEnv(cmd, unknownEnv)
+	if strictMode && len(unknownEnv) > 0 {
+		return fmt.Errorf("%s", i18n.T("unknown_env_strict_error", map[string]any{"Count": len(unknownEnv)}))
+	}
+	resolved.ApplyOverrides(overrides)
+
+	content, err := resolved.Format()
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(cmd.OutOrStdout(), content)
+
+	return nil
+}
+
+func runConfigConvert(cmd *cobra.Command) error {
+	// i18nシステムの初期化
+	if err := i18n.Initialize(language); err != nil {
+		// i18n初期化に失敗した場合でも処理を続行
+		fmt.Fprintf(os.Stderr, "Warning: Failed to initialize i18n: %v\n", err)
+	}
+
+	settingsPath := config.FindSettingsFile(settingFile)
+	if _, err := os.Stat(settingsPath); os.IsNotExist(err) {
+		return fmt.Errorf("%s", i18n.T("config_file_not_found", map[string]any{"Path": settingsPath}))
+	}
+
+	// --to が省略された場合は --output の拡張子から形式を判定する
+	var format config.Format
+	var err error
+	switch {
+	case convertTo != "":
+		format, err = config.ParseFormat(convertTo)
+	case convertOutput != "" && filepath.Ext(convertOutput) != "":
+		format, err = config.FormatFromPath(convertOutput)
+	default:
+		return fmt.Errorf("%s", i18n.T("convert_format_required"))
+	}
+	if err != nil {
+		return err
+	}
+
+	content, err := config.ConvertSettings(settingsPath, format)
+	if err != nil {
+		return fmt.Errorf("%s", i18n.T("config_load_error", map[string]any{"Error": err.Error()}))
+	}
+
+	if convertOutput == "" {
+		fmt.Fprint(cmd.OutOrStdout(), string(content))
+		return nil
+	}
+
+	if err := os.WriteFile(convertOutput, content, 0644); err != nil {
+		return err
+	}
+	cmd.PrintErrf("%s\n", i18n.T("config_converted", map[string]any{
+		"Source": util.ToRelativePath(settingsPath),
+		"Path":   util.ToRelativePath(convertOutput),
+	}))
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunConfigShowResolved(t *testing.T) {
+	tempDir := t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "shared.toml"), []byte("[app]\nfooter = \"shared footer\"\n"), 0644))
+	settingsPath := filepath.Join(tempDir, "settings.toml")
+	require.NoError(t, os.WriteFile(settingsPath, []byte("extends = [\"shared.toml\"]\n\n[app]\nheader = \"header\"\n"), 0644))
+
+	originalSettingFile, originalShowResolved := settingFile, showResolved
+	t.Cleanup(func() {
+		settingFile, showResolved = originalSettingFile, originalShowResolved
+	})
+	settingFile = settingsPath
+	showResolved = true
+
+	var out bytes.Buffer
+	configShowCmd.SetOut(&out)
+	t.Cleanup(func() { configShowCmd.SetOut(nil) })
+
+	err := runConfigShow(configShowCmd)
+	require.NoError(t, err)
+
+	assert.Contains(t, out.String(), "[app]")
+	assert.Contains(t, out.String(), "footer = \"shared footer\"  # ")
+	assert.Contains(t, out.String(), "shared.toml")
+	assert.Contains(t, out.String(), "header = \"header\"  # ")
+}
+
+func TestRunConfigShowMissingFile(t *testing.T) {
+	originalSettingFile := settingFile
+	t.Cleanup(func() { settingFile = originalSettingFile })
+	settingFile = filepath.Join(t.TempDir(), "settings.toml")
+
+	err := runConfigShow(configShowCmd)
+	assert.Error(t, err)
+}
+
+func TestRunConfigConvert(t *testing.T) {
+	tempDir := t.TempDir()
+
+	settingsPath := filepath.Join(tempDir, "settings.toml")
+	require.NoError(t, os.WriteFile(settingsPath, []byte("[app]\nheader = \"header\"\n"), 0644))
+
+	originalSettingFile, originalTo, originalOutput := settingFile, convertTo, convertOutput
+	t.Cleanup(func() {
+		settingFile, convertTo, convertOutput = originalSettingFile, originalTo, originalOutput
+	})
+	settingFile = settingsPath
+
+	t.Run("stdout", func(t *testing.T) {
+		convertTo, convertOutput = "yaml", ""
+
+		var out bytes.Buffer
+		configConvertCmd.SetOut(&out)
+		t.Cleanup(func() { configConvertCmd.SetOut(nil) })
+
+		require.NoError(t, runConfigConvert(configConvertCmd))
+		assert.Equal(t, "app:\n    header: header\n", out.String())
+	})
+
+	t.Run("format from output extension", func(t *testing.T) {
+		outputPath := filepath.Join(tempDir, "settings.json")
+		convertTo, convertOutput = "", outputPath
+
+		require.NoError(t, runConfigConvert(configConvertCmd))
+
+		content, err := os.ReadFile(outputPath)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"app": {"header": "header"}}`, string(content))
+	})
+
+	t.Run("format required", func(t *testing.T) {
+		convertTo, convertOutput = "", ""
+		assert.Error(t, runConfigConvert(configConvertCmd))
+	})
+}
@@ -1,8 +1,12 @@
 package cmd
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 
 	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
@@ -11,10 +15,24 @@ import (
 	initpkg "github.com/cateiru/system-prompt-gen/internal/init"
 )
 
+var (
+	initYes      bool
+	initTools    []string
+	initImport   []string
+	initForce    bool
+	initMerge    bool
+	initSplit    bool
+	initShared   bool
+	initJSON     bool
+	initVerify   bool
+	initTemplate string
+	initAnalyze  bool
+)
+
 var initCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Initialize project setup",
-	Long:  "system-prompt-gen init creates a .system_prompt folder in the current directory,\ndetects existing system prompt files, and initializes the project.",
+	Long:  "system-prompt-gen init creates a .system_prompt folder in the current directory,\ndetects existing system prompt files, and initializes the project.\nWith --yes, init runs without the interactive UI using --tools, --import and --force.\nWith --verify, init compares the generated output with the original files.",
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := runInit(cmd); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -24,6 +42,22 @@ var initCmd = &cobra.Command{
 }
 
 func init() {
+	initCmd.Flags().BoolVarP(&initYes, "yes", "y", false, "Run without the interactive UI")
+	initCmd.Flags().StringSliceVar(&initTools, "tools", nil, "Tools to enable with --yes (default: all built-in tools)")
+	initCmd.Flags().StringSliceVar(&initImport, "import", nil, "Files to import into 001_default.md with --yes")
+	initCmd.Flags().BoolVar(&initForce, "force", false, "Overwrite an existing .system_prompt directory with --yes (replaced files are backed up)")
+	initCmd.Flags().BoolVar(&initMerge, "merge", false, "Keep the prompt files of an existing .system_prompt directory and add tools to its settings with --yes")
+	initCmd.Flags().BoolVar(&initSplit, "split", false, "Split imported files by heading into numbered files with --yes")
+	initCmd.Flags().BoolVar(&initShared, "shared", false, "Separate content shared by the imported files from tool-specific content with --yes")
+	initCmd.Flags().StringVar(&initTemplate, "template", "", "Starter template to use with --yes: go-service, frontend, library, minimal, a template in "+initpkg.DefaultUserTemplateDir+" or a directory")
+	initCmd.Flags().BoolVar(&initAnalyze, "analyze", false, "Draft prompt files describing the build, test and lint commands and the directory layout with --yes (not with --import)")
+	initCmd.Flags().BoolVar(&initJSON, "json", false, "Print the summary as JSON with --yes or --verify")
+	initCmd.Flags().BoolVar(&initVerify, "verify", false, "Show a per-tool diff between the generated output and the original files")
+
+	initCmd.MarkFlagsMutuallyExclusive("split", "shared")
+	initCmd.MarkFlagsMutuallyExclusive("force", "merge")
+	initCmd.MarkFlagsMutuallyExclusive("analyze", "import")
+
 	rootCmd.AddCommand(initCmd)
 }
 
@@ -34,11 +68,142 @@ func runInit(cmd *cobra.Command) error {
 		fmt.Fprintf(os.Stderr, "Warning: Failed to initialize i18n: %v\n", err)
 	}
 
-	// TTY検証 - initコマンドはインタラクティブモードのみサポート
+	if initYes {
+		return runNonInteractiveInit(cmd)
+	}
+	for _, name := range []string{"tools", "import", "force", "merge", "split", "shared", "template", "analyze", "json"} {
+		if name == "json" && initVerify {
+			continue
+		}
+		if cmd.Flags().Changed(name) {
+			return fmt.Errorf("%s", i18n.T("init_flag_requires_yes", map[string]any{"Flag": "--" + name}))
+		}
+	}
+	if initVerify {
+		return runVerifyInit(cmd)
+	}
+
+	// TTY検証 - --yes を指定しない場合はインタラクティブモードのみサポート
 	if !isatty.IsTerminal(os.Stdout.Fd()) && !isatty.IsCygwinTerminal(os.Stdout.Fd()) {
 		return fmt.Errorf("%s", i18n.T("init_requires_tty"))
 	}
 
 	// init処理を実行
 	return initpkg.RunInit()
-}
\ No newline at end of file
+}
+
+// runNonInteractiveInit は UI を使わずに init を実行し、作成したファイルを表示する
+func runNonInteractiveInit(cmd *cobra.Command) error {
+	summary, err := initpkg.RunNonInteractive(initpkg.Options{
+		Tools:    initTools,
+		Import:   initImport,
+		Force:    initForce,
+		Merge:    initMerge,
+		Split:    initSplit,
+		Shared:   initShared,
+		Verify:   initVerify,
+		Template: initTemplate,
+		Analyze:  initAnalyze,
+	})
+	if errors.Is(err, initpkg.ErrSystemPromptDirExists) {
+		return fmt.Errorf("%s", i18n.T("init_exists_use_force"))
+	}
+	if err != nil {
+		return err
+	}
+
+	if initJSON {
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(summary); err != nil {
+			return err
+		}
+		return verificationError(summary.Verification)
+	}
+
+	cmd.PrintErrf("%s\n", i18n.T("init_success_title"))
+	for _, file := range summary.CreatedFiles {
+		cmd.PrintErrf("  %s\n", i18n.T("init_summary_created", map[string]any{"Path": file}))
+	}
+	for _, file := range summary.UpdatedFiles {
+		cmd.PrintErrf("  %s\n", i18n.T("init_summary_updated", map[string]any{"Path": file}))
+	}
+	if summary.BackupDir != "" {
+		cmd.PrintErrf("  %s\n", i18n.T("init_summary_backup", map[string]any{"Path": summary.BackupDir}))
+	}
+	if summary.Template != "" {
+		cmd.PrintErrf("  %s\n", i18n.T("init_summary_template", map[string]any{"Template": summary.Template}))
+	}
+	for _, file := range summary.ImportedFiles {
+		cmd.PrintErrf("  %s\n", i18n.T("init_summary_imported", map[string]any{"Path": file}))
+	}
+	for _, section := range summary.DuplicateSections {
+		cmd.PrintErrf("  %s\n", i18n.T("init_summary_duplicate", map[string]any{"Title": section.Title, "Path": section.Source}))
+	}
+	var excludeTools []string
+	for tool := range summary.Excludes {
+		excludeTools = append(excludeTools, tool)
+	}
+	sort.Strings(excludeTools)
+	for _, tool := range excludeTools {
+		cmd.PrintErrf("  %s\n", i18n.T("init_summary_exclude", map[string]any{"Tool": tool, "Files": strings.Join(summary.Excludes[tool], ", ")}))
+	}
+	cmd.PrintErrf("  %s\n", i18n.T("init_summary_tools", map[string]any{"Tools": strings.Join(summary.Tools, ", ")}))
+
+	if initVerify {
+		printVerification(cmd, summary.Verification)
+	}
+	return verificationError(summary.Verification)
+}
+
+// runVerifyInit は既存の .system_prompt の設定で生成した結果と元のファイルの差分を表示する
+func runVerifyInit(cmd *cobra.Command) error {
+	results, err := initpkg.RunVerify()
+	if err != nil {
+		return err
+	}
+
+	if initJSON {
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		if results == nil {
+			results = []initpkg.VerifyResult{}
+		}
+		if err := encoder.Encode(results); err != nil {
+			return err
+		}
+	} else {
+		printVerification(cmd, results)
+	}
+	return verificationError(results)
+}
+
+// printVerification はツールごとに差分と比較結果を表示する
+func printVerification(cmd *cobra.Command, results []initpkg.VerifyResult) {
+	out := cmd.OutOrStdout()
+	if len(results) == 0 {
+		fmt.Fprintln(out, i18n.T("init_verify_no_files"))
+		return
+	}
+
+	for i, result := range results {
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintf(out, "%s: %s\n", result.Tool, result.Path)
+		if result.Generated {
+			for _, line := range result.Diff {
+				fmt.Fprintf(out, "  %s %s\n", line.Op, line.Text)
+			}
+		}
+		fmt.Fprintf(out, "  %s\n", result.Message())
+	}
+}
+
+// verificationError は内容の欠落がある場合にエラーを返す
+func verificationError(results []initpkg.VerifyResult) error {
+	if initpkg.HasContentLoss(results) {
+		return fmt.Errorf("%s", i18n.T("init_verify_failed"))
+	}
+	return nil
+}
@@ -1,10 +1,16 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/cateiru/system-prompt-gen/internal/i18n"
+	initpkg "github.com/cateiru/system-prompt-gen/internal/init"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestInitCommandHelp(t *testing.T) {
@@ -32,4 +38,69 @@ func TestRunInitWithoutTTY(t *testing.T) {
 	// TTYエラーが発生することを確認（具体的なメッセージ内容はi18nに依存するため、エラーの存在のみ確認）
 	assert.Error(t, err)
 	assert.NotEmpty(t, err.Error())
-}
\ No newline at end of file
+}
+
+func TestRunInitNonInteractive(t *testing.T) {
+	require.NoError(t, i18n.Initialize("en"))
+	t.Chdir(t.TempDir())
+
+	originalYes, originalTools, originalJSON := initYes, initTools, initJSON
+	t.Cleanup(func() {
+		initYes, initTools, initJSON = originalYes, originalTools, originalJSON
+	})
+	initYes, initTools, initJSON = true, []string{"claude"}, true
+
+	var out bytes.Buffer
+	initCmd.SetOut(&out)
+	t.Cleanup(func() { initCmd.SetOut(nil) })
+
+	require.NoError(t, runInit(initCmd))
+
+	var summary initpkg.Summary
+	require.NoError(t, json.Unmarshal(out.Bytes(), &summary))
+	assert.Equal(t, []string{"claude"}, summary.Tools)
+	assert.FileExists(t, filepath.Join(".system_prompt", "settings.toml"))
+
+	// 2 回目は --force が必要
+	err := runInit(initCmd)
+	assert.ErrorContains(t, err, "--force")
+	_, statErr := os.Stat(filepath.Join(".system_prompt", "001_default.md"))
+	assert.NoError(t, statErr)
+
+	// --merge では既存のディレクトリに追加する
+	originalMerge := initMerge
+	t.Cleanup(func() { initMerge = originalMerge })
+	initMerge = true
+	out.Reset()
+	require.NoError(t, runInit(initCmd))
+	require.NoError(t, json.Unmarshal(out.Bytes(), &summary))
+	assert.True(t, summary.Merged)
+}
+
+func TestRunInitVerify(t *testing.T) {
+	require.NoError(t, i18n.Initialize("en"))
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.WriteFile("CLAUDE.md", []byte("# Rules\n\nUse gofmt.\n"), 0644))
+
+	originalYes, originalImport, originalJSON, originalVerify, originalLanguage := initYes, initImport, initJSON, initVerify, language
+	t.Cleanup(func() {
+		initYes, initImport, initJSON, initVerify, language = originalYes, originalImport, originalJSON, originalVerify, originalLanguage
+	})
+	language = "en"
+
+	var out bytes.Buffer
+	initCmd.SetOut(&out)
+	t.Cleanup(func() { initCmd.SetOut(nil) })
+
+	initYes, initImport, initJSON, initVerify = true, []string{"CLAUDE.md"}, false, true
+	require.NoError(t, runInit(initCmd))
+	assert.Contains(t, out.String(), "claude: CLAUDE.md\n  + # Imported from CLAUDE.md\n  ✅")
+
+	// 元のファイルの内容が生成結果に含まれない場合はエラーになる
+	require.NoError(t, os.WriteFile("CLAUDE.md", []byte("# Rules\n\nUse gofmt.\n\nNew rule.\n"), 0644))
+	out.Reset()
+	initYes, initImport = false, nil
+	err := runInit(initCmd)
+	assert.ErrorContains(t, err, "would be lost")
+	assert.Contains(t, out.String(), "  - New rule.\n")
+}
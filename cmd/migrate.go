@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cateiru/system-prompt-gen/internal/config"
+	"github.com/cateiru/system-prompt-gen/internal/i18n"
+	"github.com/cateiru/system-prompt-gen/internal/util"
+)
+
+var migrateDryRun bool
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Migrate settings to the current format version",
+	Long:  "system-prompt-gen migrate rewrites the settings file to the current format version.\nComments in TOML and YAML files are kept. Files listed in extends are not migrated;\nrun migrate with -s for each of them.",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runMigrate(cmd); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	migrateCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "Print the migrated settings instead of writing the file")
+
+	rootCmd.AddCommand(migrateCmd)
+}
+
+func runMigrate(cmd *cobra.Command) error {
+	// i18nシステムの初期化
+	if err := i18n.Initialize(language); err != nil {
+		// i18n初期化に失敗した場合でも処理を続行
+		fmt.Fprintf(os.Stderr, "Warning: Failed to initialize i18n: %v\n", err)
+	}
+
+	settingsPath := config.FindSettingsFile(settingFile)
+	if _, err := os.Stat(settingsPath); os.IsNotExist(err) {
+		return fmt.Errorf("%s", i18n.T("config_file_not_found", map[string]any{"Path": settingsPath}))
+	}
+
+	// version 1 では相対パスがプロジェクトルート（-s 指定時はカレントディレクトリ）から解決されていた
+	oldBaseDir := projectRoot
+	if oldBaseDir == "" {
+		currentDir, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		oldBaseDir = currentDir
+	}
+
+	result, err := config.MigrateSettings(settingsPath, oldBaseDir)
+	if err != nil {
+		return fmt.Errorf("%s", i18n.T("config_load_error", map[string]any{"Error": err.Error()}))
+	}
+
+	relPath := util.ToRelativePath(settingsPath)
+	if result.FromVersion == result.ToVersion {
+		cmd.PrintErrf("%s\n", i18n.T("migrate_up_to_date", map[string]any{"Path": relPath, "Version": result.ToVersion}))
+		return nil
+	}
+
+	for _, change := range result.Changes {
+		cmd.PrintErrf("  %s\n", i18n.T("migrate_change_rebased", map[string]any{
+			"Key": change.Key,
+			"Old": change.Old,
+			"New": change.New,
+		}))
+	}
+
+	if migrateDryRun {
+		fmt.Fprint(cmd.OutOrStdout(), string(result.Content))
+		return nil
+	}
+
+	if err := saveSettingsFile(settingsPath, result.Content); err != nil {
+		return err
+	}
+
+	cmd.PrintErrf("%s\n", i18n.T("migrate_done", map[string]any{
+		"Path":        relPath,
+		"FromVersion": result.FromVersion,
+		"ToVersion":   result.ToVersion,
+	}))
+	return nil
+}
+
+// saveSettingsFile は既存の設定ファイルを、元のパーミッションのまま content で上書きする
+func saveSettingsFile(settingsPath string, content []byte) error {
+	info, err := os.Stat(settingsPath)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(settingsPath, content, info.Mode().Perm())
+}
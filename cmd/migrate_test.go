@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunMigrate(t *testing.T) {
+	projectDir := t.TempDir()
+	settingsPath := filepath.Join(projectDir, ".system_prompt", "settings.toml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(settingsPath), 0755))
+
+	original := "# comment\n[app]\noutput_dir = \"docs\"\n"
+	require.NoError(t, os.WriteFile(settingsPath, []byte(original), 0644))
+
+	originalSettingFile, originalProjectRoot, originalDryRun := settingFile, projectRoot, migrateDryRun
+	t.Cleanup(func() {
+		settingFile, projectRoot, migrateDryRun = originalSettingFile, originalProjectRoot, originalDryRun
+	})
+	settingFile, projectRoot = settingsPath, projectDir
+
+	t.Run("dry run", func(t *testing.T) {
+		migrateDryRun = true
+
+		var out bytes.Buffer
+		migrateCmd.SetOut(&out)
+		t.Cleanup(func() { migrateCmd.SetOut(nil) })
+
+		require.NoError(t, runMigrate(migrateCmd))
+		assert.Equal(t, "# comment\nversion = 2\n\n[app]\noutput_dir = \"../docs\"\n", out.String())
+
+		content, err := os.ReadFile(settingsPath)
+		require.NoError(t, err)
+		assert.Equal(t, original, string(content))
+	})
+
+	t.Run("write", func(t *testing.T) {
+		migrateDryRun = false
+
+		require.NoError(t, runMigrate(migrateCmd))
+
+		content, err := os.ReadFile(settingsPath)
+		require.NoError(t, err)
+		assert.Equal(t, "# comment\nversion = 2\n\n[app]\noutput_dir = \"../docs\"\n", string(content))
+
+		// 移行済みのファイルは変更しない
+		require.NoError(t, runMigrate(migrateCmd))
+		unchanged, err := os.ReadFile(settingsPath)
+		require.NoError(t, err)
+		assert.Equal(t, content, unchanged)
+	})
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cateiru/system-prompt-gen/internal/config"
+	"github.com/cateiru/system-prompt-gen/internal/i18n"
+	"github.com/cateiru/system-prompt-gen/internal/util"
+)
+
+// --no-config に指定できる値
+const (
+	// noConfigError は設定ファイルがない場合にエラーにする
+	noConfigError = "error"
+	// noConfigDefaults は設定ファイルがない場合にデフォルト設定を使用する
+	noConfigDefaults = "defaults"
+)
+
+// settingsFileExists は -s の設定ファイル（または同名の YAML/JSON ファイル）が存在するかを返す
+func settingsFileExists() bool {
+	_, err := os.Stat(config.FindSettingsFile(settingFile))
+	return err == nil
+}
+
+// confirmInit は設定ファイルがないことを伝え、init を実行するかを確認する
+func confirmInit(cmd *cobra.Command) bool {
+	cmd.PrintErrf("%s", i18n.T("no_config_prompt", map[string]any{"Path": util.ToRelativePath(settingFile)}))
+
+	answer, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	if err != nil && answer == "" {
+		return false
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "", "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
+// printDefaultsNotice は設定ファイルがないため使用しているデフォルト設定を表示する
+func printDefaultsNotice(cmd *cobra.Command, settings *config.Settings) {
+	var files []string
+	for _, tool := range settings.Tools {
+		files = append(files, filepath.Join(string(tool.DirName), string(tool.FileName)))
+	}
+	sort.Strings(files)
+
+	var layers []string
+	for _, layer := range settings.App.Layers() {
+		layers = append(layers, util.ToRelativePath(layer))
+	}
+
+	outputDir := settings.App.OutputDir
+	if outputDir == "" {
+		outputDir = "."
+	}
+
+	cmd.PrintErrf("%s\n", i18n.T("no_config_notice", map[string]any{
+		"Path":      util.ToRelativePath(settingFile),
+		"InputDir":  strings.Join(layers, ", "),
+		"OutputDir": util.ToRelativePath(outputDir),
+		"Files":     strings.Join(files, ", "),
+	}))
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cateiru/system-prompt-gen/internal/i18n"
+)
+
+func TestConfirmInit(t *testing.T) {
+	require.NoError(t, i18n.Initialize("en"))
+
+	for input, expected := range map[string]bool{
+		"\n":    true,
+		"y\n":   true,
+		"YES\n": true,
+		"n\n":   false,
+		"no\n":  false,
+		"":      false,
+	} {
+		var out bytes.Buffer
+		rootCmd.SetIn(strings.NewReader(input))
+		rootCmd.SetErr(&out)
+
+		assert.Equal(t, expected, confirmInit(rootCmd), "input %q", input)
+		assert.Contains(t, out.String(), "[Y/n]")
+	}
+	rootCmd.SetIn(nil)
+	rootCmd.SetErr(nil)
+}
+
+func TestLoadSettingsWithoutConfig(t *testing.T) {
+	require.NoError(t, i18n.Initialize("en"))
+
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+
+	originalSettingFile, originalPolicy := settingFile, noConfigPolicy
+	t.Cleanup(func() {
+		settingFile, noConfigPolicy = originalSettingFile, originalPolicy
+	})
+	settingFile = filepath.Join(tempDir, ".system_prompt", "settings.toml")
+
+	t.Run("defaults with notice", func(t *testing.T) {
+		for _, policy := range []string{"", noConfigDefaults} {
+			noConfigPolicy = policy
+
+			var out bytes.Buffer
+			rootCmd.SetErr(&out)
+			t.Cleanup(func() { rootCmd.SetErr(nil) })
+
+			settings, err := loadSettings(rootCmd)
+			require.NoError(t, err)
+			assert.Len(t, settings.Tools, 4)
+
+			assert.Contains(t, out.String(), "No settings file found at .system_prompt/settings.toml")
+			assert.Contains(t, out.String(), ".clinerules, .github/copilot-instructions.md, AGENTS.md, CLAUDE.md")
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		noConfigPolicy = noConfigError
+
+		_, err := loadSettings(rootCmd)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "system-prompt-gen init")
+	})
+
+	t.Run("invalid policy", func(t *testing.T) {
+		noConfigPolicy = "ask"
+
+		_, err := loadSettings(rootCmd)
+		assert.Error(t, err)
+	})
+}
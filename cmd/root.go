@@ -1,9 +1,10 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
-	"path"
+	"path/filepath"
 
 	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
@@ -11,6 +12,7 @@ import (
 	"github.com/cateiru/system-prompt-gen/internal/config"
 	"github.com/cateiru/system-prompt-gen/internal/generator"
 	"github.com/cateiru/system-prompt-gen/internal/i18n"
+	initpkg "github.com/cateiru/system-prompt-gen/internal/init"
 	"github.com/cateiru/system-prompt-gen/internal/ui"
 	"github.com/cateiru/system-prompt-gen/internal/util"
 )
@@ -19,12 +21,27 @@ var (
 	settingFile     string
 	interactiveMode bool
 	language        string
+	profile         string
+	strictMode      bool
+	setValues       []string
+	workDir         string
+	noConfigPolicy  string
+	// projectRoot は自動検出した設定ファイルのプロジェクトルート（-s 指定時は空）
+	projectRoot string
 )
 
 var rootCmd = &cobra.Command{
 	Use:   "system-prompt-gen",
 	Short: "Tool to integrate system prompt files",
 	Long:  "system-prompt-gen collects .system_prompt/*.md files and integrates them into single files like CLAUDE.md and .clinerules.",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := prepareSettingFile(); err != nil {
+			// フラグの誤りではないため使い方は表示しない
+			cmd.SilenceUsage = true
+			return err
+		}
+		return nil
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := runWithCmd(cmd); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -40,15 +57,14 @@ func Execute() {
 }
 
 func init() {
-	currentDir, err := os.Getwd()
-	if err != nil {
-		panic(err)
-	}
-	defaultSettingFullPath := path.Join(currentDir, ".system_prompt", "settings.toml")
-
-	rootCmd.PersistentFlags().StringVarP(&settingFile, "setting", "s", defaultSettingFullPath, "Path to settings.toml config file")
+	rootCmd.PersistentFlags().StringVarP(&settingFile, "setting", "s", "", "Path to settings.toml config file (default: nearest .system_prompt/settings.toml)")
+	rootCmd.PersistentFlags().StringVarP(&workDir, "directory", "C", "", "Run as if started in this directory")
 	rootCmd.PersistentFlags().BoolVarP(&interactiveMode, "interactive", "i", true, "Launch in interactive mode")
 	rootCmd.PersistentFlags().StringVarP(&language, "language", "l", "", "Language setting (ja, en, or empty for auto-detect)")
+	rootCmd.PersistentFlags().StringVarP(&profile, "profile", "p", "", "Profile defined in [profiles.NAME] to use")
+	rootCmd.PersistentFlags().BoolVar(&strictMode, "strict", false, "Treat unknown keys in settings files and SPG_* environment variables that match no setting as errors")
+	rootCmd.PersistentFlags().StringVar(&noConfigPolicy, "no-config", "", "What to do when no settings file exists: error or defaults (default: offer init in interactive mode, otherwise use defaults)")
+	rootCmd.PersistentFlags().StringArrayVar(&setValues, "set", nil, "Override a setting as key=value (repeatable, e.g. --set tools.cline.exclude='[\"x.md\"]')")
 }
 
 func runWithCmd(cmd *cobra.Command) error {
@@ -58,12 +74,6 @@ func runWithCmd(cmd *cobra.Command) error {
 		fmt.Fprintf(os.Stderr, "Warning: Failed to initialize i18n: %v\n", err)
 	}
 
-	// settings.tomlの読み込みを試行
-	settings, err := config.LoadSettings(settingFile)
-	if err != nil {
-		return fmt.Errorf("%s", i18n.T("config_load_error", map[string]any{"Error": err.Error()}))
-	}
-
 	// i18n初期化後にコマンドの説明を更新（NOTE: 実行時に行う）
 
 	// TTY検出による自動フォールバック
@@ -73,23 +83,166 @@ func runWithCmd(cmd *cobra.Command) error {
 		cmd.Printf("%s\n", i18n.T("tty_fallback_message"))
 	}
 
+	// 設定ファイルがない場合、インタラクティブモードでは init の実行を提案する
+	if effectiveInteractiveMode && noConfigPolicy == "" && !settingsFileExists() && isatty.IsTerminal(os.Stdin.Fd()) {
+		if confirmInit(cmd) {
+			return initpkg.RunInit()
+		}
+	}
+
+	// settings.tomlの読み込みを試行
+	settings, err := loadSettings(cmd)
+	if err != nil {
+		return err
+	}
+
 	if effectiveInteractiveMode {
-		return ui.RunInteractive(settings)
+		return ui.RunInteractive(settings, func(name string) (*config.Settings, error) {
+			options := loadOptions()
+			options.Profile = name
+			return config.LoadSettingsWithOptions(settingFile, options)
+		})
 	}
 
 	gen := generator.New(settings)
 	if err := gen.Run(); err != nil {
 		return err
 	}
+	for _, warning := range gen.BudgetWarnings() {
+		cmd.PrintErrf("⚠️ %s\n", warning)
+	}
 
 	files, _ := gen.CollectPromptFiles()
 	cmd.Printf("%s\n", i18n.T("files_processed", map[string]any{"Count": len(files)}))
 
 	// 生成されたファイルの一覧を表示
-	targets := gen.GetGeneratedTargets()
+	targets, err := gen.GetGeneratedTargets()
+	if err != nil {
+		return err
+	}
 	for _, target := range targets {
 		cmd.Printf("%s\n", i18n.T("file_generated", map[string]any{"FileName": util.ToRelativePath(target)}))
 	}
 
 	return nil
 }
+
+// loadSettings は -s で指定された設定ファイルを読み込み、未知のキー、設定に一致しない SPG_* 環境変数、
+// 解決結果が変わった相対パス、非推奨のキーを警告として表示する。
+// --strict が指定されている場合、未知のキーまたは設定に一致しない SPG_* 環境変数があればエラーを返す。
+// 設定ファイルがない場合は --no-config に従い、エラーにするかデフォルト設定を通知して使用する。
+func loadSettings(cmd *cobra.Command) (*config.Settings, error) {
+	switch noConfigPolicy {
+	case "", noConfigError, noConfigDefaults:
+	default:
+		return nil, fmt.Errorf("%s", i18n.T("invalid_no_config_policy", map[string]any{"Value": noConfigPolicy}))
+	}
+
+	missing := !settingsFileExists()
+	if missing && noConfigPolicy == noConfigError {
+		return nil, fmt.Errorf("%s", i18n.T("no_config_error", map[string]any{"Path": util.ToRelativePath(settingFile)}))
+	}
+
+	settings, err := config.LoadSettingsWithOptions(settingFile, loadOptions())
+
+	var unknownKeysErr *config.UnknownKeysError
+	if errors.As(err, &unknownKeysErr) {
+		printUnknownKeys(cmd, unknownKeysErr.Keys)
+		return nil, fmt.Errorf("%s", i18n.T("unknown_keys_strict_error", map[string]any{"Count": len(unknownKeysErr.Keys)}))
+	}
+	var unknownEnvErr *config.UnknownEnvError
+	if errors.As(err, &unknownEnvErr) {
+		printUnknownEnv(cmd, unknownEnvErr.Names)
+		return nil, fmt.Errorf("%s", i18n.T("unknown_env_strict_error", map[string]any{"Count": len(unknownEnvErr.Names)}))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s", i18n.T("config_load_error", map[string]any{"Error": err.Error()}))
+	}
+
+	printUnknownKeys(cmd, settings.UnknownKeys)
+	printUnknownEnv(cmd, settings.UnknownEnv)
+	printPathMigrations(cmd, settings.PathMigrations)
+	if missing {
+		printDefaultsNotice(cmd, settings)
+	}
+
+	return settings, nil
+}
+
+// prepareSettingFile は -C で指定されたディレクトリに移動し、-s が省略された場合は
+// カレントディレクトリから親ディレクトリをたどって設定ファイルを探す
+func prepareSettingFile() error {
+	if workDir != "" {
+		if err := os.Chdir(workDir); err != nil {
+			return err
+		}
+	}
+
+	if settingFile != "" {
+		return nil
+	}
+
+	currentDir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	if foundPath, root, found := config.FindProjectSettings(currentDir); found {
+		settingFile = foundPath
+		projectRoot = root
+		return nil
+	}
+
+	settingFile = filepath.Join(currentDir, ".system_prompt", "settings.toml")
+	projectRoot = currentDir
+	return nil
+}
+
+// loadOptions はフラグと環境変数から設定の読み込み方法を作成する
+func loadOptions() config.LoadOptions {
+	return config.LoadOptions{
+		Profile:     profile,
+		Strict:      strictMode,
+		Environ:     os.Environ(),
+		Set:         setValues,
+		ProjectRoot: projectRoot,
+	}
+}
+
+func printUnknownKeys(cmd *cobra.Command, keys []config.UnknownKey) {
+	for _, key := range keys {
+		location := util.ToRelativePath(key.File)
+		if key.Line > 0 {
+			location = fmt.Sprintf("%s:%d", location, key.Line)
+		}
+
+		messageID := "unknown_setting_key"
+		if key.Suggestion != "" {
+			messageID = "unknown_setting_key_suggestion"
+		}
+
+		cmd.PrintErrf("%s\n", i18n.T(messageID, map[string]any{
+			"Location":   location,
+			"Key":        key.Key,
+			"Suggestion": key.Suggestion,
+		}))
+	}
+}
+
+func printUnknownEnv(cmd *cobra.Command, names []string) {
+	for _, name := range names {
+		cmd.PrintErrf("%s\n", i18n.T("unknown_env_var", map[string]any{"Name": name}))
+	}
+}
+
+func printPathMigrations(cmd *cobra.Command, migrations []config.PathMigration) {
+	for _, migration := range migrations {
+		cmd.PrintErrf("%s\n", i18n.T("path_migration_warning", map[string]any{
+			"File":    util.ToRelativePath(migration.File),
+			"Key":     migration.Key,
+			"Value":   migration.Value,
+			"Path":    util.ToRelativePath(migration.Resolved),
+			"NewPath": util.ToRelativePath(migration.NewPath),
+		}))
+	}
+}
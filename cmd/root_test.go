@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cateiru/system-prompt-gen/internal/config"
+)
+
+func TestPrepareSettingFile(t *testing.T) {
+	tempDir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+
+	projectDir := filepath.Join(tempDir, "project")
+	subDir := filepath.Join(projectDir, "sub")
+	require.NoError(t, os.MkdirAll(filepath.Join(projectDir, ".git"), 0755))
+	require.NoError(t, os.MkdirAll(subDir, 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(projectDir, ".system_prompt"), 0755))
+	settingsPath := filepath.Join(projectDir, ".system_prompt", "settings.toml")
+	require.NoError(t, os.WriteFile(settingsPath, []byte("[tools.claude]\ngenerate = true\n"), 0644))
+
+	originalSettingFile, originalWorkDir, originalProjectRoot := settingFile, workDir, projectRoot
+	t.Cleanup(func() {
+		settingFile, workDir, projectRoot = originalSettingFile, originalWorkDir, originalProjectRoot
+	})
+
+	t.Run("discovers the settings file from -C", func(t *testing.T) {
+		t.Chdir(tempDir)
+		settingFile, workDir, projectRoot = "", subDir, ""
+
+		require.NoError(t, prepareSettingFile())
+
+		currentDir, err := os.Getwd()
+		require.NoError(t, err)
+		assert.Equal(t, subDir, currentDir)
+		assert.Equal(t, settingsPath, settingFile)
+		assert.Equal(t, projectDir, projectRoot)
+	})
+
+	t.Run("resolves relative override paths from the project root", func(t *testing.T) {
+		t.Chdir(subDir)
+		t.Setenv("SPG_APP_OUTPUT_DIR", "out")
+		settingFile, workDir, projectRoot = "", "", ""
+
+		require.NoError(t, prepareSettingFile())
+		settings, err := config.LoadSettingsWithOptions(settingFile, loadOptions())
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(projectDir, "out"), settings.App.OutputDir)
+	})
+
+	t.Run("falls back to the working directory", func(t *testing.T) {
+		t.Chdir(tempDir)
+		settingFile, workDir, projectRoot = "", "", ""
+
+		require.NoError(t, prepareSettingFile())
+
+		assert.Equal(t, filepath.Join(tempDir, ".system_prompt", "settings.toml"), settingFile)
+		assert.Equal(t, tempDir, projectRoot)
+	})
+
+	t.Run("keeps an explicit setting file", func(t *testing.T) {
+		t.Chdir(tempDir)
+		settingFile, workDir, projectRoot = "custom.toml", "", ""
+
+		require.NoError(t, prepareSettingFile())
+
+		assert.Equal(t, "custom.toml", settingFile)
+		assert.Empty(t, projectRoot)
+	})
+
+	t.Run("invalid -C directory", func(t *testing.T) {
+		settingFile, workDir, projectRoot = "", filepath.Join(tempDir, "missing"), ""
+		assert.Error(t, prepareSettingFile())
+	})
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cateiru/system-prompt-gen/internal/config"
+)
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema for settings.toml",
+	Long:  "system-prompt-gen schema prints a JSON Schema for settings.toml\nthat can be used by editors supporting TOML schemas (Taplo, Even Better TOML).",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runSchema(cmd); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+}
+
+func runSchema(cmd *cobra.Command) error {
+	schema, err := config.JSONSchema()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "%s\n", schema)
+	return nil
+}
@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cateiru/system-prompt-gen/internal/config"
+	"github.com/cateiru/system-prompt-gen/internal/generator"
+	"github.com/cateiru/system-prompt-gen/internal/i18n"
+	"github.com/cateiru/system-prompt-gen/internal/tokenizer"
+	"github.com/cateiru/system-prompt-gen/internal/util"
+)
+
+var (
+	statsJSON      bool
+	statsTokenizer string
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show the size of prompt files and generated outputs",
+	Long:  "system-prompt-gen stats reports the estimated tokens, words and bytes of each prompt file and of each tool output.\nOutputs are compared with max_tokens/max_bytes in [tools.NAME]. Nothing is written.",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runStats(cmd); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	statsCmd.Flags().BoolVar(&statsJSON, "json", false, "Print the report as JSON")
+	statsCmd.Flags().StringVar(&statsTokenizer, "tokenizer", "", "Tokenizer used to estimate tokens: "+strings.Join(tokenizer.Names(), ", ")+" (default: app.tokenizer in settings)")
+
+	rootCmd.AddCommand(statsCmd)
+}
+
+// statsReport は stats コマンドの結果
+type statsReport struct {
+	Tokenizer string        `json:"tokenizer"`
+	Files     []fileStats   `json:"files"`
+	Outputs   []outputStats `json:"outputs"`
+}
+
+type fileStats struct {
+	Path string `json:"path"`
+	tokenizer.Stats
+}
+
+type outputStats struct {
+	Tool string `json:"tool"`
+	Path string `json:"path"`
+	tokenizer.Stats
+	MaxTokens int  `json:"max_tokens,omitempty"`
+	MaxBytes  int  `json:"max_bytes,omitempty"`
+	Exceeded  bool `json:"exceeded"`
+}
+
+func runStats(cmd *cobra.Command) error {
+	// i18nシステムの初期化
+	if err := i18n.Initialize(language); err != nil {
+		// i18n初期化に失敗した場合でも処理を続行
+		fmt.Fprintf(os.Stderr, "Warning: Failed to initialize i18n: %v\n", err)
+	}
+
+	settings, err := loadSettings(cmd)
+	if err != nil {
+		return err
+	}
+	if statsTokenizer != "" {
+		settings.App.Tokenizer = statsTokenizer
+	}
+	if settings.App.Tokenizer == "" {
+		settings.App.Tokenizer = tokenizer.DefaultName
+	}
+
+	report, err := buildStatsReport(settings)
+	if err != nil {
+		return err
+	}
+
+	if statsJSON {
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	cmd.PrintErrf("%s\n", i18n.T("stats_tokenizer", map[string]any{"Name": report.Tokenizer}))
+	return printStatsReport(cmd, report)
+}
+
+// buildStatsReport は各プロンプトファイルと各ツールの出力内容の大きさを数える
+func buildStatsReport(settings *config.Settings) (statsReport, error) {
+	gen := generator.New(settings)
+	report := statsReport{
+		Tokenizer: settings.App.Tokenizer,
+		Files:     []fileStats{},
+		Outputs:   []outputStats{},
+	}
+
+	files, err := gen.CollectPromptFiles()
+	if err != nil {
+		return report, err
+	}
+	for _, file := range files {
+		stats, err := gen.Measure(file.Content)
+		if err != nil {
+			return report, err
+		}
+		report.Files = append(report.Files, fileStats{
+			Path:  filepath.ToSlash(util.ToRelativePath(file.Path)),
+			Stats: stats,
+		})
+	}
+
+	targets, err := gen.BuildTargets()
+	if err != nil {
+		return report, err
+	}
+	for _, target := range targets {
+		stats, err := gen.Measure(gen.GeneratePrompt(target.Files))
+		if err != nil {
+			return report, err
+		}
+		_, exceeded := gen.CheckBudget(target, stats)
+		tool := settings.Tools[target.ToolName]
+		report.Outputs = append(report.Outputs, outputStats{
+			Tool:      target.ToolName,
+			Path:      filepath.ToSlash(util.ToRelativePath(target.Path)),
+			Stats:     stats,
+			MaxTokens: tool.MaxTokens,
+			MaxBytes:  tool.MaxBytes,
+			Exceeded:  exceeded,
+		})
+	}
+
+	return report, nil
+}
+
+func printStatsReport(cmd *cobra.Command, report statsReport) error {
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+		i18n.T("stats_prompt_file"), i18n.T("stats_tokens"), i18n.T("stats_words"), i18n.T("stats_bytes"))
+	var total tokenizer.Stats
+	for _, file := range report.Files {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\n", file.Path, file.Tokens, file.Words, file.Bytes)
+		total.Tokens += file.Tokens
+		total.Words += file.Words
+		total.Bytes += file.Bytes
+	}
+	fmt.Fprintf(w, "%s\t%d\t%d\t%d\n", i18n.T("stats_total"), total.Tokens, total.Words, total.Bytes)
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(cmd.OutOrStdout())
+
+	w = tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+		i18n.T("stats_tool"), i18n.T("stats_output"), i18n.T("stats_tokens"), i18n.T("stats_words"), i18n.T("stats_bytes"), i18n.T("stats_budget"), i18n.T("stats_status"))
+	for _, output := range report.Outputs {
+		var budget []string
+		if output.MaxTokens > 0 {
+			budget = append(budget, i18n.T("stats_budget_tokens", map[string]any{"Max": output.MaxTokens}))
+		}
+		if output.MaxBytes > 0 {
+			budget = append(budget, i18n.T("stats_budget_bytes", map[string]any{"Max": output.MaxBytes}))
+		}
+
+		status := "-"
+		switch {
+		case output.Exceeded:
+			status = i18n.T("stats_status_exceeded")
+		case len(budget) > 0:
+			status = i18n.T("stats_status_ok")
+		}
+		if len(budget) == 0 {
+			budget = []string{"-"}
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%s\t%s\n",
+			output.Tool, output.Path, output.Tokens, output.Words, output.Bytes, strings.Join(budget, ", "), status)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunStats(t *testing.T) {
+	tempDir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	t.Chdir(tempDir)
+
+	promptDir := filepath.Join(tempDir, ".system_prompt")
+	require.NoError(t, os.MkdirAll(promptDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(promptDir, "001_default.md"), []byte("Hello world\n"), 0644))
+	settingsPath := filepath.Join(promptDir, "settings.toml")
+	require.NoError(t, os.WriteFile(settingsPath, []byte(`[app]
+tokenizer = "chars"
+
+[tools.claude]
+generate = true
+max_tokens = 5
+
+[tools.agents]
+generate = true
+max_bytes = 1000
+`), 0644))
+
+	originalSettingFile, originalLanguage, originalJSON, originalTokenizer := settingFile, language, statsJSON, statsTokenizer
+	t.Cleanup(func() {
+		settingFile, language, statsJSON, statsTokenizer = originalSettingFile, originalLanguage, originalJSON, originalTokenizer
+		statsCmd.SetOut(nil)
+		statsCmd.SetErr(nil)
+	})
+	settingFile, language = settingsPath, "en"
+
+	var out, errOut bytes.Buffer
+	statsCmd.SetOut(&out)
+	statsCmd.SetErr(&errOut)
+
+	require.NoError(t, runStats(statsCmd))
+	assert.Equal(t, "Token counts are estimated with the chars tokenizer.\n", errOut.String())
+	assert.Equal(t, `PROMPT FILE                    TOKENS  WORDS  BYTES
+.system_prompt/001_default.md  3       2      12
+TOTAL                          3       2      12
+
+TOOL    OUTPUT     TOKENS  WORDS  BYTES  BUDGET      STATUS
+agents  AGENTS.md  7       4      28     1000 bytes  ok
+claude  CLAUDE.md  7       4      28     5 tokens    over budget
+`, out.String())
+
+	// 出力先のファイルは書き込まない
+	assert.NoFileExists(t, filepath.Join(tempDir, "CLAUDE.md"))
+
+	out.Reset()
+	statsJSON, statsTokenizer = true, "bpe"
+	require.NoError(t, runStats(statsCmd))
+
+	var report statsReport
+	require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+	assert.Equal(t, "bpe", report.Tokenizer)
+	require.Len(t, report.Outputs, 2)
+	assert.Equal(t, "claude", report.Outputs[1].Tool)
+	assert.Equal(t, 5, report.Outputs[1].MaxTokens)
+	assert.Equal(t, 28, report.Outputs[1].Bytes)
+
+	statsTokenizer = "unknown"
+	assert.Error(t, runStats(statsCmd))
+}
@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cateiru/system-prompt-gen/internal/config"
+	"github.com/cateiru/system-prompt-gen/internal/i18n"
+	"github.com/cateiru/system-prompt-gen/internal/util"
+)
+
+var (
+	toolFileName string
+	toolDirName  string
+)
+
+var toolCmd = &cobra.Command{
+	Use:   "tool",
+	Short: "List and edit the tools in settings",
+	Long:  "system-prompt-gen tool provides subcommands to list the tools and edit [tools] in the settings file.\nThe settings file is edited in place. Comments and formatting in TOML and YAML files are kept.",
+}
+
+var toolListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the built-in and configured tools",
+	Args:  cobra.NoArgs,
+	Run:   runToolCommand(runToolList),
+}
+
+var toolAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Add a tool to settings",
+	Long:  "system-prompt-gen tool add adds [tools.NAME] with generate = true.\nBuilt-in tools use their default paths unless --file or --dir is given.\nCustom tools require --file, and their names may contain lowercase letters, digits and underscores.",
+	Args:  cobra.ExactArgs(1),
+	Run:   runToolCommand(runToolAdd),
+}
+
+var toolRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove a tool from settings",
+	Args:  cobra.ExactArgs(1),
+	Run:   runToolCommand(runToolRemove),
+}
+
+var toolEnableCmd = &cobra.Command{
+	Use:   "enable <name>",
+	Short: "Enable generation for a tool",
+	Args:  cobra.ExactArgs(1),
+	Run:   runToolCommand(runToolEnable),
+}
+
+var toolDisableCmd = &cobra.Command{
+	Use:   "disable <name>",
+	Short: "Disable generation for a tool",
+	Args:  cobra.ExactArgs(1),
+	Run:   runToolCommand(runToolDisable),
+}
+
+var toolExcludeCmd = &cobra.Command{
+	Use:   "exclude <name> <pattern>",
+	Short: "Exclude prompt files from a tool",
+	Long:  "system-prompt-gen tool exclude appends a file name pattern to the exclude list of a tool.",
+	Args:  cobra.ExactArgs(2),
+	Run:   runToolCommand(runToolExclude),
+}
+
+func init() {
+	toolAddCmd.Flags().StringVar(&toolFileName, "file", "", "File name of the generated file (required for custom tools)")
+	toolAddCmd.Flags().StringVar(&toolDirName, "dir", "", "Directory of the generated file, relative to output_dir")
+
+	toolCmd.AddCommand(toolListCmd)
+	toolCmd.AddCommand(toolAddCmd)
+	toolCmd.AddCommand(toolRemoveCmd)
+	toolCmd.AddCommand(toolEnableCmd)
+	toolCmd.AddCommand(toolDisableCmd)
+	toolCmd.AddCommand(toolExcludeCmd)
+	rootCmd.AddCommand(toolCmd)
+}
+
+// runToolCommand は tool のサブコマンドを実行し、エラーの場合は終了する
+func runToolCommand(run func(cmd *cobra.Command, args []string) error) func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		// i18nシステムの初期化
+		if err := i18n.Initialize(language); err != nil {
+			// i18n初期化に失敗した場合でも処理を続行
+			fmt.Fprintf(os.Stderr, "Warning: Failed to initialize i18n: %v\n", err)
+		}
+
+		if err := run(cmd, args); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+}
+
+// toolSettingsPath は編集する設定ファイルのパスを返す
+func toolSettingsPath() (string, error) {
+	settingsPath := config.FindSettingsFile(settingFile)
+	if _, err := os.Stat(settingsPath); os.IsNotExist(err) {
+		return "", fmt.Errorf("%s", i18n.T("config_file_not_found", map[string]any{"Path": settingsPath}))
+	}
+	return settingsPath, nil
+}
+
+func runToolList(cmd *cobra.Command, args []string) error {
+	settingsPath, err := toolSettingsPath()
+	if err != nil {
+		return err
+	}
+
+	tools, err := config.ListTools(settingsPath)
+	if err != nil {
+		return fmt.Errorf("%s", i18n.T("config_load_error", map[string]any{"Error": err.Error()}))
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+		i18n.T("tool_list_name"), i18n.T("tool_list_type"), i18n.T("tool_list_status"), i18n.T("tool_list_output"), i18n.T("tool_list_exclude"))
+	for _, tool := range tools {
+		toolType := i18n.T("tool_type_custom")
+		if tool.BuiltIn {
+			toolType = i18n.T("tool_type_builtin")
+		}
+
+		status := i18n.T("tool_status_disabled")
+		switch {
+		case !tool.Defined:
+			status = i18n.T("tool_status_not_configured")
+		case tool.Generate:
+			status = i18n.T("tool_status_enabled")
+		}
+
+		output := filepath.ToSlash(filepath.Join(string(tool.DirName), string(tool.FileName)))
+		exclude := strings.Join(tool.Exclude, ", ")
+		if exclude == "" {
+			exclude = "-"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", tool.Name, toolType, status, output, exclude)
+	}
+	return w.Flush()
+}
+
+func runToolAdd(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	return editToolSettings(cmd, "tool_added", map[string]any{"Name": name}, func(settingsPath string) (*config.SettingsEdit, error) {
+		return config.AddTool(settingsPath, name, config.AIToolPaths{
+			DirName:  config.DirName(toolDirName),
+			FileName: config.FileName(toolFileName),
+		})
+	})
+}
+
+func runToolRemove(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	return editToolSettings(cmd, "tool_removed", map[string]any{"Name": name}, func(settingsPath string) (*config.SettingsEdit, error) {
+		return config.RemoveTool(settingsPath, name)
+	})
+}
+
+func runToolEnable(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	return editToolSettings(cmd, "tool_enabled", map[string]any{"Name": name}, func(settingsPath string) (*config.SettingsEdit, error) {
+		return config.SetToolGenerate(settingsPath, name, true)
+	})
+}
+
+func runToolDisable(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	return editToolSettings(cmd, "tool_disabled", map[string]any{"Name": name}, func(settingsPath string) (*config.SettingsEdit, error) {
+		return config.SetToolGenerate(settingsPath, name, false)
+	})
+}
+
+func runToolExclude(cmd *cobra.Command, args []string) error {
+	name, pattern := args[0], args[1]
+	return editToolSettings(cmd, "tool_exclude_added", map[string]any{"Name": name, "Pattern": pattern}, func(settingsPath string) (*config.SettingsEdit, error) {
+		return config.AddToolExclude(settingsPath, name, pattern)
+	})
+}
+
+// editToolSettings は edit で書き換えた設定ファイルを保存し、messageID のメッセージを表示する
+func editToolSettings(cmd *cobra.Command, messageID string, data map[string]any, edit func(settingsPath string) (*config.SettingsEdit, error)) error {
+	settingsPath, err := toolSettingsPath()
+	if err != nil {
+		return err
+	}
+
+	result, err := edit(settingsPath)
+	if err != nil {
+		return err
+	}
+
+	data["Path"] = util.ToRelativePath(settingsPath)
+	if !result.Changed {
+		cmd.PrintErrf("%s\n", i18n.T("tool_unchanged", data))
+		return nil
+	}
+
+	if err := saveSettingsFile(settingsPath, result.Content); err != nil {
+		return err
+	}
+
+	cmd.PrintErrf("%s\n", i18n.T(messageID, data))
+	return nil
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cateiru/system-prompt-gen/internal/i18n"
+)
+
+func TestRunToolCommands(t *testing.T) {
+	require.NoError(t, i18n.Initialize("en"))
+
+	settingsPath := filepath.Join(t.TempDir(), "settings.toml")
+	require.NoError(t, os.WriteFile(settingsPath, []byte("# 設定\n[tools.claude]\ngenerate = true\n"), 0600))
+
+	originalSettingFile, originalFileName, originalDirName := settingFile, toolFileName, toolDirName
+	t.Cleanup(func() {
+		settingFile, toolFileName, toolDirName = originalSettingFile, originalFileName, originalDirName
+	})
+	settingFile = settingsPath
+
+	toolFileName, toolDirName = "rules.md", ".cursor"
+	require.NoError(t, runToolAdd(toolAddCmd, []string{"cursor"}))
+	require.NoError(t, runToolDisable(toolDisableCmd, []string{"claude"}))
+	require.NoError(t, runToolExclude(toolExcludeCmd, []string{"cursor", "draft.md"}))
+
+	content, err := os.ReadFile(settingsPath)
+	require.NoError(t, err)
+	assert.Equal(t, "# 設定\n[tools.claude]\ngenerate = false\n\n[tools.cursor]\ngenerate = true\ndir_name = \".cursor\"\nfile_name = \"rules.md\"\nexclude = [\"draft.md\"]\n", string(content))
+
+	// 元のパーミッションを維持する
+	info, err := os.Stat(settingsPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	var out bytes.Buffer
+	toolListCmd.SetOut(&out)
+	t.Cleanup(func() { toolListCmd.SetOut(nil) })
+
+	require.NoError(t, runToolList(toolListCmd, nil))
+	assert.Equal(t, `NAME            TYPE      STATUS          OUTPUT                           EXCLUDE
+agents          built-in  not configured  AGENTS.md                        -
+claude          built-in  disabled        CLAUDE.md                        -
+cline           built-in  not configured  .clinerules                      -
+cursor          custom    enabled         .cursor/rules.md                 draft.md
+github_copilot  built-in  not configured  .github/copilot-instructions.md  -
+`, out.String())
+
+	assert.Error(t, runToolRemove(toolRemoveCmd, []string{"cline"}))
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cateiru/system-prompt-gen/internal/config"
+	"github.com/cateiru/system-prompt-gen/internal/i18n"
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate settings",
+	Long:  "system-prompt-gen validate checks settings.toml for unsafe output paths,\nduplicate outputs and malformed include/exclude patterns.",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runValidate(cmd); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+}
+
+func runValidate(cmd *cobra.Command) error {
+	// i18nシステムの初期化
+	if err := i18n.Initialize(language); err != nil {
+		// i18n初期化に失敗した場合でも処理を続行
+		fmt.Fprintf(os.Stderr, "Warning: Failed to initialize i18n: %v\n", err)
+	}
+
+	settings, err := loadSettings(cmd)
+	if err != nil {
+		return err
+	}
+
+	// 警告は表示するのみで、エラーの件数には含めない
+	errorCount := 0
+	for _, finding := range settings.Validate() {
+		icon := "⚠️"
+		if finding.Severity == config.SeverityError {
+			icon = "❌"
+			errorCount++
+		}
+		cmd.Printf("%s %s\n", icon, finding.String())
+	}
+
+	if errorCount > 0 {
+		return fmt.Errorf("%s", i18n.T("validation_errors_found", map[string]any{"Count": errorCount}))
+	}
+
+	cmd.Printf("%s\n", i18n.T("validation_passed"))
+	return nil
+}
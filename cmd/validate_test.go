@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunValidate(t *testing.T) {
+	tempDir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	t.Chdir(tempDir)
+
+	promptDir := filepath.Join(tempDir, ".system_prompt")
+	require.NoError(t, os.MkdirAll(promptDir, 0755))
+	settingsPath := filepath.Join(promptDir, "settings.toml")
+
+	originalSettingFile, originalLanguage := settingFile, language
+	t.Cleanup(func() {
+		settingFile, language = originalSettingFile, originalLanguage
+		validateCmd.SetOut(nil)
+		validateCmd.SetErr(nil)
+	})
+	settingFile, language = settingsPath, "en"
+
+	t.Run("valid", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(settingsPath, []byte("[tools.claude]\ngenerate = true\n"), 0644))
+
+		var out bytes.Buffer
+		validateCmd.SetOut(&out)
+		validateCmd.SetErr(&out)
+
+		require.NoError(t, runValidate(validateCmd))
+		assert.Equal(t, "✅ No problems found in settings\n", out.String())
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(settingsPath, []byte(`[tools.claude]
+generate = true
+include = ["[.md"]
+
+[tools.mytool]
+generate = true
+dir_name = "/etc"
+file_name = "mytool.md"
+`), 0644))
+
+		var out bytes.Buffer
+		validateCmd.SetOut(&out)
+		validateCmd.SetErr(&out)
+
+		err := runValidate(validateCmd)
+		require.Error(t, err)
+		assert.Equal(t, "Found 2 error(s) in settings", err.Error())
+		assert.Contains(t, out.String(), "❌ [SPG001]")
+		assert.Contains(t, out.String(), "❌ [SPG005]")
+	})
+}
@@ -4,8 +4,6 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-
-	"github.com/BurntSushi/toml"
 )
 
 type FileName string
@@ -16,6 +14,11 @@ type AIToolSettings struct {
 	Include  []string `toml:"include"`
 	Exclude  []string `toml:"exclude"`
 	AIToolPaths
+
+	// MaxTokens は出力ファイルのトークン数の上限（0 の場合は制限しない）
+	MaxTokens int `toml:"max_tokens"`
+	// MaxBytes は出力ファイルのバイト数の上限（0 の場合は制限しない）
+	MaxBytes int `toml:"max_bytes"`
 }
 
 type AIToolPaths struct {
@@ -28,13 +31,100 @@ type AppSettings struct {
 	Footer    string `toml:"footer"`
 	InputDir  string `toml:"input_dir"`
 	OutputDir string `toml:"output_dir"`
+
+	// InputDirs は複数の入力ディレクトリ（レイヤー）を優先度の低い順に指定する。
+	// 指定された場合は InputDir の代わりに使用される
+	InputDirs []string `toml:"input_dirs"`
+	// LayerPolicy は同じ相対パスのファイルが複数のレイヤーに存在する場合の扱いを指定する
+	LayerPolicy LayerPolicy `toml:"layer_policy"`
+
+	// DirectoryMapping が true の場合、InputDir のサブディレクトリを
+	// OutputDir 配下の同じ相対パスにそれぞれ個別のファイルとして出力する
+	DirectoryMapping bool `toml:"directory_mapping"`
+	// InheritParent が true の場合、サブディレクトリの出力に親ディレクトリ（ルートを含む）のプロンプトも含める
+	InheritParent bool `toml:"inherit_parent"`
+
+	// Tokenizer はトークン数の見積もりに使用するトークナイザー（空の場合は bpe）
+	Tokenizer string `toml:"tokenizer"`
+	// BudgetPolicy は出力ファイルが max_tokens/max_bytes を超えた場合の扱いを指定する
+	BudgetPolicy BudgetPolicy `toml:"budget_policy"`
+}
+
+// Layers は入力ディレクトリを優先度の低い順に返す。
+// InputDirs が未指定の場合は InputDir のみを返す。
+func (app AppSettings) Layers() []string {
+	if len(app.InputDirs) > 0 {
+		return app.InputDirs
+	}
+	return []string{app.InputDir}
 }
 
+// LayerPolicy は複数のレイヤーに同じ相対パスのファイルが存在する場合の扱い
+type LayerPolicy string
+
+const (
+	// LayerPolicyOverride は後ろのレイヤーのファイルで前のレイヤーのファイルを置き換える（デフォルト）
+	LayerPolicyOverride LayerPolicy = "override"
+	// LayerPolicyKeep は最初に見つかったレイヤーのファイルを使用する
+	LayerPolicyKeep LayerPolicy = "keep"
+	// LayerPolicyAppend は全てのレイヤーのファイルをレイヤー順に含める
+	LayerPolicyAppend LayerPolicy = "append"
+	// LayerPolicyError は重複をエラーとして扱う
+	LayerPolicyError LayerPolicy = "error"
+)
+
+// BudgetPolicy は出力ファイルが上限（max_tokens/max_bytes）を超えた場合の扱い
+type BudgetPolicy string
+
+const (
+	// BudgetPolicyWarn は警告を表示して出力する（デフォルト）
+	BudgetPolicyWarn BudgetPolicy = "warn"
+	// BudgetPolicyError はエラーとして扱い、出力しない
+	BudgetPolicyError BudgetPolicy = "error"
+)
+
 type Settings struct {
+	// Version は設定ファイルの形式のバージョン（省略時は 1）
+	Version int `toml:"version"`
+	// Extends は継承する設定ファイルのパス（この設定ファイルからの相対パス）
+	Extends []string `toml:"extends"`
+	// ArrayMerge は継承元の配列（include/exclude など）との結合方法
+	ArrayMerge ArrayMergeMode `toml:"array_merge"`
+
 	App   AppSettings               `toml:"app"`
 	Tools map[string]AIToolSettings `toml:"tools"`
+
+	// Profiles は --profile で選択できる名前付きの設定
+	Profiles map[string]ProfileSettings `toml:"profiles"`
+	// Profile は選択されているプロファイル名（未選択の場合は空）
+	Profile string `toml:"-"`
+	// UnknownKeys は設定ファイル内の未知のキー（typo の可能性がある）
+	UnknownKeys []UnknownKey `toml:"-"`
+	// PathMigrations は version 2 に移行すると解決結果が変わる相対パス
+	PathMigrations []PathMigration `toml:"-"`
+	// UnknownEnv は設定に一致しない SPG_* 環境変数の名前
+	UnknownEnv []string `toml:"-"`
+}
+
+// LoadOptions は設定の読み込み方法を指定する
+type LoadOptions struct {
+	// Profile は適用するプロファイル名（空の場合は適用しない）
+	Profile string
+	// Strict が true の場合、未知のキーと設定に一致しない SPG_* 環境変数をエラーとして扱う
+	Strict bool
+	// Environ は設定の上書きに使用する環境変数（"KEY=VALUE" 形式、通常は os.Environ()）
+	Environ []string
+	// Set は --set で指定された "key=value" 形式の上書き
+	Set []string
+	// ProjectRoot は入出力ディレクトリのデフォルト値の基準となるディレクトリ（空の場合はカレントディレクトリ）。
+	// 環境変数と Set の相対パスは ProjectRoot から、設定ファイル内の相対パスは設定ファイルのディレクトリから解決される
+	ProjectRoot string
 }
 
+// BackupDirName は init が置き換えるファイルのバックアップを保存する入力ディレクトリ内のディレクトリ。
+// プロンプトファイルの収集では無視される。
+const BackupDirName = ".backup"
+
 var DefaultKnownToolFileNames = map[string]AIToolPaths{
     "claude": {
         DirName:  "",
@@ -73,43 +163,142 @@ func DefaultSettings(currentDir string) (*Settings, error) {
 
 	return &Settings{
 		App: AppSettings{
-			InputDir: inputDir,
+			InputDir:     inputDir,
+			LayerPolicy:  LayerPolicyOverride,
+			BudgetPolicy: BudgetPolicyWarn,
 		},
 		Tools: tools,
 	}, nil
 }
 
-// LoadSettings は指定された TOML ファイル (settingsPath) から設定を読み込みます。
+// LoadSettings は指定された設定ファイル (settingsPath) から設定を読み込みます。
+// 形式は拡張子から判定し（TOML/YAML/JSON）、settingsPath が存在しない場合は
+// 同じディレクトリの settings.yaml/settings.yml/settings.json を探します。
+// extends で指定された設定ファイルはマージされます。
 // ファイルが存在しない場合はデフォルト設定を返します。
 // また、Claude/Cline の FileName が空の場合は既定値を補完します。
 func LoadSettings(settingsPath string) (*Settings, error) {
-	currentDir, err := os.Getwd()
+	return LoadSettingsWithOptions(settingsPath, LoadOptions{})
+}
+
+// LoadSettingsWithOptions は LoadSettings と同様に設定を読み込みます。
+// options.Profile が指定されている場合は [profiles.NAME] の設定で上書きし、
+// さらに options.Environ の SPG_* 環境変数、options.Set の順に上書きします。
+// 未知のキーは Settings.UnknownKeys に記録され、options.Strict の場合は UnknownKeysError を返します。
+// 設定に一致しない SPG_* 環境変数は Settings.UnknownEnv に記録され、options.Strict の場合は UnknownEnvError を返します。
+func LoadSettingsWithOptions(settingsPath string, options LoadOptions) (*Settings, error) {
+	profile := options.Profile
+	settingsPath = FindSettingsFile(settingsPath)
+
+	baseDir := options.ProjectRoot
+	if baseDir == "" {
+		currentDir, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		baseDir = currentDir
+	}
+
+	overrides, unknownEnv, err := ParseOverrides(options.Environ, options.Set)
 	if err != nil {
 		return nil, err
 	}
+	if options.Strict && len(unknownEnv) > 0 {
+		return nil, &UnknownEnvError{Names: unknownEnv}
+	}
+	resolveOverridePaths(overrides, baseDir)
 
+	var resolved *ResolvedSettings
 	if _, err := os.Stat(settingsPath); os.IsNotExist(err) {
-		return DefaultSettings(currentDir)
+		if profile != "" {
+			return nil, fmt.Errorf("profile %q is not defined", profile)
+		}
+		if len(overrides) == 0 {
+			settings, err := DefaultSettings(baseDir)
+			if err != nil {
+				return nil, err
+			}
+			if options.ProjectRoot != "" {
+				settings.App.OutputDir = options.ProjectRoot
+			}
+			settings.UnknownEnv = unknownEnv
+			return settings, nil
+		}
+		resolved = defaultResolvedSettings()
+	} else {
+		resolved, err = ResolveSettingsWithRoot(settingsPath, baseDir)
+		if err != nil {
+			return nil, err
+		}
+
+		if options.Strict && len(resolved.UnknownKeys) > 0 {
+			return nil, &UnknownKeysError{Keys: resolved.UnknownKeys}
+		}
+
+		if profile != "" {
+			if err := resolved.ApplyProfile(profile); err != nil {
+				return nil, err
+			}
+		}
 	}
 
-	var settings Settings
-	if _, err := toml.DecodeFile(settingsPath, &settings); err != nil {
+	// 優先度: 設定ファイル < プロファイル < 環境変数 < --set
+	resolved.ApplyOverrides(overrides)
+
+	settings, err := resolved.Decode()
+	if err != nil {
 		return nil, err
 	}
 
 	if settings.App.InputDir == "" {
-		settings.App.InputDir = filepath.Join(currentDir, ".system_prompt")
+		settings.App.InputDir = filepath.Join(baseDir, ".system_prompt")
 	}
 	if settings.App.OutputDir == "" {
-		settings.App.OutputDir = currentDir
+		settings.App.OutputDir = baseDir
+	}
+
+	switch settings.App.LayerPolicy {
+	case "":
+		settings.App.LayerPolicy = LayerPolicyOverride
+	case LayerPolicyOverride, LayerPolicyKeep, LayerPolicyAppend, LayerPolicyError:
+	default:
+		return nil, fmt.Errorf("unknown layer_policy %q", settings.App.LayerPolicy)
+	}
+
+	switch settings.App.BudgetPolicy {
+	case "":
+		settings.App.BudgetPolicy = BudgetPolicyWarn
+	case BudgetPolicyWarn, BudgetPolicyError:
+	default:
+		return nil, fmt.Errorf("unknown budget_policy %q", settings.App.BudgetPolicy)
 	}
 
+	tools, err := normalizeTools(settings.Tools)
+	if err != nil {
+		return nil, err
+	}
+
+	settings.Tools = tools
+	settings.Profile = profile
+	settings.UnknownKeys = resolved.UnknownKeys
+	settings.PathMigrations = findPathMigrations(resolved.RelativePaths)
+	settings.Version = resolved.Version
+	settings.UnknownEnv = unknownEnv
+
+	return settings, nil
+}
+
+// normalizeTools は生成するツールのみを残し、ビルトインツールの dir_name/file_name の省略時の値を補完する
+func normalizeTools(tools map[string]AIToolSettings) (map[string]AIToolSettings, error) {
 	var newTools = make(map[string]AIToolSettings)
 
-	for name, tool := range settings.Tools {
+	for name, tool := range tools {
 		if !tool.Generate {
 			continue
 		}
+		if tool.MaxTokens < 0 || tool.MaxBytes < 0 {
+			return nil, fmt.Errorf("tool %q has a negative max_tokens or max_bytes", name)
+		}
 
 		knownTool, ok := DefaultKnownToolFileNames[name]
 		if ok {
@@ -130,6 +319,8 @@ func LoadSettings(settingsPath string) (*Settings, error) {
 					DirName:  dirName,
 					FileName: fileName,
 				},
+				MaxTokens: tool.MaxTokens,
+				MaxBytes:  tool.MaxBytes,
 			}
 		} else {
 			if tool.FileName == "" {
@@ -140,7 +331,22 @@ func LoadSettings(settingsPath string) (*Settings, error) {
 		}
 	}
 
-	settings.Tools = newTools
+	return newTools, nil
+}
+
+// defaultResolvedSettings は設定ファイルがない場合の設定値（全てのビルトインツールを生成する）を返す
+func defaultResolvedSettings() *ResolvedSettings {
+	resolved := &ResolvedSettings{
+		Values:  make(map[string]any),
+		Origins: make(map[string][]string),
+	}
+
+	tools := make(map[string]any)
+	for name := range DefaultKnownToolFileNames {
+		tools[name] = map[string]any{"generate": true}
+		resolved.Origins[joinKey("tools", name)+".generate"] = []string{"default"}
+	}
+	resolved.Values["tools"] = tools
 
-	return &settings, nil
+	return resolved
 }
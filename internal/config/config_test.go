@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -254,3 +255,124 @@ generate = false`
 	_, ok := settings.Tools["cline"]
 	assert.False(t, ok)
 }
+
+func TestLoadSettingsLayers(t *testing.T) {
+	tests := []struct {
+		name            string
+		settingsContent string
+		expectedLayers  []string
+		expectedPolicy  LayerPolicy
+		expectError     bool
+	}{
+		{
+			name:            "input_dirs with policy",
+			settingsContent: "[app]\ninput_dirs = [\"shared/prompts\", \"~/.config/system-prompt-gen/prompts\", \".system_prompt\"]\nlayer_policy = \"append\"\n",
+			expectedLayers:  []string{"shared/prompts", "~/.config/system-prompt-gen/prompts", ".system_prompt"},
+			expectedPolicy:  LayerPolicyAppend,
+		},
+		{
+			name:            "default policy is override",
+			settingsContent: "[app]\ninput_dirs = [\"a\", \"b\"]\n",
+			expectedLayers:  []string{"a", "b"},
+			expectedPolicy:  LayerPolicyOverride,
+		},
+		{
+			name:            "unknown policy",
+			settingsContent: "[app]\nlayer_policy = \"merge\"\n",
+			expectError:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settingsPath := filepath.Join(t.TempDir(), "settings.toml")
+			require.NoError(t, os.WriteFile(settingsPath, []byte(tt.settingsContent), 0644))
+
+			settings, err := LoadSettings(settingsPath)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			// version のない設定ファイルの相対パスはプロジェクトルート（カレントディレクトリ）から解決される
+			currentDir, err := os.Getwd()
+			require.NoError(t, err)
+			var expectedLayers []string
+			for _, layer := range tt.expectedLayers {
+				if !strings.HasPrefix(layer, "~") {
+					layer = filepath.Join(currentDir, layer)
+				}
+				expectedLayers = append(expectedLayers, layer)
+			}
+			assert.Equal(t, expectedLayers, settings.App.Layers())
+			assert.Equal(t, tt.expectedPolicy, settings.App.LayerPolicy)
+		})
+	}
+}
+
+func TestLoadSettingsBudgets(t *testing.T) {
+	tests := []struct {
+		name              string
+		fileName          string
+		settingsContent   string
+		expectedTool      AIToolSettings
+		expectedPolicy    BudgetPolicy
+		expectedTokenizer string
+		expectError       bool
+	}{
+		{
+			name:            "default policy is warn",
+			fileName:        "settings.toml",
+			settingsContent: "[tools.github_copilot]\ngenerate = true\nmax_tokens = 8000\nmax_bytes = 32000\n",
+			expectedTool: AIToolSettings{
+				Generate:    true,
+				AIToolPaths: DefaultKnownToolFileNames["github_copilot"],
+				MaxTokens:   8000,
+				MaxBytes:    32000,
+			},
+			expectedPolicy: BudgetPolicyWarn,
+		},
+		{
+			name:            "json numbers",
+			fileName:        "settings.json",
+			settingsContent: `{"app": {"budget_policy": "error", "tokenizer": "chars"}, "tools": {"github_copilot": {"generate": true, "max_tokens": 8000}}}`,
+			expectedTool: AIToolSettings{
+				Generate:    true,
+				AIToolPaths: DefaultKnownToolFileNames["github_copilot"],
+				MaxTokens:   8000,
+			},
+			expectedPolicy:    BudgetPolicyError,
+			expectedTokenizer: "chars",
+		},
+		{
+			name:            "unknown policy",
+			fileName:        "settings.toml",
+			settingsContent: "[app]\nbudget_policy = \"truncate\"\n",
+			expectError:     true,
+		},
+		{
+			name:            "negative budget",
+			fileName:        "settings.toml",
+			settingsContent: "[tools.github_copilot]\ngenerate = true\nmax_tokens = -1\n",
+			expectError:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settingsPath := writeSettingsFile(t, filepath.Join(t.TempDir(), tt.fileName), tt.settingsContent)
+
+			settings, err := LoadSettings(settingsPath)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedTool, settings.Tools["github_copilot"])
+			assert.Equal(t, tt.expectedPolicy, settings.App.BudgetPolicy)
+			assert.Equal(t, tt.expectedTokenizer, settings.App.Tokenizer)
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// FindProjectSettings は startDir から親ディレクトリに向かって .system_prompt/settings.*
+// （TOML/YAML/JSON）を探し、最も近いものを返す。git リポジトリのルート（.git があるディレクトリ）より
+// 上のディレクトリは探さない。projectRoot は .system_prompt を含むディレクトリ。
+func FindProjectSettings(startDir string) (settingsPath string, projectRoot string, found bool) {
+	dir, err := filepath.Abs(startDir)
+	if err != nil {
+		return "", "", false
+	}
+
+	for {
+		candidate := FindSettingsFile(filepath.Join(dir, ".system_prompt", "settings.toml"))
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, dir, true
+		}
+
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return "", "", false
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", "", false
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindProjectSettings(t *testing.T) {
+	tempDir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+
+	repoDir := filepath.Join(tempDir, "repo")
+	subDir := filepath.Join(repoDir, "packages", "app")
+	require.NoError(t, os.MkdirAll(filepath.Join(repoDir, ".git"), 0755))
+	require.NoError(t, os.MkdirAll(subDir, 0755))
+
+	// git リポジトリの外の設定ファイルは検出しない
+	writeSettingsFile(t, filepath.Join(tempDir, ".system_prompt", "settings.toml"), "")
+
+	t.Run("stops at the git root", func(t *testing.T) {
+		_, _, found := FindProjectSettings(subDir)
+		assert.False(t, found)
+	})
+
+	t.Run("finds the settings file of the repository", func(t *testing.T) {
+		settingsPath := writeSettingsFile(t, filepath.Join(repoDir, ".system_prompt", "settings.yaml"), "")
+
+		foundPath, root, found := FindProjectSettings(subDir)
+		require.True(t, found)
+		assert.Equal(t, settingsPath, foundPath)
+		assert.Equal(t, repoDir, root)
+	})
+
+	t.Run("prefers the nearest settings file", func(t *testing.T) {
+		settingsPath := writeSettingsFile(t, filepath.Join(repoDir, "packages", ".system_prompt", "settings.toml"), "")
+
+		foundPath, root, found := FindProjectSettings(subDir)
+		require.True(t, found)
+		assert.Equal(t, settingsPath, foundPath)
+		assert.Equal(t, filepath.Join(repoDir, "packages"), root)
+	})
+}
+
+func TestLoadSettingsWithProjectRoot(t *testing.T) {
+	projectRoot := t.TempDir()
+
+	settingsPath := writeSettingsFile(t, filepath.Join(projectRoot, ".system_prompt", "settings.toml"), `[app]
+output_dir = "dist"
+input_dirs = ["~/prompts", "shared", "/abs/prompts"]
+`)
+
+	settings, err := LoadSettingsWithOptions(settingsPath, LoadOptions{ProjectRoot: projectRoot})
+	require.NoError(t, err)
+
+	assert.Equal(t, filepath.Join(projectRoot, ".system_prompt"), settings.App.InputDir)
+	assert.Equal(t, filepath.Join(projectRoot, "dist"), settings.App.OutputDir)
+	assert.Equal(t, []string{"~/prompts", filepath.Join(projectRoot, "shared"), "/abs/prompts"}, settings.App.InputDirs)
+
+	t.Run("defaults without settings file", func(t *testing.T) {
+		settings, err := LoadSettingsWithOptions(filepath.Join(projectRoot, "missing", "settings.toml"), LoadOptions{ProjectRoot: projectRoot})
+		require.NoError(t, err)
+
+		assert.Equal(t, filepath.Join(projectRoot, ".system_prompt"), settings.App.InputDir)
+		assert.Equal(t, projectRoot, settings.App.OutputDir)
+	})
+}
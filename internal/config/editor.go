@@ -0,0 +1,719 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// settingsEditor は設定ファイルの内容を、可能な限りコメントや書式を維持したまま書き換える。
+// tool コマンドによる設定の編集と migrate で使用する
+type settingsEditor interface {
+	// Set は既存のキーの値を置き換える
+	Set(key toml.Key, value any) error
+	// Put はキーの値を設定する。キーや親のテーブルがない場合は追加する
+	Put(key toml.Key, value any) error
+	// Append は既存の配列の末尾に value を追加する
+	Append(key toml.Key, value any) error
+	// Delete は既存のキーまたはテーブルを削除する
+	Delete(key toml.Key) error
+	// SetVersion はルートの version を設定する
+	SetVersion(version int) error
+	// Bytes は書き換え後の内容を返す
+	Bytes() ([]byte, error)
+}
+
+func newSettingsEditor(format Format, content []byte) (settingsEditor, error) {
+	switch format {
+	case FormatTOML:
+		return &tomlEditor{lines: strings.Split(string(content), "\n")}, nil
+	case FormatYAML:
+		editor := &yamlEditor{}
+		if err := yaml.Unmarshal(content, &editor.document); err != nil {
+			return nil, err
+		}
+		return editor, nil
+	case FormatJSON:
+		values, err := decodeValues(FormatJSON, content)
+		if err != nil {
+			return nil, err
+		}
+		return &jsonEditor{values: values}, nil
+	default:
+		return nil, fmt.Errorf("unsupported settings format %q", format)
+	}
+}
+
+// tomlEditor は TOML を行単位で書き換える。書き換えた値の行末コメントは維持される。
+// 1 行に 1 要素ずつ書かれた複数行の配列は要素の行ごとに書き換えるため、配列内のコメントも維持される。
+type tomlEditor struct {
+	lines []string
+}
+
+// tomlEntry は TOML のテーブルヘッダーまたはキーの定義
+type tomlEntry struct {
+	// Key はテーブルヘッダーの場合はテーブル、それ以外は値のキーのパス
+	Key   []string
+	Table bool
+	// Start と End は定義されている行の範囲 [Start, End)。テーブルヘッダーの場合はヘッダーの行のみ
+	Start int
+	End   int
+}
+
+func (e *tomlEditor) Set(key toml.Key, value any) error {
+	return e.replace(key, value)
+}
+
+func (e *tomlEditor) Put(key toml.Key, value any) error {
+	if _, _, err := e.span(key); err == nil {
+		return e.Set(key, value)
+	}
+
+	encoded, err := encodeTOMLValue(value)
+	if err != nil {
+		return err
+	}
+	line := fmt.Sprintf("%s = %s", quoteKey(key[len(key)-1]), encoded)
+	table := key[:len(key)-1]
+
+	if len(table) == 0 {
+		e.insertRootLine(line)
+		return nil
+	}
+
+	entries, err := e.entries()
+	if err != nil {
+		return err
+	}
+	for i, entry := range entries {
+		if !entry.Table || !keyEquals(entry.Key, table) {
+			continue
+		}
+
+		// テーブル内の最後のキーの後ろに追加する
+		insertAt := entry.End
+		for _, next := range entries[i+1:] {
+			if next.Table {
+				break
+			}
+			insertAt = next.End
+		}
+		e.insertLines(insertAt, line)
+		return nil
+	}
+
+	// テーブルがない場合はファイルの末尾に追加する
+	for len(e.lines) > 0 && strings.TrimSpace(e.lines[len(e.lines)-1]) == "" {
+		e.lines = e.lines[:len(e.lines)-1]
+	}
+	var header []string
+	for _, part := range table {
+		header = append(header, quoteKey(part))
+	}
+	if len(e.lines) > 0 {
+		e.lines = append(e.lines, "")
+	}
+	e.lines = append(e.lines, fmt.Sprintf("[%s]", strings.Join(header, ".")), line, "")
+	return nil
+}
+
+// Append は配列に value を追加する。複数行の配列は、配列内のコメントを維持するため要素の行を追加する。
+func (e *tomlEditor) Append(key toml.Key, value any) error {
+	start, end, err := e.span(key)
+	if err != nil {
+		return err
+	}
+
+	encoded, err := encodeTOMLValue(value)
+	if err != nil {
+		return err
+	}
+
+	closing, _ := splitComment(e.lines[end-1])
+	if end-start == 1 || strings.TrimSpace(closing) != "]" {
+		current, err := e.value(start, end)
+		if err != nil {
+			return err
+		}
+		items, ok := current.([]any)
+		if !ok {
+			return fmt.Errorf("%s is not an array", key)
+		}
+		return e.Set(key, append(items, value))
+	}
+
+	// 最後の要素の末尾にカンマを追加し、同じインデントで要素を追加する
+	indent := closing[:len(closing)-len(strings.TrimLeft(closing, " \t"))] + "  "
+	for i := end - 2; i >= start; i-- {
+		code, comment := splitComment(e.lines[i])
+		code = strings.TrimRight(code, " \t")
+		if strings.TrimSpace(code) == "" {
+			continue
+		}
+		if !strings.HasSuffix(code, ",") && !strings.HasSuffix(code, "[") {
+			e.lines[i] = code + "," + comment
+		}
+		if i > start {
+			indent = code[:len(code)-len(strings.TrimLeft(code, " \t"))]
+		}
+		break
+	}
+
+	e.insertLines(end-1, indent+encoded+",")
+	return nil
+}
+
+// Delete は key の値を削除する。key がテーブルの場合は、テーブルとその中の全てのキーを削除する。
+func (e *tomlEditor) Delete(key toml.Key) error {
+	if start, end, err := e.span(key); err == nil {
+		e.lines = append(e.lines[:start], e.lines[end:]...)
+		return nil
+	}
+
+	entries, err := e.entries()
+	if err != nil {
+		return err
+	}
+
+	removed := make([]bool, len(e.lines))
+	found := false
+	for i, entry := range entries {
+		if len(entry.Key) < len(key) || !keyEquals(entry.Key[:len(key)], key) {
+			continue
+		}
+		found = true
+
+		if !entry.Table {
+			for line := entry.Start; line < entry.End; line++ {
+				removed[line] = true
+			}
+			continue
+		}
+
+		// テーブルは直前のコメントから次のテーブルの直前のコメントまでを削除する
+		start := entry.Start
+		for start > 0 && isTOMLComment(e.lines[start-1]) {
+			start--
+		}
+		end := len(e.lines)
+		for _, next := range entries[i+1:] {
+			if next.Table {
+				end = next.Start
+				for end > start && isTOMLComment(e.lines[end-1]) {
+					end--
+				}
+				break
+			}
+		}
+		for line := start; line < end; line++ {
+			removed[line] = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("key %s is not defined", key)
+	}
+
+	var lines []string
+	for i, line := range e.lines {
+		if !removed[i] {
+			lines = append(lines, line)
+		}
+	}
+	// ファイル末尾の改行を維持する
+	if len(lines) == 0 || lines[len(lines)-1] != "" {
+		lines = append(lines, "")
+	}
+	e.lines = lines
+	return nil
+}
+
+func (e *tomlEditor) SetVersion(version int) error {
+	return e.Put(toml.Key{"version"}, version)
+}
+
+func (e *tomlEditor) Bytes() ([]byte, error) {
+	return []byte(strings.Join(e.lines, "\n")), nil
+}
+
+// insertRootLine はルートのキーの行を追加する。ルートのキーはテーブルより前に書く必要があるため、
+// 最初のキーまたはテーブルの前に挿入する。
+func (e *tomlEditor) insertRootLine(line string) {
+	for i, current := range e.lines {
+		trimmed := strings.TrimSpace(current)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		inserted := []string{line}
+		if strings.HasPrefix(trimmed, "[") {
+			inserted = append(inserted, "")
+		}
+		e.insertLines(i, inserted...)
+		return
+	}
+
+	e.lines = append([]string{line}, e.lines...)
+}
+
+// value は [start, end) の行に定義されている値を返す
+func (e *tomlEditor) value(start, end int) (any, error) {
+	_, rest, _ := strings.Cut(e.lines[start], "=")
+	text := strings.Join(append([]string{rest}, e.lines[start+1:end]...), "\n")
+
+	var decoded map[string]any
+	if _, err := toml.Decode("v = "+text, &decoded); err != nil {
+		return nil, err
+	}
+	return decoded["v"], nil
+}
+
+func (e *tomlEditor) insertLines(index int, lines ...string) {
+	e.lines = append(e.lines[:index], append(lines, e.lines[index:]...)...)
+}
+
+// replace は key の行を "キー = 値" で置き換える
+func (e *tomlEditor) replace(key toml.Key, value any) error {
+	start, end, err := e.span(key)
+	if err != nil {
+		return err
+	}
+
+	encoded, err := encodeTOMLValue(value)
+	if err != nil {
+		return err
+	}
+
+	first := e.lines[start]
+	indent := first[:len(first)-len(strings.TrimLeft(first, " \t"))]
+	name, _, _ := strings.Cut(strings.TrimSpace(first), "=")
+	name = strings.TrimSpace(name)
+
+	if items, ok := value.([]any); ok {
+		if lines, ok := e.replaceArrayItems(start, end, items); ok {
+			_, comment := splitComment(first)
+			lines[0] = fmt.Sprintf("%s%s = [%s", indent, name, comment)
+			e.lines = append(e.lines[:start], append(lines, e.lines[end:]...)...)
+			return nil
+		}
+	}
+
+	_, comment := splitComment(e.lines[end-1])
+
+	replaced := fmt.Sprintf("%s%s = %s%s", indent, name, encoded, comment)
+	e.lines = append(e.lines[:start], append([]string{replaced}, e.lines[end:]...)...)
+	return nil
+}
+
+// replaceArrayItems は [start, end) の行に 1 行に 1 要素ずつ書かれた複数行の配列の要素を items で置き換えた行を返す。
+// 各要素の行のインデント、カンマ、行末コメントと、配列内のコメントの行は維持する。
+// 配列がこの形式でない場合や要素数が異なる場合は false を返す。
+func (e *tomlEditor) replaceArrayItems(start, end int, items []any) ([]string, bool) {
+	if end-start < 2 {
+		return nil, false
+	}
+	opening, _ := splitComment(e.lines[start])
+	_, rest, _ := strings.Cut(opening, "=")
+	closing, _ := splitComment(e.lines[end-1])
+	if strings.TrimSpace(rest) != "[" || strings.TrimSpace(closing) != "]" {
+		return nil, false
+	}
+
+	lines := append([]string{}, e.lines[start:end]...)
+	index := 0
+	for i := 1; i < len(lines)-1; i++ {
+		code, comment := splitComment(lines[i])
+		item := strings.TrimSpace(code)
+		if item == "" {
+			continue
+		}
+
+		comma := ""
+		if strings.HasSuffix(item, ",") {
+			item = strings.TrimSpace(strings.TrimSuffix(item, ","))
+			comma = ","
+		}
+		var decoded map[string]any
+		if _, err := toml.Decode("v = "+item, &decoded); err != nil || index >= len(items) {
+			return nil, false
+		}
+
+		encoded, err := encodeTOMLValue(items[index])
+		if err != nil {
+			return nil, false
+		}
+		indent := code[:len(code)-len(strings.TrimLeft(code, " \t"))]
+		lines[i] = indent + encoded + comma + comment
+		index++
+	}
+	if index != len(items) {
+		return nil, false
+	}
+
+	return lines, true
+}
+
+// span は key が定義されている行の範囲 [start, end) を返す。複数行の値にも対応する。
+func (e *tomlEditor) span(key toml.Key) (int, int, error) {
+	entries, err := e.entries()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, entry := range entries {
+		if !entry.Table && keyEquals(entry.Key, key) {
+			return entry.Start, entry.End, nil
+		}
+	}
+
+	return 0, 0, fmt.Errorf("key %s is not defined", key)
+}
+
+// entries はテーブルヘッダーとキーの定義を出現順に返す
+func (e *tomlEditor) entries() ([]tomlEntry, error) {
+	var entries []tomlEntry
+	var table []string
+
+	for i := 0; i < len(e.lines); i++ {
+		trimmed := strings.TrimSpace(e.lines[i])
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "[") {
+			header := strings.Trim(strings.SplitN(trimmed, "]", 2)[0], "[ ")
+			table = splitDottedKey(header)
+			entries = append(entries, tomlEntry{Key: table, Table: true, Start: i, End: i + 1})
+			continue
+		}
+
+		name, rest, found := strings.Cut(trimmed, "=")
+		if !found {
+			continue
+		}
+		fullKey := append(append([]string{}, table...), splitDottedKey(name)...)
+
+		// 値として解釈できるまで行を追加して、値の終わりを探す
+		value := rest
+		end := 0
+		for next := i + 1; next <= len(e.lines); next++ {
+			if next > i+1 {
+				value += "\n" + e.lines[next-1]
+			}
+			var decoded map[string]any
+			if _, err := toml.Decode("v = "+value, &decoded); err == nil {
+				end = next
+				break
+			}
+		}
+		if end == 0 {
+			return nil, fmt.Errorf("failed to parse the value of %s", toml.Key(fullKey))
+		}
+
+		entries = append(entries, tomlEntry{Key: fullKey, Start: i, End: end})
+		i = end - 1
+	}
+
+	return entries, nil
+}
+
+func isTOMLComment(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), "#")
+}
+
+// splitComment は行をコード部分と行末コメント（直前の空白を含む）に分割する
+func splitComment(line string) (string, string) {
+	var quote rune
+	escaped := false
+
+	for i, r := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case quote == '"' && r == '\\':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#':
+			code := strings.TrimRight(line[:i], " \t")
+			return code, line[len(code):]
+		}
+	}
+
+	return line, ""
+}
+
+// yamlEditor は yaml.Node を書き換えることで、コメントを維持したまま YAML を書き換える
+type yamlEditor struct {
+	document yaml.Node
+}
+
+func (e *yamlEditor) Set(key toml.Key, value any) error {
+	mapping, index, err := e.find(key)
+	if err != nil {
+		return err
+	}
+	return setYAMLValue(mapping, index, value)
+}
+
+func (e *yamlEditor) Append(key toml.Key, value any) error {
+	mapping, index, err := e.find(key)
+	if err != nil {
+		return err
+	}
+
+	sequence := mapping.Content[index+1]
+	if sequence.Kind != yaml.SequenceNode {
+		return fmt.Errorf("%s is not an array", key)
+	}
+
+	var valueNode yaml.Node
+	if err := valueNode.Encode(value); err != nil {
+		return err
+	}
+	sequence.Content = append(sequence.Content, &valueNode)
+	return nil
+}
+
+func (e *yamlEditor) Delete(key toml.Key) error {
+	mapping, index, err := e.find(key)
+	if err != nil {
+		return err
+	}
+	mapping.Content = append(mapping.Content[:index], mapping.Content[index+2:]...)
+	return nil
+}
+
+func (e *yamlEditor) Put(key toml.Key, value any) error {
+	if _, _, err := e.find(key); err == nil {
+		return e.Set(key, value)
+	}
+
+	node, err := e.root()
+	if err != nil {
+		return err
+	}
+	for _, part := range key[:len(key)-1] {
+		var child *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == part {
+				child = node.Content[i+1]
+				break
+			}
+		}
+
+		switch {
+		case child == nil:
+			child = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			node.Content = append(node.Content, newYAMLKey(part), child)
+		case child.Kind == yaml.ScalarNode && child.Tag == "!!null":
+			// 値が空のキーはテーブルとして扱う
+			*child = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", LineComment: child.LineComment}
+		case child.Kind != yaml.MappingNode:
+			return fmt.Errorf("%s is not a table", key)
+		}
+		node = child
+	}
+
+	var valueNode yaml.Node
+	if err := valueNode.Encode(value); err != nil {
+		return err
+	}
+	node.Content = append(node.Content, newYAMLKey(key[len(key)-1]), &valueNode)
+	return nil
+}
+
+func (e *yamlEditor) SetVersion(version int) error {
+	if _, _, err := e.find(toml.Key{"version"}); err == nil {
+		return e.Set(toml.Key{"version"}, version)
+	}
+
+	root, err := e.root()
+	if err != nil {
+		return err
+	}
+
+	keyNode := newYAMLKey("version")
+	var valueNode yaml.Node
+	if err := valueNode.Encode(version); err != nil {
+		return err
+	}
+
+	// ファイル先頭のコメントは version より前に残す
+	if len(root.Content) > 0 {
+		keyNode.HeadComment = root.Content[0].HeadComment
+		root.Content[0].HeadComment = ""
+	}
+	root.Content = append([]*yaml.Node{keyNode, &valueNode}, root.Content...)
+	return nil
+}
+
+// root はドキュメントのルートのマッピングを返す。空のドキュメントの場合は作成する。
+func (e *yamlEditor) root() (*yaml.Node, error) {
+	if len(e.document.Content) == 0 {
+		e.document = yaml.Node{
+			Kind:    yaml.DocumentNode,
+			Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}},
+		}
+	}
+	root := e.document.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("settings must be a mapping")
+	}
+	return root, nil
+}
+
+func newYAMLKey(name string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name}
+}
+
+func (e *yamlEditor) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&e.document); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// find は key を持つマッピングと、そのキーのノードの位置を返す
+func (e *yamlEditor) find(key toml.Key) (*yaml.Node, int, error) {
+	if len(e.document.Content) == 0 {
+		return nil, 0, fmt.Errorf("key %s is not defined", key)
+	}
+
+	node := e.document.Content[0]
+	for depth, part := range key {
+		if node.Kind != yaml.MappingNode {
+			break
+		}
+
+		index := -1
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == part {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			break
+		}
+
+		if depth == len(key)-1 {
+			return node, index, nil
+		}
+		node = node.Content[index+1]
+	}
+
+	return nil, 0, fmt.Errorf("key %s is not defined", key)
+}
+
+func setYAMLValue(mapping *yaml.Node, index int, value any) error {
+	var valueNode yaml.Node
+	if err := valueNode.Encode(value); err != nil {
+		return err
+	}
+
+	old := mapping.Content[index+1]
+	valueNode.LineComment = old.LineComment
+	valueNode.FootComment = old.FootComment
+	if valueNode.Kind == yaml.SequenceNode {
+		// 元の値がスカラーの場合は、行末コメントを同じ行に残すためフロースタイルにする
+		valueNode.Style = yaml.FlowStyle
+		if old.Kind == yaml.SequenceNode {
+			valueNode.Style = old.Style & yaml.FlowStyle
+		}
+	}
+
+	mapping.Content[index+1] = &valueNode
+	return nil
+}
+
+// jsonEditor は JSON を書き換える。JSON にはコメントがないため、値を書き換えて再エンコードする。
+type jsonEditor struct {
+	values map[string]any
+}
+
+func (e *jsonEditor) Set(key toml.Key, value any) error {
+	table, ok := lookupTable(e.values, key[:len(key)-1])
+	if !ok {
+		return fmt.Errorf("key %s is not defined", key)
+	}
+	table[key[len(key)-1]] = value
+	return nil
+}
+
+func (e *jsonEditor) Put(key toml.Key, value any) error {
+	table := e.values
+	for _, part := range key[:len(key)-1] {
+		child, ok := table[part]
+		if !ok || child == nil {
+			child = make(map[string]any)
+			table[part] = child
+		}
+		childTable, ok := child.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s is not a table", key)
+		}
+		table = childTable
+	}
+	table[key[len(key)-1]] = value
+	return nil
+}
+
+func (e *jsonEditor) Append(key toml.Key, value any) error {
+	current, _ := lookupValue(e.values, key)
+	items, ok := current.([]any)
+	if !ok {
+		return fmt.Errorf("%s is not an array", key)
+	}
+	return e.Set(key, append(items, value))
+}
+
+func (e *jsonEditor) Delete(key toml.Key) error {
+	table, ok := lookupTable(e.values, key[:len(key)-1])
+	if !ok {
+		return fmt.Errorf("key %s is not defined", key)
+	}
+	delete(table, key[len(key)-1])
+	return nil
+}
+
+func (e *jsonEditor) SetVersion(version int) error {
+	e.values["version"] = version
+	return nil
+}
+
+func (e *jsonEditor) Bytes() ([]byte, error) {
+	return EncodeValues(FormatJSON, e.values)
+}
+
+func lookupTable(values map[string]any, key toml.Key) (map[string]any, bool) {
+	table := values
+	for _, part := range key {
+		child, ok := table[part].(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		table = child
+	}
+	return table, true
+}
+
+func encodeTOMLValue(value any) (string, error) {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(map[string]any{"v": value}); err != nil {
+		return "", err
+	}
+	return strings.TrimPrefix(strings.TrimSpace(buf.String()), "v = "), nil
+}
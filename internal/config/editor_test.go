@@ -0,0 +1,37 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTOMLEditorSetMultiLineArray(t *testing.T) {
+	tests := map[string]struct {
+		content  string
+		expected string
+	}{
+		"one item per line": {
+			content:  "dirs = [  # layers\n  \"a\",  # first\n  # second\n  \"b\"\n]\n",
+			expected: "dirs = [  # layers\n  \"../a\",  # first\n  # second\n  \"../b\"\n]\n",
+		},
+		// 1 行に複数の要素がある場合は 1 行で書き直す
+		"several items per line": {
+			content:  "dirs = [\n  \"a\", \"b\",\n]  # layers\n",
+			expected: "dirs = [\"../a\", \"../b\"]  # layers\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			editor := &tomlEditor{lines: strings.Split(test.content, "\n")}
+			require.NoError(t, editor.Set([]string{"dirs"}, []any{"../a", "../b"}))
+
+			content, err := editor.Bytes()
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, string(content))
+		})
+	}
+}
@@ -0,0 +1,371 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/cateiru/system-prompt-gen/internal/util"
+)
+
+// ArrayMergeMode は extends で継承した設定ファイルの配列を、継承先でどう結合するかを表す
+type ArrayMergeMode string
+
+const (
+	// ArrayMergeReplace は継承先の配列で継承元の配列を置き換える（デフォルト）
+	ArrayMergeReplace ArrayMergeMode = "replace"
+	// ArrayMergeAppend は継承元の配列の後ろに継承先の配列を追加する
+	ArrayMergeAppend ArrayMergeMode = "append"
+)
+
+// ResolvedSettings は extends を解決してマージした設定ファイルの内容と、各値の定義元を保持する
+type ResolvedSettings struct {
+	// Values はマージ後の設定値（TOML のテーブル構造）
+	Values map[string]any
+	// Origins はキーのパス（例: "tools.claude.exclude"）ごとの定義元
+	Origins map[string][]string
+	// UnknownKeys は読み込んだ全ての設定ファイルに含まれる未知のキー
+	UnknownKeys []UnknownKey
+	// RelativePaths は解決した相対パス
+	RelativePaths []RelativePath
+	// Version は settingsPath の設定ファイルの version（継承元のファイルは含まない）
+	Version int
+}
+
+// ResolveSettings は settingsPath の設定ファイルを読み込み、extends で指定された設定ファイルを再帰的にマージする。
+// 設定ファイルの形式（TOML/YAML/JSON）は拡張子から判定する。
+// extends のパスは、それを記述した設定ファイルのディレクトリからの相対パスとして解決される。
+// [app] の input_dir/input_dirs/output_dir は、version 2 以降の設定ファイルでは設定ファイルのディレクトリから、
+// version 1 の設定ファイルではカレントディレクトリから解決される。
+func ResolveSettings(settingsPath string) (*ResolvedSettings, error) {
+	return ResolveSettingsWithRoot(settingsPath, "")
+}
+
+// ResolveSettingsWithRoot は ResolveSettings と同様に設定を読み込む。
+// version 1 の設定ファイルの相対パスは projectRoot（空の場合はカレントディレクトリ）から解決される。
+func ResolveSettingsWithRoot(settingsPath string, projectRoot string) (*ResolvedSettings, error) {
+	if projectRoot == "" {
+		currentDir, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		projectRoot = currentDir
+	}
+	absRoot, err := filepath.Abs(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	return resolveSettings(settingsPath, absRoot, nil)
+}
+
+func resolveSettings(settingsPath string, projectRoot string, chain []string) (*ResolvedSettings, error) {
+	absPath, err := filepath.Abs(settingsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if slices.Contains(chain, absPath) {
+		return nil, fmt.Errorf("extends cycle detected: %s", strings.Join(append(chain, absPath), " -> "))
+	}
+	chain = append(chain, absPath)
+
+	content, err := os.ReadFile(absPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return resolveSettingsContent(settingsPath, content, projectRoot, chain)
+}
+
+// resolveSettingsContent は settingsPath の設定ファイルの内容が content であるものとして、extends をマージする
+func resolveSettingsContent(settingsPath string, content []byte, projectRoot string, chain []string) (*ResolvedSettings, error) {
+	absPath, err := filepath.Abs(settingsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	format, err := FormatFromPath(absPath)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := decodeValues(format, content)
+	if err != nil {
+		return nil, err
+	}
+
+	var unknownKeys []UnknownKey
+	if format == FormatTOML {
+		unknownKeys, err = findUnknownKeys(settingsPath, string(content))
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		unknownKeys = findUnknownKeysInValues(settingsPath, format, content, values)
+	}
+
+	version, err := settingsVersion(values)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", settingsPath, err)
+	}
+
+	extends, err := stringList(values["extends"])
+	if err != nil {
+		return nil, fmt.Errorf("%s: extends must be an array of paths", settingsPath)
+	}
+
+	mode := ArrayMergeReplace
+	if value, ok := values["array_merge"]; ok {
+		mode = ArrayMergeMode(fmt.Sprint(value))
+		if mode != ArrayMergeReplace && mode != ArrayMergeAppend {
+			return nil, fmt.Errorf("%s: unknown array_merge %q", settingsPath, mode)
+		}
+	}
+
+	// version、extends、array_merge はファイル単位の指示なのでマージ結果には含めない
+	delete(values, "version")
+	delete(values, "extends")
+	delete(values, "array_merge")
+
+	// 相対パスは、version 2 以降ではそれを記述した設定ファイルのディレクトリから、
+	// version 1 では以前と同じくプロジェクトルートから解決する（migrate で version 2 に移行できる）
+	pathBaseDir := filepath.Dir(absPath)
+	if version < 2 {
+		pathBaseDir = projectRoot
+	}
+	relativePaths := resolvePathValues(values, pathBaseDir, settingsPath)
+	for i := range relativePaths {
+		relativePaths[i].Version = version
+	}
+
+	resolved := &ResolvedSettings{
+		Values:  make(map[string]any),
+		Origins: make(map[string][]string),
+		Version: version,
+	}
+
+	// 継承元同士は後ろに書かれたものが優先される
+	for _, base := range extends {
+		basePath := base
+		if !filepath.IsAbs(basePath) {
+			basePath = filepath.Join(filepath.Dir(absPath), basePath)
+		}
+		if _, err := os.Stat(basePath); err != nil {
+			return nil, fmt.Errorf("%s: failed to extend %s: %w", settingsPath, base, err)
+		}
+
+		baseResolved, err := resolveSettings(basePath, projectRoot, chain)
+		if err != nil {
+			return nil, err
+		}
+		mergeValues(resolved.Values, resolved.Origins, baseResolved.Values, baseResolved.Origins, ArrayMergeReplace, "")
+		resolved.UnknownKeys = append(resolved.UnknownKeys, baseResolved.UnknownKeys...)
+		resolved.RelativePaths = append(resolved.RelativePaths, baseResolved.RelativePaths...)
+	}
+	resolved.UnknownKeys = append(resolved.UnknownKeys, unknownKeys...)
+	resolved.RelativePaths = append(resolved.RelativePaths, relativePaths...)
+
+	ownOrigins := make(map[string][]string)
+	collectOrigins(ownOrigins, values, absPath, "")
+	mergeValues(resolved.Values, resolved.Origins, values, ownOrigins, mode, "")
+
+	return resolved, nil
+}
+
+// mergeValues は src を dst にマージする。テーブルは再帰的にマージし、配列は mode に従って結合し、
+// それ以外の値は src の値で置き換える。
+func mergeValues(dst map[string]any, dstOrigins map[string][]string, src map[string]any, srcOrigins map[string][]string, mode ArrayMergeMode, prefix string) {
+	for key, srcValue := range src {
+		path := joinKey(prefix, key)
+
+		srcTable, srcIsTable := srcValue.(map[string]any)
+		dstTable, dstIsTable := dst[key].(map[string]any)
+		if srcIsTable && dstIsTable {
+			mergeValues(dstTable, dstOrigins, srcTable, srcOrigins, mode, path)
+			continue
+		}
+
+		srcArray, srcIsArray := srcValue.([]any)
+		dstArray, dstIsArray := dst[key].([]any)
+		if srcIsArray && dstIsArray && mode == ArrayMergeAppend {
+			dst[key] = append(slices.Clone(dstArray), srcArray...)
+			dstOrigins[path] = append(dstOrigins[path], srcOrigins[path]...)
+			continue
+		}
+
+		for originKey := range dstOrigins {
+			if isKeyOrChild(originKey, path) {
+				delete(dstOrigins, originKey)
+			}
+		}
+		for originKey, origin := range srcOrigins {
+			if isKeyOrChild(originKey, path) {
+				dstOrigins[originKey] = slices.Clone(origin)
+			}
+		}
+		dst[key] = cloneValue(srcValue)
+	}
+}
+
+// cloneValue はテーブルと配列を再帰的に複製する
+func cloneValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		cloned := make(map[string]any, len(v))
+		for key, item := range v {
+			cloned[key] = cloneValue(item)
+		}
+		return cloned
+	case []any:
+		cloned := make([]any, len(v))
+		for i, item := range v {
+			cloned[i] = cloneValue(item)
+		}
+		return cloned
+	default:
+		return value
+	}
+}
+
+// collectOrigins は values に含まれる全ての値の定義元を origin として記録する
+func collectOrigins(origins map[string][]string, values map[string]any, origin string, prefix string) {
+	for key, value := range values {
+		path := joinKey(prefix, key)
+		if table, ok := value.(map[string]any); ok {
+			collectOrigins(origins, table, origin, path)
+			continue
+		}
+		origins[path] = []string{origin}
+	}
+}
+
+// Decode はマージ後の設定値を Settings に変換する
+func (r *ResolvedSettings) Decode() (*Settings, error) {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(r.Values); err != nil {
+		return nil, err
+	}
+
+	var settings Settings
+	if _, err := toml.Decode(buf.String(), &settings); err != nil {
+		return nil, err
+	}
+
+	return &settings, nil
+}
+
+// Format はマージ後の設定値を TOML 形式で返す。各値の行末には定義元をコメントとして付与する。
+func (r *ResolvedSettings) Format() (string, error) {
+	var out strings.Builder
+	if err := r.formatTable(&out, r.Values, nil); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+func (r *ResolvedSettings) formatTable(out *strings.Builder, table map[string]any, parts []string) error {
+	var keys, tableKeys []string
+	for key, value := range table {
+		if _, ok := value.(map[string]any); ok {
+			tableKeys = append(tableKeys, key)
+		} else {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	sort.Strings(tableKeys)
+
+	if len(parts) > 0 && len(keys) > 0 {
+		var header []string
+		for _, part := range parts {
+			header = append(header, quoteKey(part))
+		}
+		if out.Len() > 0 {
+			out.WriteString("\n")
+		}
+		out.WriteString(fmt.Sprintf("[%s]\n", strings.Join(header, ".")))
+	}
+
+	for _, key := range keys {
+		path := joinKey(strings.Join(parts, "."), key)
+
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(map[string]any{"v": table[key]}); err != nil {
+			return err
+		}
+		value := strings.TrimPrefix(strings.TrimSpace(buf.String()), "v = ")
+
+		var origins []string
+		for _, origin := range r.Origins[path] {
+			origins = append(origins, formatOrigin(origin))
+		}
+
+		out.WriteString(fmt.Sprintf("%s = %s  # %s\n", quoteKey(key), value, strings.Join(origins, ", ")))
+	}
+
+	for _, key := range tableKeys {
+		if err := r.formatTable(out, table[key].(map[string]any), append(slices.Clone(parts), key)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// formatOrigin はファイルパスの定義元を表示用に相対パスへ変換する
+func formatOrigin(origin string) string {
+	if filepath.IsAbs(origin) {
+		return util.ToRelativePath(origin)
+	}
+	return origin
+}
+
+var bareKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func quoteKey(key string) string {
+	if bareKeyPattern.MatchString(key) {
+		return key
+	}
+	return strconv.Quote(key)
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+func isKeyOrChild(key, path string) bool {
+	return key == path || strings.HasPrefix(key, path+".")
+}
+
+// stringList は TOML の配列を文字列のスライスに変換する
+func stringList(value any) ([]string, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	array, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("expected array, got %T", value)
+	}
+
+	var list []string
+	for _, item := range array {
+		str, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected string, got %T", item)
+		}
+		list = append(list, str)
+	}
+	return list, nil
+}
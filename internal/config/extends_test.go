@@ -0,0 +1,172 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSettingsFile(t *testing.T, path, content string) string {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestResolveSettings(t *testing.T) {
+	tempDir := t.TempDir()
+
+	sharedPath := writeSettingsFile(t, filepath.Join(tempDir, "shared", "settings.toml"), `[app]
+header = "shared header"
+footer = "shared footer"
+
+[tools.claude]
+generate = true
+exclude = ["temp*.md"]
+
+[tools.cline]
+generate = true
+`)
+
+	t.Run("deep merge with replaced arrays", func(t *testing.T) {
+		projectPath := writeSettingsFile(t, filepath.Join(tempDir, "replace", ".system_prompt", "settings.toml"), `extends = ["../../shared/settings.toml"]
+
+[app]
+header = "project header"
+
+[tools.claude]
+exclude = ["secret*.md"]
+`)
+
+		resolved, err := ResolveSettings(projectPath)
+		require.NoError(t, err)
+
+		settings, err := resolved.Decode()
+		require.NoError(t, err)
+
+		assert.Equal(t, "project header", settings.App.Header)
+		assert.Equal(t, "shared footer", settings.App.Footer)
+		assert.True(t, settings.Tools["claude"].Generate)
+		assert.Equal(t, []string{"secret*.md"}, settings.Tools["claude"].Exclude)
+		assert.True(t, settings.Tools["cline"].Generate)
+		assert.Empty(t, settings.Extends)
+
+		absProjectPath, err := filepath.Abs(projectPath)
+		require.NoError(t, err)
+		assert.Equal(t, []string{absProjectPath}, resolved.Origins["app.header"])
+		assert.Equal(t, []string{sharedPath}, resolved.Origins["app.footer"])
+		assert.Equal(t, []string{absProjectPath}, resolved.Origins["tools.claude.exclude"])
+	})
+
+	t.Run("appended arrays", func(t *testing.T) {
+		projectPath := writeSettingsFile(t, filepath.Join(tempDir, "append", "settings.toml"), `extends = ["../shared/settings.toml"]
+array_merge = "append"
+
+[tools.claude]
+exclude = ["secret*.md"]
+`)
+
+		resolved, err := ResolveSettings(projectPath)
+		require.NoError(t, err)
+
+		settings, err := resolved.Decode()
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"temp*.md", "secret*.md"}, settings.Tools["claude"].Exclude)
+		assert.Len(t, resolved.Origins["tools.claude.exclude"], 2)
+	})
+
+	t.Run("later extends take priority", func(t *testing.T) {
+		writeSettingsFile(t, filepath.Join(tempDir, "multi", "a.toml"), "[app]\nheader = \"a\"\nfooter = \"a\"\n")
+		writeSettingsFile(t, filepath.Join(tempDir, "multi", "b.toml"), "[app]\nheader = \"b\"\n")
+		projectPath := writeSettingsFile(t, filepath.Join(tempDir, "multi", "settings.toml"), "extends = [\"a.toml\", \"b.toml\"]\n")
+
+		resolved, err := ResolveSettings(projectPath)
+		require.NoError(t, err)
+
+		settings, err := resolved.Decode()
+		require.NoError(t, err)
+
+		assert.Equal(t, "b", settings.App.Header)
+		assert.Equal(t, "a", settings.App.Footer)
+	})
+
+	t.Run("cycle is detected", func(t *testing.T) {
+		writeSettingsFile(t, filepath.Join(tempDir, "cycle", "a.toml"), "extends = [\"b.toml\"]\n")
+		projectPath := writeSettingsFile(t, filepath.Join(tempDir, "cycle", "b.toml"), "extends = [\"a.toml\"]\n")
+
+		_, err := ResolveSettings(projectPath)
+		assert.ErrorContains(t, err, "cycle")
+	})
+
+	t.Run("missing extended file", func(t *testing.T) {
+		projectPath := writeSettingsFile(t, filepath.Join(tempDir, "missing", "settings.toml"), "extends = [\"nothing.toml\"]\n")
+
+		_, err := ResolveSettings(projectPath)
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown array_merge", func(t *testing.T) {
+		projectPath := writeSettingsFile(t, filepath.Join(tempDir, "unknown", "settings.toml"), "array_merge = \"merge\"\n")
+
+		_, err := ResolveSettings(projectPath)
+		assert.Error(t, err)
+	})
+}
+
+func TestResolvedSettingsFormat(t *testing.T) {
+	resolved := &ResolvedSettings{
+		Values: map[string]any{
+			"app": map[string]any{
+				"header": "header",
+			},
+			"tools": map[string]any{
+				"claude": map[string]any{
+					"generate": true,
+					"exclude":  []any{"temp*.md", "secret*.md"},
+				},
+			},
+		},
+		Origins: map[string][]string{
+			"app.header":            {"settings.toml"},
+			"tools.claude.generate": {"shared.toml"},
+			"tools.claude.exclude":  {"shared.toml", "settings.toml"},
+		},
+	}
+
+	content, err := resolved.Format()
+	require.NoError(t, err)
+
+	expected := `[app]
+header = "header"  # settings.toml
+
+[tools.claude]
+exclude = ["temp*.md", "secret*.md"]  # shared.toml, settings.toml
+generate = true  # shared.toml
+`
+	assert.Equal(t, expected, content)
+}
+
+func TestLoadSettingsWithExtends(t *testing.T) {
+	tempDir := t.TempDir()
+
+	writeSettingsFile(t, filepath.Join(tempDir, "shared.toml"), `[tools.claude]
+generate = true
+exclude = ["temp*.md"]
+`)
+	projectPath := writeSettingsFile(t, filepath.Join(tempDir, "settings.toml"), `extends = ["shared.toml"]
+
+[app]
+header = "header"
+`)
+
+	settings, err := LoadSettings(projectPath)
+	require.NoError(t, err)
+
+	assert.Equal(t, "header", settings.App.Header)
+	assert.Equal(t, FileName("CLAUDE.md"), settings.Tools["claude"].FileName)
+	assert.Equal(t, []string{"temp*.md"}, settings.Tools["claude"].Exclude)
+}
@@ -0,0 +1,208 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Format は設定ファイルの形式
+type Format string
+
+const (
+	FormatTOML Format = "toml"
+	FormatYAML Format = "yaml"
+	FormatJSON Format = "json"
+)
+
+// settingsExtensions は自動検出の対象となる設定ファイルの拡張子（優先度順）
+var settingsExtensions = []string{".toml", ".yaml", ".yml", ".json"}
+
+// ParseFormat は "toml"/"yaml"/"yml"/"json" を Format に変換する
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(strings.TrimPrefix(name, ".")) {
+	case "toml":
+		return FormatTOML, nil
+	case "yaml", "yml":
+		return FormatYAML, nil
+	case "json":
+		return FormatJSON, nil
+	default:
+		return "", fmt.Errorf("unsupported settings format %q", name)
+	}
+}
+
+// FormatFromPath は拡張子から設定ファイルの形式を判定する。拡張子がない場合は TOML として扱う。
+func FormatFromPath(path string) (Format, error) {
+	ext := filepath.Ext(path)
+	if ext == "" {
+		return FormatTOML, nil
+	}
+	return ParseFormat(ext)
+}
+
+// FindSettingsFile は settingsPath が存在しない場合、同じディレクトリにある同名の
+// settings.yaml/settings.yml/settings.json を探す。見つからない場合は settingsPath をそのまま返す。
+func FindSettingsFile(settingsPath string) string {
+	if _, err := os.Stat(settingsPath); err == nil {
+		return settingsPath
+	}
+
+	base := strings.TrimSuffix(settingsPath, filepath.Ext(settingsPath))
+	for _, ext := range settingsExtensions {
+		candidate := base + ext
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+
+	return settingsPath
+}
+
+// ReadSettingsValues は 1 つの設定ファイルを extends を解決せずに読み込む
+func ReadSettingsValues(settingsPath string) (map[string]any, error) {
+	format, err := FormatFromPath(settingsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(settingsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeValues(format, content)
+}
+
+// ConvertSettings は設定ファイルを extends を解決せずに別の形式に変換する。
+// コメントは変換後のファイルに引き継がれない。
+func ConvertSettings(settingsPath string, to Format) ([]byte, error) {
+	values, err := ReadSettingsValues(settingsPath)
+	if err != nil {
+		return nil, err
+	}
+	return EncodeValues(to, values)
+}
+
+func decodeValues(format Format, content []byte) (map[string]any, error) {
+	values := make(map[string]any)
+
+	switch format {
+	case FormatTOML:
+		if _, err := toml.Decode(string(content), &values); err != nil {
+			return nil, err
+		}
+	case FormatYAML:
+		if err := yaml.Unmarshal(content, &values); err != nil {
+			return nil, err
+		}
+		if values == nil {
+			// 空の YAML ファイルは空の設定として扱う
+			values = make(map[string]any)
+		}
+	case FormatJSON:
+		if err := json.Unmarshal(content, &values); err != nil {
+			return nil, err
+		}
+		integerFloats(values)
+	default:
+		return nil, fmt.Errorf("unsupported settings format %q", format)
+	}
+
+	// TOML には null がないため、YAML/JSON の null は未指定として扱う
+	if err := dropNulls(values, ""); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// dropNulls はテーブルから値が null のキーを再帰的に削除する。path はテーブルのキーのパス。
+// 配列の null の要素は削除すると要素数が変わってしまうため、エラーにする。
+func dropNulls(values map[string]any, path string) error {
+	for name, value := range values {
+		if value == nil {
+			delete(values, name)
+			continue
+		}
+		key := name
+		if path != "" {
+			key = path + "." + name
+		}
+		if err := dropNullValue(value, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func dropNullValue(value any, path string) error {
+	switch v := value.(type) {
+	case map[string]any:
+		return dropNulls(v, path)
+	case []any:
+		for i, item := range v {
+			itemPath := fmt.Sprintf("%s[%d]", path, i)
+			if item == nil {
+				return fmt.Errorf("%s: null is not allowed in arrays", itemPath)
+			}
+			if err := dropNullValue(item, itemPath); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// integerFloats はテーブルと配列に含まれる整数の float64 を再帰的に int64 に変換する。
+// JSON の数値は float64 としてデコードされるため、そのままでは整数の設定値に代入できない
+func integerFloats(values map[string]any) {
+	for key, value := range values {
+		values[key] = integerFloatValue(value)
+	}
+}
+
+func integerFloatValue(value any) any {
+	switch v := value.(type) {
+	case float64:
+		if v == math.Trunc(v) {
+			return int64(v)
+		}
+	case map[string]any:
+		integerFloats(v)
+	case []any:
+		for i, item := range v {
+			v[i] = integerFloatValue(item)
+		}
+	}
+	return value
+}
+
+// EncodeValues は設定値を指定された形式で出力する
+func EncodeValues(format Format, values map[string]any) ([]byte, error) {
+	switch format {
+	case FormatTOML:
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(values); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case FormatYAML:
+		return yaml.Marshal(values)
+	case FormatJSON:
+		data, err := json.MarshalIndent(values, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	default:
+		return nil, fmt.Errorf("unsupported settings format %q", format)
+	}
+}
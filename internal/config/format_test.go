@@ -0,0 +1,218 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFormat(t *testing.T) {
+	for name, expected := range map[string]Format{
+		"toml":  FormatTOML,
+		"YAML":  FormatYAML,
+		"yml":   FormatYAML,
+		".json": FormatJSON,
+	} {
+		format, err := ParseFormat(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, expected, format, name)
+	}
+
+	_, err := ParseFormat("ini")
+	assert.Error(t, err)
+}
+
+func TestFindSettingsFile(t *testing.T) {
+	tempDir := t.TempDir()
+	settingsPath := filepath.Join(tempDir, "settings.toml")
+
+	t.Run("returns the path as is when nothing exists", func(t *testing.T) {
+		assert.Equal(t, settingsPath, FindSettingsFile(settingsPath))
+	})
+
+	t.Run("falls back to another extension", func(t *testing.T) {
+		jsonPath := writeSettingsFile(t, filepath.Join(tempDir, "settings.json"), "{}")
+		assert.Equal(t, jsonPath, FindSettingsFile(settingsPath))
+
+		yamlPath := writeSettingsFile(t, filepath.Join(tempDir, "settings.yaml"), "")
+		assert.Equal(t, yamlPath, FindSettingsFile(settingsPath), "yaml has priority over json")
+	})
+
+	t.Run("prefers the given path", func(t *testing.T) {
+		writeSettingsFile(t, settingsPath, "")
+		assert.Equal(t, settingsPath, FindSettingsFile(settingsPath))
+	})
+}
+
+func TestLoadSettingsFormats(t *testing.T) {
+	tempDir := t.TempDir()
+
+	contents := map[string]string{
+		"settings.toml": `[app]
+header = "header"
+
+[tools.claude]
+generate = true
+exclude = ["draft*.md"]
+`,
+		"settings.yaml": `app:
+  header: header
+tools:
+  claude:
+    generate: true
+    exclude:
+      - draft*.md
+`,
+		"settings.json": `{
+  "app": {"header": "header"},
+  "tools": {
+    "claude": {"generate": true, "exclude": ["draft*.md"]}
+  }
+}
+`,
+	}
+
+	for name, content := range contents {
+		t.Run(name, func(t *testing.T) {
+			settingsPath := writeSettingsFile(t, filepath.Join(tempDir, name), content)
+
+			settings, err := LoadSettings(settingsPath)
+			require.NoError(t, err)
+
+			assert.Equal(t, "header", settings.App.Header)
+			require.Contains(t, settings.Tools, "claude")
+			assert.True(t, settings.Tools["claude"].Generate)
+			assert.Equal(t, []string{"draft*.md"}, settings.Tools["claude"].Exclude)
+			assert.Equal(t, FileName("CLAUDE.md"), settings.Tools["claude"].FileName)
+			assert.Empty(t, settings.UnknownKeys)
+		})
+	}
+}
+
+func TestLoadSettingsExtendsAcrossFormats(t *testing.T) {
+	tempDir := t.TempDir()
+
+	writeSettingsFile(t, filepath.Join(tempDir, "base.yaml"), `app:
+  footer: base footer
+tools:
+  claude:
+    generate: true
+`)
+	settingsPath := writeSettingsFile(t, filepath.Join(tempDir, "settings.json"), `{
+  "extends": ["base.yaml"],
+  "app": {"header": "project header"}
+}`)
+
+	settings, err := LoadSettings(settingsPath)
+	require.NoError(t, err)
+
+	assert.Equal(t, "project header", settings.App.Header)
+	assert.Equal(t, "base footer", settings.App.Footer)
+	assert.True(t, settings.Tools["claude"].Generate)
+}
+
+func TestFindUnknownKeysInValues(t *testing.T) {
+	tempDir := t.TempDir()
+
+	t.Run("yaml", func(t *testing.T) {
+		settingsPath := writeSettingsFile(t, filepath.Join(tempDir, "settings.yaml"), `app:
+  header: header
+tools:
+  claude:
+    generate: true
+    exlude:
+      - draft*.md
+`)
+
+		resolved, err := ResolveSettings(settingsPath)
+		require.NoError(t, err)
+
+		require.Len(t, resolved.UnknownKeys, 1)
+		assert.Equal(t, "tools.claude.exlude", resolved.UnknownKeys[0].Key)
+		assert.Equal(t, 6, resolved.UnknownKeys[0].Line)
+		assert.Equal(t, "tools.claude.exclude", resolved.UnknownKeys[0].Suggestion)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		settingsPath := writeSettingsFile(t, filepath.Join(tempDir, "settings.json"), `{
+  "app": {
+    "heder": "header"
+  },
+  "tool": {}
+}`)
+
+		resolved, err := ResolveSettings(settingsPath)
+		require.NoError(t, err)
+
+		require.Len(t, resolved.UnknownKeys, 2)
+		assert.Equal(t, "app.heder", resolved.UnknownKeys[0].Key)
+		assert.Equal(t, 3, resolved.UnknownKeys[0].Line)
+		assert.Equal(t, "app.header", resolved.UnknownKeys[0].Suggestion)
+		assert.Equal(t, "tool", resolved.UnknownKeys[1].Key)
+		assert.Equal(t, 5, resolved.UnknownKeys[1].Line)
+		assert.Equal(t, "tools", resolved.UnknownKeys[1].Suggestion)
+	})
+}
+
+func TestConvertSettings(t *testing.T) {
+	tempDir := t.TempDir()
+
+	settingsPath := writeSettingsFile(t, filepath.Join(tempDir, "settings.toml"), `# comment
+[app]
+header = "header"
+
+[tools.claude]
+generate = true
+include = ["*.md"]
+`)
+
+	for _, format := range []Format{FormatYAML, FormatJSON, FormatTOML} {
+		t.Run(string(format), func(t *testing.T) {
+			content, err := ConvertSettings(settingsPath, format)
+			require.NoError(t, err)
+			assert.NotContains(t, string(content), "comment")
+
+			convertedPath := writeSettingsFile(t, filepath.Join(tempDir, "converted", "settings."+string(format)), string(content))
+
+			settings, err := LoadSettings(convertedPath)
+			require.NoError(t, err)
+			assert.Equal(t, "header", settings.App.Header)
+			assert.True(t, settings.Tools["claude"].Generate)
+			assert.Equal(t, []string{"*.md"}, settings.Tools["claude"].Include)
+		})
+	}
+}
+
+func TestLoadSettingsNullValues(t *testing.T) {
+	settingsPath := writeSettingsFile(t, filepath.Join(t.TempDir(), "settings.yaml"), `tools:
+  claude:
+    generate: true
+    include:
+`)
+
+	resolved, err := ResolveSettings(settingsPath)
+	require.NoError(t, err)
+	assert.NotContains(t, resolved.Values["tools"].(map[string]any)["claude"], "include")
+
+	settings, err := LoadSettings(settingsPath)
+	require.NoError(t, err)
+	assert.Nil(t, settings.Tools["claude"].Include)
+}
+
+func TestDecodeValuesNestedArrays(t *testing.T) {
+	// 配列の中の整数も変換し、テーブルの null は削除する
+	values, err := decodeValues(FormatJSON, []byte(`{"a": [1, 1.5, [2], {"b": 3, "c": null}]}`))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"a": []any{int64(1), 1.5, []any{int64(2)}, map[string]any{"b": int64(3)}},
+	}, values)
+
+	// 配列の null の要素は要素数が変わるため削除せずエラーにする
+	_, err = decodeValues(FormatYAML, []byte("tools:\n  claude:\n    include:\n      - a.md\n      -\n"))
+	assert.EqualError(t, err, "tools.claude.include[1]: null is not allowed in arrays")
+
+	_, err = decodeValues(FormatJSON, []byte(`{"a": [{"b": [null]}]}`))
+	assert.EqualError(t, err, "a[0].b[0]: null is not allowed in arrays")
+}
@@ -0,0 +1,185 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/BurntSushi/toml"
+)
+
+// MigrationChange は migrate で設定ファイルのディレクトリからの相対パスに書き換えた値
+type MigrationChange struct {
+	// Key はキーのパス
+	Key string
+	// Old は変更前の値
+	Old string
+	// New は変更後の値
+	New string
+}
+
+// MigrationResult は MigrateSettings の結果
+type MigrationResult struct {
+	FromVersion int
+	ToVersion   int
+	Changes     []MigrationChange
+	// Content は書き換え後の設定ファイルの内容
+	Content []byte
+}
+
+// migrationContext は移行処理に必要な情報
+type migrationContext struct {
+	// settingsDir は設定ファイルのディレクトリ
+	settingsDir string
+	// oldBaseDir は version 1 で相対パスの基準だったディレクトリ
+	oldBaseDir string
+}
+
+// MigrateSettings は settingsPath の設定ファイルを現在のバージョンの形式に書き換えた内容を返す。
+// ファイルへの書き込みは行わない。oldBaseDir は version 1 で相対パスの基準となっていた
+// ディレクトリ（プロジェクトルートまたはカレントディレクトリ）。
+// TOML と YAML のコメントは維持される。extends で継承した設定ファイルは書き換えない。
+func MigrateSettings(settingsPath string, oldBaseDir string) (*MigrationResult, error) {
+	format, err := FormatFromPath(settingsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(settingsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	absPath, err := filepath.Abs(settingsPath)
+	if err != nil {
+		return nil, err
+	}
+	absBaseDir, err := filepath.Abs(oldBaseDir)
+	if err != nil {
+		return nil, err
+	}
+	ctx := migrationContext{
+		settingsDir: filepath.Dir(absPath),
+		oldBaseDir:  absBaseDir,
+	}
+
+	values, err := decodeValues(format, content)
+	if err != nil {
+		return nil, err
+	}
+	version, err := settingsVersion(values)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MigrationResult{
+		FromVersion: version,
+		ToVersion:   CurrentVersion,
+		Content:     content,
+	}
+	if version == CurrentVersion {
+		return result, nil
+	}
+
+	// version 1 から 2 への移行のみのため、version の行と相対パスの値のみを書き換える
+	editor, err := newSettingsEditor(format, content)
+	if err != nil {
+		return nil, err
+	}
+	if result.Changes, err = migrateV1ToV2(editor, values, ctx); err != nil {
+		return nil, err
+	}
+	if err := editor.SetVersion(CurrentVersion); err != nil {
+		return nil, err
+	}
+	if result.Content, err = editor.Bytes(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// migrateV1ToV2 は相対パスを設定ファイルのディレクトリからのパスに書き換える
+func migrateV1ToV2(editor settingsEditor, values map[string]any, ctx migrationContext) ([]MigrationChange, error) {
+	prefixes := []toml.Key{{"app"}}
+
+	profiles, _ := values["profiles"].(map[string]any)
+	var names []string
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		prefixes = append(prefixes, toml.Key{"profiles", name, "app"})
+	}
+
+	var changes []MigrationChange
+	for _, prefix := range prefixes {
+		app, ok := lookupTable(values, prefix)
+		if !ok {
+			continue
+		}
+
+		if inputDirs, ok := app["input_dirs"].([]any); ok {
+			key := appendKey(prefix, "input_dirs")
+			rebased := make([]any, len(inputDirs))
+			changed := false
+			for i, item := range inputDirs {
+				rebased[i] = item
+				if path, ok := item.(string); ok {
+					rebased[i] = ctx.rebase(path)
+					changed = changed || rebased[i] != path
+				}
+			}
+			if changed {
+				if err := editor.Set(key, rebased); err != nil {
+					return nil, err
+				}
+				changes = append(changes, MigrationChange{Key: key.String(), Old: formatValue(inputDirs), New: formatValue(rebased)})
+			}
+		}
+
+		for _, name := range []string{"input_dir", "output_dir"} {
+			path, ok := app[name].(string)
+			if !ok {
+				continue
+			}
+			key := appendKey(prefix, name)
+			if rebased := ctx.rebase(path); rebased != path {
+				if err := editor.Set(key, rebased); err != nil {
+					return nil, err
+				}
+				changes = append(changes, MigrationChange{Key: key.String(), Old: formatValue(path), New: formatValue(rebased)})
+			}
+		}
+	}
+
+	return changes, nil
+}
+
+// rebase は oldBaseDir からの相対パスを settingsDir からの相対パスに変換する
+func (ctx migrationContext) rebase(path string) string {
+	if resolveRelativePath(ctx.oldBaseDir, path) == path {
+		return path
+	}
+
+	target := filepath.Join(ctx.oldBaseDir, path)
+	relPath, err := filepath.Rel(ctx.settingsDir, target)
+	if err != nil {
+		return filepath.ToSlash(target)
+	}
+	return filepath.ToSlash(relPath)
+}
+
+func appendKey(key toml.Key, name string) toml.Key {
+	return append(append(toml.Key{}, key...), name)
+}
+
+// formatValue は値を TOML の表記で返す
+func formatValue(value any) string {
+	encoded, err := encodeTOMLValue(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return encoded
+}
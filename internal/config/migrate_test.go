@@ -0,0 +1,118 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files in testdata")
+
+// TestMigrateSettingsGolden は testdata/migrate/*.input.* を移行した結果を *.golden.* と比較する。
+// golden ファイルは go test ./internal/config -run TestMigrateSettingsGolden -update で更新できる。
+func TestMigrateSettingsGolden(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "migrate", "*.input.*"))
+	require.NoError(t, err)
+	require.NotEmpty(t, inputs)
+
+	for _, input := range inputs {
+		name := filepath.Base(input)
+		t.Run(name, func(t *testing.T) {
+			content, err := os.ReadFile(input)
+			require.NoError(t, err)
+
+			// version 1 ではプロジェクトルートからの相対パスだった設定を .system_prompt に置いて移行する
+			projectRoot := t.TempDir()
+			settingsPath := writeSettingsFile(t, filepath.Join(projectRoot, ".system_prompt", "settings"+filepath.Ext(input)), string(content))
+
+			result, err := MigrateSettings(settingsPath, projectRoot)
+			require.NoError(t, err)
+			assert.Equal(t, CurrentVersion, result.ToVersion)
+
+			golden := strings.Replace(input, ".input.", ".golden.", 1)
+			if *updateGolden {
+				require.NoError(t, os.WriteFile(golden, result.Content, 0644))
+			}
+
+			expected, err := os.ReadFile(golden)
+			require.NoError(t, err)
+			assert.Equal(t, string(expected), string(result.Content))
+
+			// 移行後の設定ファイルは読み込めて、未知のキーを含まない
+			writeSettingsFile(t, filepath.Join(projectRoot, ".system_prompt", "base.toml"), "")
+			writeSettingsFile(t, settingsPath, string(result.Content))
+			resolved, err := ResolveSettings(settingsPath)
+			require.NoError(t, err)
+			assert.Equal(t, CurrentVersion, resolved.Version)
+			assert.Empty(t, resolved.UnknownKeys)
+		})
+	}
+}
+
+func TestMigrateSettingsChanges(t *testing.T) {
+	projectRoot := t.TempDir()
+	settingsPath := writeSettingsFile(t, filepath.Join(projectRoot, ".system_prompt", "settings.toml"), `[app]
+input_dir = "prompts"
+output_dir = "docs"
+
+[profiles.ci.app]
+input_dirs = ["shared"]
+`)
+
+	result, err := MigrateSettings(settingsPath, projectRoot)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, result.FromVersion)
+	assert.Equal(t, []MigrationChange{
+		{Key: "app.input_dir", Old: `"prompts"`, New: `"../prompts"`},
+		{Key: "app.output_dir", Old: `"docs"`, New: `"../docs"`},
+		{Key: "profiles.ci.app.input_dirs", Old: `["shared"]`, New: `["../shared"]`},
+	}, result.Changes)
+}
+
+func TestSettingsVersion(t *testing.T) {
+	for name, test := range map[string]struct {
+		values   map[string]any
+		expected int
+		hasError bool
+	}{
+		"missing":  {values: map[string]any{}, expected: 1},
+		"toml":     {values: map[string]any{"version": int64(2)}, expected: 2},
+		"yaml":     {values: map[string]any{"version": 1}, expected: 1},
+		"json":     {values: map[string]any{"version": float64(2)}, expected: 2},
+		"fraction": {values: map[string]any{"version": 1.5}, hasError: true},
+		"string":   {values: map[string]any{"version": "2"}, hasError: true},
+		"zero":     {values: map[string]any{"version": int64(0)}, hasError: true},
+		"newer":    {values: map[string]any{"version": int64(CurrentVersion + 1)}, hasError: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			version, err := settingsVersion(test.values)
+			if test.hasError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, version)
+		})
+	}
+}
+
+func TestPathMigrationsOnlyForVersion1(t *testing.T) {
+	projectRoot := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(projectRoot, "docs"), 0755))
+
+	settingsPath := writeSettingsFile(t, filepath.Join(projectRoot, ".system_prompt", "settings.toml"), `version = 2
+
+[app]
+output_dir = "docs"
+`)
+
+	settings, err := LoadSettingsWithOptions(settingsPath, LoadOptions{ProjectRoot: projectRoot})
+	require.NoError(t, err)
+	assert.Empty(t, settings.PathMigrations)
+}
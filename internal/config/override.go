@@ -0,0 +1,224 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// EnvPrefix は設定を上書きする環境変数の接頭辞
+const EnvPrefix = "SPG_"
+
+// Override は環境変数または --set による設定値の上書き
+type Override struct {
+	// Key は上書きするキーのパス
+	Key toml.Key
+	// Value は上書き後の値
+	Value any
+	// Origin は上書きの定義元（例: "env SPG_APP_OUTPUT_DIR", "--set"）
+	Origin string
+}
+
+// ParseOverrides は環境変数と --set の値から設定の上書きを作成する。
+// environ は "KEY=VALUE" 形式で、SPG_ で始まるもののみを対象とする。
+// SPG_ は他のツールも使用しうる接頭辞のため、設定に一致しない環境変数はエラーにせず、名前順に unknownEnv として返す。
+// 適用順は環境変数（名前順）、--set（指定順）で、後に適用されたものが優先される。
+func ParseOverrides(environ []string, sets []string) (overrides []Override, unknownEnv []string, err error) {
+	var envOverrides []Override
+	for _, env := range environ {
+		name, raw, found := strings.Cut(env, "=")
+		if !found || !strings.HasPrefix(name, EnvPrefix) {
+			continue
+		}
+
+		key, ok := envKey(strings.TrimPrefix(name, EnvPrefix))
+		if !ok {
+			unknownEnv = append(unknownEnv, name)
+			continue
+		}
+
+		override, err := newOverride(key, raw, "env "+name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("environment variable %s: %w", name, err)
+		}
+		envOverrides = append(envOverrides, override)
+	}
+	sort.SliceStable(envOverrides, func(i, j int) bool {
+		return envOverrides[i].Origin < envOverrides[j].Origin
+	})
+	sort.Strings(unknownEnv)
+
+	overrides = envOverrides
+	for _, set := range sets {
+		name, raw, found := strings.Cut(set, "=")
+		if !found {
+			return nil, nil, fmt.Errorf("--set %q must be in the form key=value", set)
+		}
+
+		key := toml.Key(splitDottedKey(name))
+		if _, ok := typeAtPath(key); !ok {
+			key = unknownPrefix(key)
+			if suggestion := suggestKey(key); suggestion != "" {
+				return nil, nil, fmt.Errorf("--set: unknown key %q (did you mean %q?)", key, suggestion)
+			}
+			return nil, nil, fmt.Errorf("--set: unknown key %q", key)
+		}
+
+		override, err := newOverride(key, raw, "--set")
+		if err != nil {
+			return nil, nil, fmt.Errorf("--set %s: %w", name, err)
+		}
+		overrides = append(overrides, override)
+	}
+
+	return overrides, unknownEnv, nil
+}
+
+// UnknownEnvError は strict モードで設定に一致しない SPG_* 環境変数が見つかった場合のエラー
+type UnknownEnvError struct {
+	Names []string
+}
+
+func (e *UnknownEnvError) Error() string {
+	return fmt.Sprintf("environment variables do not match any setting: %s", strings.Join(e.Names, ", "))
+}
+
+// ApplyOverrides は上書きを順番に設定値へ適用する。配列は上書き後の値で置き換えられる。
+func (r *ResolvedSettings) ApplyOverrides(overrides []Override) {
+	for _, override := range overrides {
+		var value any = override.Value
+		for i := len(override.Key) - 1; i >= 0; i-- {
+			value = map[string]any{override.Key[i]: value}
+		}
+
+		origins := map[string][]string{
+			strings.Join(override.Key, "."): {override.Origin},
+		}
+		mergeValues(r.Values, r.Origins, value.(map[string]any), origins, ArrayMergeReplace, "")
+	}
+}
+
+func newOverride(key toml.Key, raw string, origin string) (Override, error) {
+	if key[0] == "extends" || key[0] == "array_merge" {
+		return Override{}, fmt.Errorf("%s cannot be overridden", key[0])
+	}
+
+	typ, _ := typeAtPath(key)
+	value, err := parseOverrideValue(typ, raw)
+	if err != nil {
+		return Override{}, err
+	}
+
+	return Override{
+		Key:    key,
+		Value:  value,
+		Origin: origin,
+	}, nil
+}
+
+// parseOverrideValue は文字列の値を typ に合わせて変換する。
+// 配列は TOML の配列（["a.md", "b.md"]）またはカンマ区切りで指定する。
+func parseOverrideValue(typ reflect.Type, raw string) (any, error) {
+	switch typ.Kind() {
+	case reflect.String:
+		return raw, nil
+	case reflect.Bool:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid boolean %q", raw)
+		}
+		return value, nil
+	case reflect.Int:
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer %q", raw)
+		}
+		return int64(value), nil
+	case reflect.Slice:
+		raw = strings.TrimSpace(raw)
+		if strings.HasPrefix(raw, "[") {
+			var decoded struct {
+				V []string `toml:"v"`
+			}
+			if _, err := toml.Decode("v = "+raw, &decoded); err != nil {
+				return nil, fmt.Errorf("invalid array %s: %w", raw, err)
+			}
+			return stringsToValues(decoded.V), nil
+		}
+
+		var items []string
+		if raw != "" {
+			for _, item := range strings.Split(raw, ",") {
+				items = append(items, strings.TrimSpace(item))
+			}
+		}
+		return stringsToValues(items), nil
+	default:
+		return nil, fmt.Errorf("tables cannot be overridden")
+	}
+}
+
+func stringsToValues(items []string) []any {
+	values := make([]any, 0, len(items))
+	for _, item := range items {
+		values = append(values, item)
+	}
+	return values
+}
+
+// envKey は環境変数名（SPG_ を除いた部分）を設定のキーのパスに変換する。
+// キー名に含まれる "_" と区切りの "_" を区別するため、Settings の構造に沿って一致するキーを探す。
+func envKey(name string) (toml.Key, bool) {
+	if name == "" {
+		return nil, false
+	}
+	return matchEnvKey(reflect.TypeOf(Settings{}), strings.Split(strings.ToLower(name), "_"))
+}
+
+func matchEnvKey(typ reflect.Type, parts []string) (toml.Key, bool) {
+	if len(parts) == 0 {
+		switch typ.Kind() {
+		case reflect.Struct, reflect.Map:
+			return nil, false
+		default:
+			return toml.Key{}, true
+		}
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		// 長いキー名から順に試す（例: "output_dir" を "output" より優先する）
+		for i := len(parts); i > 0; i-- {
+			name := strings.Join(parts[:i], "_")
+			field, ok := fieldByTag(typ, name)
+			if !ok {
+				continue
+			}
+			if rest, ok := matchEnvKey(field.Type, parts[i:]); ok {
+				return append(toml.Key{name}, rest...), true
+			}
+		}
+	case reflect.Map:
+		// ビルトインツール名を優先し、それ以外は残りのキーが一致する最短の名前を使う
+		for i := len(parts); i > 0; i-- {
+			name := strings.Join(parts[:i], "_")
+			if _, ok := DefaultKnownToolFileNames[name]; !ok {
+				continue
+			}
+			if rest, ok := matchEnvKey(typ.Elem(), parts[i:]); ok {
+				return append(toml.Key{name}, rest...), true
+			}
+		}
+		for i := 1; i <= len(parts); i++ {
+			if rest, ok := matchEnvKey(typ.Elem(), parts[i:]); ok {
+				return append(toml.Key{strings.Join(parts[:i], "_")}, rest...), true
+			}
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,174 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvKey(t *testing.T) {
+	for name, expected := range map[string]toml.Key{
+		"APP_OUTPUT_DIR":                  {"app", "output_dir"},
+		"APP_DIRECTORY_MAPPING":           {"app", "directory_mapping"},
+		"TOOLS_CLAUDE_GENERATE":           {"tools", "claude", "generate"},
+		"TOOLS_GITHUB_COPILOT_DIR_NAME":   {"tools", "github_copilot", "dir_name"},
+		"TOOLS_MY_TOOL_FILE_NAME":         {"tools", "my_tool", "file_name"},
+		"PROFILES_CI_TOOLS_CLINE_EXCLUDE": {"profiles", "ci", "tools", "cline", "exclude"},
+	} {
+		key, ok := envKey(name)
+		require.True(t, ok, name)
+		assert.Equal(t, expected, key, name)
+	}
+
+	for _, name := range []string{"", "APP", "APP_UNKNOWN", "TOOLS_CLAUDE", "PROFILE"} {
+		_, ok := envKey(name)
+		assert.False(t, ok, name)
+	}
+}
+
+func TestParseOverrides(t *testing.T) {
+	t.Run("environment variables and --set", func(t *testing.T) {
+		overrides, unknownEnv, err := ParseOverrides(
+			[]string{"HOME=/root", "SPG_TOOLS_CLAUDE_GENERATE=false", "SPG_TOKEN=abc", "SPG_APP_OUTPUT_DIR=dist", "SPG_APP_OUTPT_DIR=dist"},
+			[]string{`tools.cline.exclude=["x.md", "y.md"]`, "tools.cline.include=a.md, b.md", "app.header=a=b", "tools.claude.max_tokens=8000"},
+		)
+		require.NoError(t, err)
+
+		assert.Equal(t, []Override{
+			{Key: toml.Key{"app", "output_dir"}, Value: "dist", Origin: "env SPG_APP_OUTPUT_DIR"},
+			{Key: toml.Key{"tools", "claude", "generate"}, Value: false, Origin: "env SPG_TOOLS_CLAUDE_GENERATE"},
+			{Key: toml.Key{"tools", "cline", "exclude"}, Value: []any{"x.md", "y.md"}, Origin: "--set"},
+			{Key: toml.Key{"tools", "cline", "include"}, Value: []any{"a.md", "b.md"}, Origin: "--set"},
+			{Key: toml.Key{"app", "header"}, Value: "a=b", Origin: "--set"},
+			{Key: toml.Key{"tools", "claude", "max_tokens"}, Value: int64(8000), Origin: "--set"},
+		}, overrides)
+		// 設定に一致しない環境変数はエラーにせず返す
+		assert.Equal(t, []string{"SPG_APP_OUTPT_DIR", "SPG_TOKEN"}, unknownEnv)
+	})
+
+	for name, test := range map[string]struct {
+		environ []string
+		sets    []string
+	}{
+		"invalid boolean": {environ: []string{"SPG_TOOLS_CLAUDE_GENERATE=maybe"}},
+		"invalid integer": {sets: []string{"tools.claude.max_tokens=many"}},
+		"missing value":   {sets: []string{"app.header"}},
+		"unknown key":     {sets: []string{"tool.claude.generate=true"}},
+		"table":           {sets: []string{"tools.claude=true"}},
+		"extends":         {sets: []string{"extends=base.toml"}},
+		"invalid array":   {sets: []string{`tools.claude.include=["a.md"`}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := ParseOverrides(test.environ, test.sets)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoadSettingsWithOverrides(t *testing.T) {
+	tempDir := t.TempDir()
+
+	settingsPath := writeSettingsFile(t, filepath.Join(tempDir, "settings.toml"), `[app]
+output_dir = "out"
+
+[tools.claude]
+generate = true
+
+[tools.cline]
+generate = true
+
+[profiles.ci.app]
+output_dir = "ci"
+`)
+
+	t.Run("precedence", func(t *testing.T) {
+		settings, err := LoadSettingsWithOptions(settingsPath, LoadOptions{
+			Profile:     "ci",
+			Environ:     []string{"SPG_APP_OUTPUT_DIR=env", "SPG_TOOLS_CLAUDE_GENERATE=false"},
+			Set:         []string{"app.output_dir=set", `tools.cline.exclude=["x.md"]`},
+			ProjectRoot: tempDir,
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, filepath.Join(tempDir, "set"), settings.App.OutputDir)
+		assert.NotContains(t, settings.Tools, "claude")
+		assert.Equal(t, []string{"x.md"}, settings.Tools["cline"].Exclude)
+	})
+
+	t.Run("environment variables override the profile", func(t *testing.T) {
+		settings, err := LoadSettingsWithOptions(settingsPath, LoadOptions{
+			Profile:     "ci",
+			Environ:     []string{"SPG_APP_OUTPUT_DIR=env"},
+			ProjectRoot: tempDir,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(tempDir, "env"), settings.App.OutputDir)
+	})
+
+	t.Run("relative paths are resolved from the project root", func(t *testing.T) {
+		// サブディレクトリで実行しても、カレントディレクトリではなくプロジェクトルートから解決する
+		subDir := filepath.Join(tempDir, "sub")
+		require.NoError(t, os.MkdirAll(subDir, 0755))
+		t.Chdir(subDir)
+
+		absolute := filepath.Join(t.TempDir(), "abs")
+		settings, err := LoadSettingsWithOptions(settingsPath, LoadOptions{
+			Environ:     []string{"SPG_APP_OUTPUT_DIR=out"},
+			Set:         []string{"app.input_dirs=prompts," + absolute, "tools.cline.dir_name=rules"},
+			ProjectRoot: tempDir,
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, filepath.Join(tempDir, "out"), settings.App.OutputDir)
+		assert.Equal(t, []string{filepath.Join(tempDir, "prompts"), absolute}, settings.App.InputDirs)
+		// dir_name は output_dir からの相対パスのまま
+		assert.Equal(t, DirName("rules"), settings.Tools["cline"].DirName)
+
+		// ProjectRoot を指定しない場合はカレントディレクトリから解決する
+		settings, err = LoadSettingsWithOptions(settingsPath, LoadOptions{Environ: []string{"SPG_APP_OUTPUT_DIR=out"}})
+		require.NoError(t, err)
+		currentDir, err := os.Getwd()
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(currentDir, "out"), settings.App.OutputDir)
+	})
+
+	t.Run("without settings file", func(t *testing.T) {
+		settings, err := LoadSettingsWithOptions(filepath.Join(tempDir, "missing", "settings.toml"), LoadOptions{
+			Environ: []string{"SPG_TOOLS_CLINE_GENERATE=false"},
+		})
+		require.NoError(t, err)
+
+		assert.Contains(t, settings.Tools, "claude")
+		assert.NotContains(t, settings.Tools, "cline")
+		assert.NotEmpty(t, settings.App.InputDir)
+	})
+
+	t.Run("unknown environment variables", func(t *testing.T) {
+		options := LoadOptions{Environ: []string{"SPG_TOKEN=abc"}}
+		settings, err := LoadSettingsWithOptions(settingsPath, options)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"SPG_TOKEN"}, settings.UnknownEnv)
+
+		options.Strict = true
+		_, err = LoadSettingsWithOptions(settingsPath, options)
+		var unknownEnvErr *UnknownEnvError
+		require.ErrorAs(t, err, &unknownEnvErr)
+		assert.Equal(t, []string{"SPG_TOKEN"}, unknownEnvErr.Names)
+	})
+
+	t.Run("origins", func(t *testing.T) {
+		resolved, err := ResolveSettings(settingsPath)
+		require.NoError(t, err)
+
+		overrides, _, err := ParseOverrides([]string{"SPG_APP_OUTPUT_DIR=env"}, []string{"tools.claude.exclude=x.md"})
+		require.NoError(t, err)
+		resolved.ApplyOverrides(overrides)
+
+		assert.Equal(t, []string{"env SPG_APP_OUTPUT_DIR"}, resolved.Origins["app.output_dir"])
+		assert.Equal(t, []string{"--set"}, resolved.Origins["tools.claude.exclude"])
+	})
+}
@@ -0,0 +1,167 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// appPathKeys は設定ファイルのディレクトリからの相対パスとして解決する [app] のキー。
+// その他のキーはファイルシステムのパスとして解決しない。[tools.*] の dir_name と file_name は output_dir からの
+// 相対パス、include と exclude はプロンプトファイル名のパターン、header と footer はファイルではなく内容そのもの。
+var appPathKeys = []string{"input_dir", "input_dirs", "output_dir"}
+
+// isAppPathKey は key が [app] または [profiles.*.app] のパスのキーかを返す
+func isAppPathKey(key toml.Key) bool {
+	switch {
+	case len(key) == 2 && key[0] == "app":
+	case len(key) == 4 && key[0] == "profiles" && key[2] == "app":
+	default:
+		return false
+	}
+	return slices.Contains(appPathKeys, key[len(key)-1])
+}
+
+// resolveOverridePaths は環境変数と --set で指定されたパスのうち、相対パスを baseDir からのパスに書き換える。
+// baseDir は入出力ディレクトリのデフォルト値と同じくプロジェクトルートとし、サブディレクトリで実行しても同じパスになる
+func resolveOverridePaths(overrides []Override, baseDir string) {
+	for i, override := range overrides {
+		if !isAppPathKey(override.Key) {
+			continue
+		}
+		switch v := override.Value.(type) {
+		case string:
+			overrides[i].Value = resolveRelativePath(baseDir, v)
+		case []any:
+			for j, item := range v {
+				if path, ok := item.(string); ok {
+					v[j] = resolveRelativePath(baseDir, path)
+				}
+			}
+		}
+	}
+}
+
+// RelativePath は設定ファイルに相対パスで記述され、解決されたパス
+type RelativePath struct {
+	// File はパスが記述された設定ファイル
+	File string
+	// Key はキーのパス（配列の場合は "app.input_dirs[1]" のように添字を含む）
+	Key string
+	// Value は設定ファイルに記述された値
+	Value string
+	// Resolved は解決したパス（version 2 以降は設定ファイルのディレクトリから、version 1 はプロジェクトルートから）
+	Resolved string
+	// Version はパスが記述された設定ファイルの version
+	Version int
+}
+
+// PathMigration は version 1 の設定ファイルの相対パスのうち、version 2 の規則
+// （設定ファイルのディレクトリからの解決）では別のパスを指すもの
+type PathMigration struct {
+	RelativePath
+	// NewPath は version 2 の規則で解決した場合のパス
+	NewPath string
+}
+
+// resolvePathValues は values の [app] と [profiles.*.app] にあるパスのうち、相対パスを
+// baseDir からのパスに書き換え、書き換えたパスを返す
+func resolvePathValues(values map[string]any, baseDir string, file string) []RelativePath {
+	relativePaths := resolveAppPaths(values["app"], "app", baseDir, file)
+
+	profiles, _ := values["profiles"].(map[string]any)
+	var names []string
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		profile, ok := profiles[name].(map[string]any)
+		if !ok {
+			continue
+		}
+		prefix := joinKey(joinKey("profiles", quoteKey(name)), "app")
+		relativePaths = append(relativePaths, resolveAppPaths(profile["app"], prefix, baseDir, file)...)
+	}
+
+	return relativePaths
+}
+
+func resolveAppPaths(value any, prefix string, baseDir string, file string) []RelativePath {
+	app, ok := value.(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	var relativePaths []RelativePath
+	resolve := func(key string, path string) string {
+		resolved := resolveRelativePath(baseDir, path)
+		if resolved != path {
+			relativePaths = append(relativePaths, RelativePath{
+				File:     file,
+				Key:      key,
+				Value:    path,
+				Resolved: resolved,
+			})
+		}
+		return resolved
+	}
+
+	for _, name := range appPathKeys {
+		key := joinKey(prefix, name)
+		switch v := app[name].(type) {
+		case string:
+			app[name] = resolve(key, v)
+		case []any:
+			for i, item := range v {
+				if path, ok := item.(string); ok {
+					v[i] = resolve(fmt.Sprintf("%s[%d]", key, i), path)
+				}
+			}
+		}
+	}
+
+	return relativePaths
+}
+
+// resolveRelativePath は相対パスを baseDir からのパスに変換する。
+// 空文字列、絶対パス、"~" から始まるパスはそのまま返す。
+func resolveRelativePath(baseDir, path string) string {
+	if path == "" || filepath.IsAbs(path) || path == "~" || strings.HasPrefix(path, "~/") {
+		return path
+	}
+	return filepath.Join(baseDir, path)
+}
+
+// findPathMigrations は version 1 の設定ファイルの相対パスのうち、設定ファイルのディレクトリから解決すると
+// 現在（プロジェクトルートから解決）とは別のパスを指すものを返す。
+// ディレクトリが存在するかどうかにかかわらず、解決結果が変わる全てのパスを報告する。
+func findPathMigrations(relativePaths []RelativePath) []PathMigration {
+	var migrations []PathMigration
+	for _, relativePath := range relativePaths {
+		// version 2 以降は設定ファイルのディレクトリから解決されている
+		if relativePath.Version >= 2 {
+			continue
+		}
+
+		absFile, err := filepath.Abs(relativePath.File)
+		if err != nil {
+			continue
+		}
+		newPath := filepath.Join(filepath.Dir(absFile), relativePath.Value)
+		if newPath == relativePath.Resolved {
+			continue
+		}
+
+		migrations = append(migrations, PathMigration{
+			RelativePath: relativePath,
+			NewPath:      newPath,
+		})
+	}
+	return migrations
+}
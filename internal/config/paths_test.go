@@ -0,0 +1,106 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolvePathsRelativeToSettingsFile(t *testing.T) {
+	tempDir := t.TempDir()
+
+	writeSettingsFile(t, filepath.Join(tempDir, "shared", "base.toml"), `version = 2
+
+[app]
+input_dirs = ["prompts"]
+`)
+	settingsPath := writeSettingsFile(t, filepath.Join(tempDir, "project", ".system_prompt", "settings.toml"), `version = 2
+extends = ["../../shared/base.toml"]
+array_merge = "append"
+
+[app]
+input_dirs = ["."]
+output_dir = ".."
+
+[profiles.ci.app]
+output_dir = "../dist"
+`)
+
+	settings, err := LoadSettingsWithOptions(settingsPath, LoadOptions{Profile: "ci"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		filepath.Join(tempDir, "shared", "prompts"),
+		filepath.Join(tempDir, "project", ".system_prompt"),
+	}, settings.App.InputDirs)
+	assert.Equal(t, filepath.Join(tempDir, "project", "dist"), settings.App.OutputDir)
+
+	resolved, err := ResolveSettings(settingsPath)
+	require.NoError(t, err)
+
+	var keys []string
+	for _, relativePath := range resolved.RelativePaths {
+		keys = append(keys, relativePath.Key)
+	}
+	assert.Equal(t, []string{"app.input_dirs[0]", "app.input_dirs[0]", "app.output_dir", "profiles.ci.app.output_dir"}, keys)
+}
+
+func TestFindPathMigrations(t *testing.T) {
+	projectRoot := t.TempDir()
+	settingsDir := filepath.Join(projectRoot, ".system_prompt")
+
+	settingsPath := writeSettingsFile(t, filepath.Join(settingsDir, "settings.toml"), `[app]
+output_dir = "."
+input_dirs = ["/abs/prompts", "~/prompts", "missing"]
+`)
+
+	settings, err := LoadSettingsWithOptions(settingsPath, LoadOptions{ProjectRoot: projectRoot})
+	require.NoError(t, err)
+
+	// version のない設定ファイルはプロジェクトルートから解決する
+	assert.Equal(t, projectRoot, settings.App.OutputDir)
+	assert.Equal(t, "/abs/prompts", settings.App.InputDirs[0])
+	assert.Equal(t, filepath.Join(projectRoot, "missing"), settings.App.InputDirs[2])
+
+	// ディレクトリが存在しなくても、version 2 で解決結果が変わる相対パスは全て報告する
+	require.Len(t, settings.PathMigrations, 2)
+	assert.Equal(t, "app.input_dirs[2]", settings.PathMigrations[0].Key)
+	assert.Equal(t, filepath.Join(projectRoot, "missing"), settings.PathMigrations[0].Resolved)
+	assert.Equal(t, filepath.Join(settingsDir, "missing"), settings.PathMigrations[0].NewPath)
+
+	migration := settings.PathMigrations[1]
+	assert.Equal(t, "app.output_dir", migration.Key)
+	assert.Equal(t, ".", migration.Value)
+	assert.Equal(t, projectRoot, migration.Resolved)
+	assert.Equal(t, settingsDir, migration.NewPath)
+}
+
+func TestVersion2ResolvesPathsRelativeToSettingsFile(t *testing.T) {
+	projectRoot := t.TempDir()
+	settingsDir := filepath.Join(projectRoot, ".system_prompt")
+
+	settingsPath := writeSettingsFile(t, filepath.Join(settingsDir, "settings.toml"), `version = 2
+
+[app]
+output_dir = ".."
+input_dirs = ["../shared", "."]
+
+[tools.github_copilot]
+generate = true
+dir_name = ".github"
+include = ["docs/*.md"]
+`)
+
+	settings, err := LoadSettingsWithOptions(settingsPath, LoadOptions{ProjectRoot: projectRoot})
+	require.NoError(t, err)
+
+	assert.Equal(t, projectRoot, settings.App.OutputDir)
+	assert.Equal(t, []string{filepath.Join(projectRoot, "shared"), settingsDir}, settings.App.InputDirs)
+	assert.Empty(t, settings.PathMigrations)
+
+	// dir_name は output_dir から、include はプロンプトファイル名のパターンのため書き換えない
+	assert.Equal(t, DirName(".github"), settings.Tools["github_copilot"].DirName)
+	assert.Equal(t, []string{"docs/*.md"}, settings.Tools["github_copilot"].Include)
+}
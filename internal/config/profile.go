@@ -0,0 +1,54 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ProfileSettings は [profiles.NAME] で定義され、選択時に [app] と [tools.*] を上書きする設定
+type ProfileSettings struct {
+	App   AppSettings               `toml:"app"`
+	Tools map[string]AIToolSettings `toml:"tools"`
+}
+
+// ProfileNames は定義されているプロファイル名をソートして返す
+func (s *Settings) ProfileNames() []string {
+	var names []string
+	for name := range s.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ApplyProfile は [profiles.NAME] の app/tools を設定値にマージする。
+// 配列はプロファイルの値で置き換えられる。
+func (r *ResolvedSettings) ApplyProfile(name string) error {
+	profiles, _ := r.Values["profiles"].(map[string]any)
+	profile, ok := profiles[name].(map[string]any)
+	if !ok {
+		return fmt.Errorf("profile %q is not defined", name)
+	}
+
+	prefix := joinKey("profiles", name) + "."
+
+	for _, section := range []string{"app", "tools"} {
+		values, ok := profile[section].(map[string]any)
+		if !ok {
+			continue
+		}
+
+		// プロファイル内のキーのパスを通常のキーのパスに読み替える
+		origins := make(map[string][]string)
+		for key, origin := range r.Origins {
+			if strings.HasPrefix(key, prefix+section+".") {
+				origins[strings.TrimPrefix(key, prefix)] = origin
+			}
+		}
+
+		mergeValues(r.Values, r.Origins, map[string]any{section: values}, origins, ArrayMergeReplace, "")
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const profileSettingsContent = `[app]
+header = "header"
+
+[tools.claude]
+generate = true
+
+[tools.cline]
+generate = true
+
+[profiles.public.app]
+output_dir = "dist/public"
+
+[profiles.public.tools.claude]
+exclude = ["internal_*.md"]
+
+[profiles.public.tools.cline]
+generate = false
+
+[profiles.minimal.app]
+header = "minimal header"
+`
+
+func TestLoadSettingsWithOptionsProfile(t *testing.T) {
+	settingsPath := writeSettingsFile(t, filepath.Join(t.TempDir(), "settings.toml"), profileSettingsContent)
+
+	t.Run("without profile", func(t *testing.T) {
+		settings, err := LoadSettingsWithOptions(settingsPath, LoadOptions{})
+		require.NoError(t, err)
+
+		assert.Empty(t, settings.Profile)
+		assert.Equal(t, "header", settings.App.Header)
+		assert.Empty(t, settings.Tools["claude"].Exclude)
+		assert.Contains(t, settings.Tools, "cline")
+		assert.Equal(t, []string{"minimal", "public"}, settings.ProfileNames())
+	})
+
+	t.Run("public profile", func(t *testing.T) {
+		settings, err := LoadSettingsWithOptions(settingsPath, LoadOptions{Profile: "public"})
+		require.NoError(t, err)
+
+		assert.Equal(t, "public", settings.Profile)
+		assert.Equal(t, "header", settings.App.Header)
+		currentDir, err := os.Getwd()
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(currentDir, "dist", "public"), settings.App.OutputDir)
+		assert.Equal(t, []string{"internal_*.md"}, settings.Tools["claude"].Exclude)
+		assert.Equal(t, FileName("CLAUDE.md"), settings.Tools["claude"].FileName)
+		assert.NotContains(t, settings.Tools, "cline")
+	})
+
+	t.Run("minimal profile", func(t *testing.T) {
+		settings, err := LoadSettingsWithOptions(settingsPath, LoadOptions{Profile: "minimal"})
+		require.NoError(t, err)
+
+		assert.Equal(t, "minimal header", settings.App.Header)
+		assert.Contains(t, settings.Tools, "cline")
+	})
+
+	t.Run("undefined profile", func(t *testing.T) {
+		_, err := LoadSettingsWithOptions(settingsPath, LoadOptions{Profile: "unknown"})
+		assert.Error(t, err)
+	})
+
+	t.Run("profile without settings file", func(t *testing.T) {
+		_, err := LoadSettingsWithOptions(filepath.Join(t.TempDir(), "settings.toml"), LoadOptions{Profile: "public"})
+		assert.Error(t, err)
+	})
+}
+
+func TestApplyProfileOrigins(t *testing.T) {
+	settingsPath := writeSettingsFile(t, filepath.Join(t.TempDir(), "settings.toml"), profileSettingsContent)
+
+	resolved, err := ResolveSettings(settingsPath)
+	require.NoError(t, err)
+
+	require.NoError(t, resolved.ApplyProfile("public"))
+
+	assert.Equal(t, []string{settingsPath}, resolved.Origins["app.output_dir"])
+	assert.Equal(t, []string{settingsPath}, resolved.Origins["tools.claude.exclude"])
+}
@@ -0,0 +1,211 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// schemaDescriptions は JSON Schema に出力する各フィールドの説明（"型名.キー" 形式）
+var schemaDescriptions = map[string]string{
+	"Settings.version":     "Settings format version. Files without a version are treated as version 1. Update with the migrate command.",
+	"Settings.extends":     "Settings files to inherit from, relative to this file. Later entries take priority.",
+	"Settings.array_merge": "How arrays such as include/exclude are combined with inherited settings.",
+	"Settings.app":         "Application settings.",
+	"Settings.tools":       "AI tools to generate prompt files for, keyed by tool name.",
+	"Settings.profiles":    "Named profiles selected with --profile. Each profile overrides [app] and [tools.*].",
+
+	"AppSettings.header":            "Content written at the top of every generated file.",
+	"AppSettings.footer":            "Content written at the bottom of every generated file.",
+	"AppSettings.input_dir":         "Directory containing the prompt files, relative to this settings file (the project root if version is not 2). Defaults to .system_prompt in the project root.",
+	"AppSettings.output_dir":        "Directory the prompt files are generated into, relative to this settings file (the project root if version is not 2). Defaults to the project root.",
+	"AppSettings.input_dirs":        "Input directories (layers) from lowest to highest priority, relative to this settings file (the project root if version is not 2). Replaces input_dir when set.",
+	"AppSettings.layer_policy":      "What to do when the same relative path exists in several input directories.",
+	"AppSettings.directory_mapping": "Generate separate outputs for each subdirectory of the input directory at the same relative path.",
+	"AppSettings.inherit_parent":    "With directory_mapping, also include prompt files from parent directories.",
+	"AppSettings.tokenizer":         "Tokenizer used to estimate token counts: bpe (bundled BPE approximation) or chars (4 characters per token).",
+	"AppSettings.budget_policy":     "What to do when a generated file exceeds max_tokens or max_bytes: warn, or fail generation with error.",
+
+	"AIToolSettings.generate":   "Whether to generate the file for this tool.",
+	"AIToolSettings.include":    "Glob patterns of prompt files to include, relative to the input directory. All files are included when undefined.",
+	"AIToolSettings.exclude":    "Glob patterns of prompt files to exclude. Exclude takes priority over include.",
+	"AIToolSettings.max_tokens": "Maximum estimated tokens of the generated file. 0 means no limit.",
+	"AIToolSettings.max_bytes":  "Maximum size of the generated file in bytes. 0 means no limit.",
+
+	"AIToolPaths.dir_name":  "Directory of the generated file, relative to the output directory.",
+	"AIToolPaths.file_name": "File name of the generated file. Required for custom tools.",
+
+	"ProfileSettings.app":   "Application settings overridden by this profile.",
+	"ProfileSettings.tools": "Tool settings overridden by this profile.",
+}
+
+// schemaEnums は列挙型の値と、デフォルト値
+var schemaEnums = map[reflect.Type]struct {
+	values       []string
+	defaultValue string
+}{
+	reflect.TypeOf(LayerPolicy("")): {
+		values:       []string{string(LayerPolicyOverride), string(LayerPolicyKeep), string(LayerPolicyAppend), string(LayerPolicyError)},
+		defaultValue: string(LayerPolicyOverride),
+	},
+	reflect.TypeOf(ArrayMergeMode("")): {
+		values:       []string{string(ArrayMergeReplace), string(ArrayMergeAppend)},
+		defaultValue: string(ArrayMergeReplace),
+	},
+	reflect.TypeOf(BudgetPolicy("")): {
+		values:       []string{string(BudgetPolicyWarn), string(BudgetPolicyError)},
+		defaultValue: string(BudgetPolicyWarn),
+	},
+}
+
+// schemaDefaults は列挙型以外のフィールドのデフォルト値（"型名.キー" 形式）
+var schemaDefaults = map[string]any{
+	"AppSettings.header":            "",
+	"AppSettings.footer":            "",
+	"AppSettings.directory_mapping": false,
+	"AppSettings.inherit_parent":    false,
+	"AppSettings.tokenizer":         "bpe",
+	"AIToolSettings.generate":       false,
+	"AIToolSettings.max_tokens":     0,
+	"AIToolSettings.max_bytes":      0,
+}
+
+// JSONSchema は settings.toml の JSON Schema を返す
+func JSONSchema() ([]byte, error) {
+	toolSchema := structSchema(reflect.TypeOf(AIToolSettings{}))
+	customToolSchema := cloneSchema(toolSchema)
+	customToolSchema["required"] = []string{"file_name"}
+
+	// ビルトインツールは file_name/dir_name が省略可能で、デフォルト値を持つ
+	var knownToolNames []string
+	for name := range DefaultKnownToolFileNames {
+		knownToolNames = append(knownToolNames, name)
+	}
+	sort.Strings(knownToolNames)
+
+	knownTools := make(map[string]any)
+	for _, name := range knownToolNames {
+		paths := DefaultKnownToolFileNames[name]
+
+		knownToolSchema := cloneSchema(toolSchema)
+		properties := knownToolSchema["properties"].(map[string]any)
+		properties["dir_name"].(map[string]any)["default"] = string(paths.DirName)
+		properties["file_name"].(map[string]any)["default"] = string(paths.FileName)
+		knownToolSchema["description"] = fmt.Sprintf("Built-in tool %q. Generates %s by default.", name, defaultToolPath(paths))
+
+		knownTools[name] = knownToolSchema
+	}
+
+	toolsSchema := map[string]any{
+		"type":                 "object",
+		"properties":           knownTools,
+		"additionalProperties": customToolSchema,
+	}
+
+	schema := structSchema(reflect.TypeOf(Settings{}))
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["title"] = "system-prompt-gen settings"
+
+	properties := schema["properties"].(map[string]any)
+	properties["tools"] = withDescription(toolsSchema, "Settings.tools")
+
+	profileSchema := properties["profiles"].(map[string]any)["additionalProperties"].(map[string]any)
+	profileSchema["properties"].(map[string]any)["tools"] = withDescription(cloneSchema(toolsSchema), "ProfileSettings.tools")
+
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// structSchema は構造体の toml タグから JSON Schema を生成する
+func structSchema(typ reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	addStructProperties(properties, typ)
+
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+func addStructProperties(properties map[string]any, typ reflect.Type) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.Anonymous {
+			addStructProperties(properties, field.Type)
+			continue
+		}
+
+		tag := field.Tag.Get("toml")
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		key := typ.Name() + "." + tag
+		property := typeSchema(field.Type)
+		if defaultValue, ok := schemaDefaults[key]; ok {
+			property["default"] = defaultValue
+		}
+		properties[tag] = withDescription(property, key)
+	}
+}
+
+func typeSchema(typ reflect.Type) map[string]any {
+	if enum, ok := schemaEnums[typ]; ok {
+		return map[string]any{
+			"type":    "string",
+			"enum":    enum.values,
+			"default": enum.defaultValue,
+		}
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int:
+		return map[string]any{"type": "integer"}
+	case reflect.Slice:
+		return map[string]any{
+			"type":  "array",
+			"items": typeSchema(typ.Elem()),
+		}
+	case reflect.Map:
+		return map[string]any{
+			"type":                 "object",
+			"additionalProperties": typeSchema(typ.Elem()),
+		}
+	case reflect.Struct:
+		return structSchema(typ)
+	default:
+		panic(fmt.Sprintf("unsupported settings type %s", typ))
+	}
+}
+
+func withDescription(schema map[string]any, key string) map[string]any {
+	if description, ok := schemaDescriptions[key]; ok {
+		schema["description"] = description
+	}
+	return schema
+}
+
+// cloneSchema は JSON Schema を再帰的に複製する
+func cloneSchema(schema map[string]any) map[string]any {
+	cloned := make(map[string]any, len(schema))
+	for key, value := range schema {
+		if child, ok := value.(map[string]any); ok {
+			cloned[key] = cloneSchema(child)
+		} else {
+			cloned[key] = value
+		}
+	}
+	return cloned
+}
+
+func defaultToolPath(paths AIToolPaths) string {
+	if paths.DirName == "" {
+		return string(paths.FileName)
+	}
+	return string(paths.DirName) + "/" + string(paths.FileName)
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// schemaTypes は settings.toml を構成する構造体
+var schemaTypes = []reflect.Type{
+	reflect.TypeOf(Settings{}),
+	reflect.TypeOf(AppSettings{}),
+	reflect.TypeOf(AIToolSettings{}),
+	reflect.TypeOf(AIToolPaths{}),
+	reflect.TypeOf(ProfileSettings{}),
+}
+
+func TestSchemaDescriptionsInSync(t *testing.T) {
+	fields := make(map[string]bool)
+	for _, typ := range schemaTypes {
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			tag := field.Tag.Get("toml")
+			if field.Anonymous || tag == "" || tag == "-" {
+				continue
+			}
+			fields[typ.Name()+"."+tag] = true
+		}
+	}
+
+	for field := range fields {
+		assert.Contains(t, schemaDescriptions, field, "field %s has no schema description", field)
+	}
+	for key := range schemaDescriptions {
+		assert.True(t, fields[key], "schema description %s does not match any field", key)
+	}
+	for key := range schemaDefaults {
+		assert.True(t, fields[key], "schema default %s does not match any field", key)
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	data, err := JSONSchema()
+	require.NoError(t, err)
+
+	var schema map[string]any
+	require.NoError(t, json.Unmarshal(data, &schema))
+
+	properties := schema["properties"].(map[string]any)
+	for _, key := range []string{"extends", "array_merge", "app", "tools", "profiles"} {
+		assert.Contains(t, properties, key)
+	}
+
+	appProperties := properties["app"].(map[string]any)["properties"].(map[string]any)
+	assert.Equal(t, "override", appProperties["layer_policy"].(map[string]any)["default"])
+
+	tools := properties["tools"].(map[string]any)
+	knownTools := tools["properties"].(map[string]any)
+	for name, paths := range DefaultKnownToolFileNames {
+		require.Contains(t, knownTools, name)
+		toolProperties := knownTools[name].(map[string]any)["properties"].(map[string]any)
+		assert.Equal(t, string(paths.FileName), toolProperties["file_name"].(map[string]any)["default"])
+	}
+
+	customTool := tools["additionalProperties"].(map[string]any)
+	assert.Equal(t, []any{"file_name"}, customTool["required"])
+}
+
+func TestJSONSchemaFileUpToDate(t *testing.T) {
+	data, err := JSONSchema()
+	require.NoError(t, err)
+
+	committed, err := os.ReadFile("../../settings.schema.json")
+	require.NoError(t, err)
+
+	assert.Equal(t, strings.TrimSpace(string(data)), strings.TrimSpace(string(committed)),
+		"settings.schema.json is out of date, run `make schema`")
+}
@@ -0,0 +1,198 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cateiru/system-prompt-gen/internal/i18n"
+	"github.com/cateiru/system-prompt-gen/internal/util"
+)
+
+// Severity は検証結果の重大度
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// FindingCode は検証結果の種類を識別するコード
+type FindingCode string
+
+const (
+	// CodeAbsolutePath は dir_name/file_name に絶対パスが指定されている
+	CodeAbsolutePath FindingCode = "SPG001"
+	// CodePathEscapesOutput は dir_name/file_name が出力ディレクトリの外を指している
+	CodePathEscapesOutput FindingCode = "SPG002"
+	// CodeDuplicateOutput は複数のツールが同じファイルに出力する
+	CodeDuplicateOutput FindingCode = "SPG003"
+	// CodeOutputInsideInput は出力先が入力ディレクトリの中にある
+	CodeOutputInsideInput FindingCode = "SPG004"
+	// CodeInvalidPattern は include/exclude のパターンが不正
+	CodeInvalidPattern FindingCode = "SPG005"
+)
+
+// Finding は設定の検証で見つかった問題
+type Finding struct {
+	Code     FindingCode
+	Severity Severity
+	// MessageID はメッセージの i18n ID
+	MessageID string
+	// Data はメッセージのテンプレートデータ
+	Data map[string]any
+}
+
+// Message はローカライズされたメッセージを返す
+func (f Finding) Message() string {
+	return i18n.T(f.MessageID, f.Data)
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("[%s] %s", f.Code, f.Message())
+}
+
+// HasErrors は findings にエラーが含まれるかを返す
+func HasErrors(findings []Finding) bool {
+	for _, finding := range findings {
+		if finding.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate はツールの出力先と include/exclude パターンを検証する
+func (s *Settings) Validate() []Finding {
+	var findings []Finding
+
+	var toolNames []string
+	for name := range s.Tools {
+		toolNames = append(toolNames, name)
+	}
+	sort.Strings(toolNames)
+
+	outputs := make(map[string][]string)
+
+	for _, name := range toolNames {
+		tool := s.Tools[name]
+
+		pathFindings := validateToolPaths(name, tool)
+		findings = append(findings, pathFindings...)
+
+		for _, field := range []struct {
+			key      string
+			patterns []string
+		}{
+			{key: "include", patterns: tool.Include},
+			{key: "exclude", patterns: tool.Exclude},
+		} {
+			for _, pattern := range field.patterns {
+				if _, err := filepath.Match(pattern, ""); err != nil {
+					findings = append(findings, newFinding(CodeInvalidPattern, SeverityError, "finding_invalid_pattern", map[string]any{
+						"ToolName": name,
+						"Key":      field.key,
+						"Pattern":  pattern,
+						"Error":    err,
+					}))
+				}
+			}
+		}
+
+		// パスに問題がある場合は出力先の比較を行わない
+		if len(pathFindings) > 0 {
+			continue
+		}
+
+		outputPath := filepath.Join(s.App.OutputDir, string(tool.DirName), string(tool.FileName))
+		outputs[outputPath] = append(outputs[outputPath], name)
+
+		for _, layer := range s.App.Layers() {
+			if layer != "" && isWithin(util.ExpandHome(layer), outputPath) {
+				findings = append(findings, newFinding(CodeOutputInsideInput, SeverityError, "finding_output_inside_input", map[string]any{
+					"ToolName": name,
+					"Path":     outputPath,
+					"InputDir": layer,
+				}))
+			}
+		}
+	}
+
+	var outputPaths []string
+	for outputPath := range outputs {
+		outputPaths = append(outputPaths, outputPath)
+	}
+	sort.Strings(outputPaths)
+
+	for _, outputPath := range outputPaths {
+		if names := outputs[outputPath]; len(names) > 1 {
+			findings = append(findings, newFinding(CodeDuplicateOutput, SeverityError, "finding_duplicate_output", map[string]any{
+				"ToolNames": strings.Join(names, ", "),
+				"Path":      outputPath,
+			}))
+		}
+	}
+
+	return findings
+}
+
+func validateToolPaths(name string, tool AIToolSettings) []Finding {
+	var findings []Finding
+
+	for _, field := range []struct {
+		key   string
+		value string
+	}{
+		{key: "dir_name", value: string(tool.DirName)},
+		{key: "file_name", value: string(tool.FileName)},
+	} {
+		if filepath.IsAbs(field.value) {
+			findings = append(findings, newFinding(CodeAbsolutePath, SeverityError, "finding_absolute_path", map[string]any{
+				"ToolName": name,
+				"Key":      field.key,
+				"Value":    field.value,
+			}))
+		}
+	}
+	if len(findings) > 0 {
+		return findings
+	}
+
+	relPath := filepath.Clean(filepath.Join(string(tool.DirName), string(tool.FileName)))
+	if relPath == "." || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		findings = append(findings, newFinding(CodePathEscapesOutput, SeverityError, "finding_path_escapes_output", map[string]any{
+			"ToolName": name,
+			"Path":     filepath.Join(string(tool.DirName), string(tool.FileName)),
+		}))
+	}
+
+	return findings
+}
+
+// isWithin は path が dir 以下にあるかを返す
+func isWithin(dir, path string) bool {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+
+	relPath, err := filepath.Rel(absDir, absPath)
+	if err != nil {
+		return false
+	}
+	return relPath != ".." && !strings.HasPrefix(relPath, ".."+string(filepath.Separator))
+}
+
+func newFinding(code FindingCode, severity Severity, messageID string, data map[string]any) Finding {
+	return Finding{
+		Code:      code,
+		Severity:  severity,
+		MessageID: messageID,
+		Data:      data,
+	}
+}
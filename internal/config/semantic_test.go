@@ -0,0 +1,100 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cateiru/system-prompt-gen/internal/i18n"
+)
+
+func TestValidate(t *testing.T) {
+	i18n.TestSetupI18n(t)
+
+	tempDir := t.TempDir()
+
+	tests := []struct {
+		name          string
+		tools         map[string]AIToolSettings
+		expectedCodes []FindingCode
+	}{
+		{
+			name: "valid settings",
+			tools: map[string]AIToolSettings{
+				"claude":         {Generate: true, AIToolPaths: AIToolPaths{FileName: "CLAUDE.md"}},
+				"github_copilot": {Generate: true, Include: []string{"01-*.md"}, AIToolPaths: AIToolPaths{DirName: ".github", FileName: "copilot-instructions.md"}},
+			},
+		},
+		{
+			name: "absolute dir_name",
+			tools: map[string]AIToolSettings{
+				"mytool": {Generate: true, AIToolPaths: AIToolPaths{DirName: "/etc", FileName: "mytool.md"}},
+			},
+			expectedCodes: []FindingCode{CodeAbsolutePath},
+		},
+		{
+			name: "dir_name escapes the output directory",
+			tools: map[string]AIToolSettings{
+				"mytool": {Generate: true, AIToolPaths: AIToolPaths{DirName: "../../etc", FileName: "mytool.md"}},
+			},
+			expectedCodes: []FindingCode{CodePathEscapesOutput},
+		},
+		{
+			name: "two tools write the same file",
+			tools: map[string]AIToolSettings{
+				"claude": {Generate: true, AIToolPaths: AIToolPaths{FileName: "AGENTS.md"}},
+				"agents": {Generate: true, AIToolPaths: AIToolPaths{DirName: "./", FileName: "AGENTS.md"}},
+			},
+			expectedCodes: []FindingCode{CodeDuplicateOutput},
+		},
+		{
+			name: "output inside the input directory",
+			tools: map[string]AIToolSettings{
+				"mytool": {Generate: true, AIToolPaths: AIToolPaths{DirName: ".system_prompt", FileName: "generated.md"}},
+			},
+			expectedCodes: []FindingCode{CodeOutputInsideInput},
+		},
+		{
+			name: "malformed patterns",
+			tools: map[string]AIToolSettings{
+				"claude": {Generate: true, Include: []string{"[01-*.md"}, Exclude: []string{"temp*.md", "draft[.md"}, AIToolPaths: AIToolPaths{FileName: "CLAUDE.md"}},
+			},
+			expectedCodes: []FindingCode{CodeInvalidPattern, CodeInvalidPattern},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := &Settings{
+				App: AppSettings{
+					InputDir:  filepath.Join(tempDir, ".system_prompt"),
+					OutputDir: tempDir,
+				},
+				Tools: tt.tools,
+			}
+
+			findings := settings.Validate()
+
+			var codes []FindingCode
+			for _, finding := range findings {
+				codes = append(codes, finding.Code)
+				assert.NotEqual(t, finding.MessageID, finding.Message(), "message %s should be localized", finding.MessageID)
+			}
+			assert.Equal(t, tt.expectedCodes, codes)
+			assert.Equal(t, len(tt.expectedCodes) > 0, HasErrors(findings))
+		})
+	}
+}
+
+func TestFindingString(t *testing.T) {
+	i18n.TestSetupI18n(t)
+
+	finding := newFinding(CodeDuplicateOutput, SeverityError, "finding_duplicate_output", map[string]any{
+		"ToolNames": "agents, claude",
+		"Path":      "AGENTS.md",
+	})
+
+	require.Equal(t, "[SPG003] agents, claude write to the same file AGENTS.md", finding.String())
+}
@@ -0,0 +1,301 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+
+	"github.com/BurntSushi/toml"
+)
+
+// toolNamePattern はカスタムツール名として使用できる名前（SPG_TOOLS_<NAME>_* で上書きできる形式）
+var toolNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// ToolInfo は tool list で表示するツールの情報
+type ToolInfo struct {
+	Name string
+	// BuiltIn はビルトインツール（DefaultKnownToolFileNames に含まれる）かどうか
+	BuiltIn bool
+	// Defined は設定ファイル（extends を含む）の [tools] に定義されているかどうか
+	Defined bool
+	// AIToolSettings はツールの設定。ビルトインツールの dir_name/file_name は省略時の値で補完される
+	AIToolSettings
+}
+
+// SettingsEdit は設定ファイルの書き換え結果
+type SettingsEdit struct {
+	// Content は書き換え後の設定ファイルの内容
+	Content []byte
+	// Changed は設定値が変更されたかどうか
+	Changed bool
+}
+
+// ListTools は settingsPath の設定ファイル（extends を含む）に定義されたツールと、
+// 定義されていないビルトインツールを名前順に返す
+func ListTools(settingsPath string) ([]ToolInfo, error) {
+	resolved, err := ResolveSettings(settingsPath)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := resolved.Decode()
+	if err != nil {
+		return nil, err
+	}
+
+	var tools []ToolInfo
+	for name, tool := range settings.Tools {
+		tools = append(tools, ToolInfo{Name: name, Defined: true, AIToolSettings: tool})
+	}
+	for name := range DefaultKnownToolFileNames {
+		if _, ok := settings.Tools[name]; !ok {
+			tools = append(tools, ToolInfo{Name: name})
+		}
+	}
+
+	for i, tool := range tools {
+		paths, ok := DefaultKnownToolFileNames[tool.Name]
+		if !ok {
+			continue
+		}
+		tools[i].BuiltIn = true
+		if tool.DirName == "" {
+			tools[i].DirName = paths.DirName
+		}
+		if tool.FileName == "" {
+			tools[i].FileName = paths.FileName
+		}
+	}
+
+	sort.Slice(tools, func(i, j int) bool {
+		return tools[i].Name < tools[j].Name
+	})
+	return tools, nil
+}
+
+// AddTool は [tools.NAME] を追加した設定ファイルの内容を返す。ファイルへの書き込みは行わない。
+// ビルトインツールの paths は省略でき、カスタムツールは file_name が必須となる。
+func AddTool(settingsPath string, name string, paths AIToolPaths) (*SettingsEdit, error) {
+	if _, ok := DefaultKnownToolFileNames[name]; !ok {
+		if err := ValidateToolName(name); err != nil {
+			return nil, err
+		}
+	}
+
+	return editSettings(settingsPath, func(editor settingsEditor, values map[string]any, resolved *ResolvedSettings) error {
+		if _, ok := lookupTable(values, toml.Key{"tools", name}); ok {
+			return fmt.Errorf("tool %q is already defined in %s", name, settingsPath)
+		}
+
+		if err := editor.Put(toml.Key{"tools", name, "generate"}, true); err != nil {
+			return err
+		}
+		if paths.DirName != "" {
+			if err := editor.Put(toml.Key{"tools", name, "dir_name"}, string(paths.DirName)); err != nil {
+				return err
+			}
+		}
+		if paths.FileName != "" {
+			if err := editor.Put(toml.Key{"tools", name, "file_name"}, string(paths.FileName)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ValidateToolName はカスタムツール名として使用できる名前かどうかを検証する
+func ValidateToolName(name string) error {
+	if !toolNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid tool name %q: use lowercase letters, digits and underscores, starting with a letter", name)
+	}
+	return nil
+}
+
+// RemoveTool は [tools.NAME] を削除した設定ファイルの内容を返す。ファイルへの書き込みは行わない。
+// extends で継承した設定ファイルのツールは削除できない。
+func RemoveTool(settingsPath string, name string) (*SettingsEdit, error) {
+	return editSettings(settingsPath, func(editor settingsEditor, values map[string]any, resolved *ResolvedSettings) error {
+		if _, ok := lookupTable(values, toml.Key{"tools", name}); !ok {
+			if _, ok := lookupTable(resolved.Values, toml.Key{"tools", name}); ok {
+				return fmt.Errorf("tool %q is defined in an extended settings file, not in %s", name, settingsPath)
+			}
+			return unknownToolError(name, resolved)
+		}
+
+		return editor.Delete(toml.Key{"tools", name})
+	})
+}
+
+// SetToolGenerate はツールの generate を設定した設定ファイルの内容を返す。ファイルへの書き込みは行わない。
+func SetToolGenerate(settingsPath string, name string, generate bool) (*SettingsEdit, error) {
+	return editSettings(settingsPath, func(editor settingsEditor, values map[string]any, resolved *ResolvedSettings) error {
+		if !isKnownTool(name, resolved) {
+			return unknownToolError(name, resolved)
+		}
+
+		return editor.Put(toml.Key{"tools", name, "generate"}, generate)
+	})
+}
+
+// AddToolExclude はツールの exclude に pattern を追加した設定ファイルの内容を返す。ファイルへの書き込みは行わない。
+// settingsPath に exclude がなく array_merge が replace の場合は、継承元の exclude に追加する。
+func AddToolExclude(settingsPath string, name string, pattern string) (*SettingsEdit, error) {
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+
+	return editSettings(settingsPath, func(editor settingsEditor, values map[string]any, resolved *ResolvedSettings) error {
+		if !isKnownTool(name, resolved) {
+			return unknownToolError(name, resolved)
+		}
+
+		key := toml.Key{"tools", name, "exclude"}
+		current, ok := lookupValue(values, key)
+		if !ok && values["array_merge"] != string(ArrayMergeAppend) {
+			current, _ = lookupValue(resolved.Values, key)
+		}
+
+		excludes, err := stringList(current)
+		if err != nil {
+			return fmt.Errorf("%s must be an array of patterns", key)
+		}
+		if slices.Contains(excludes, pattern) {
+			return nil
+		}
+
+		if ok {
+			return editor.Append(key, pattern)
+		}
+		return editor.Put(key, stringsToValues(append(excludes, pattern)))
+	})
+}
+
+// editSettings は settingsPath の設定ファイルを edit で書き換えた内容を返す。
+// values は settingsPath の設定値、resolved は extends をマージした設定値で、どちらも書き換え前のもの。
+// 書き換えによって新しく発生した設定のエラーがある場合はエラーを返す。
+func editSettings(settingsPath string, edit func(editor settingsEditor, values map[string]any, resolved *ResolvedSettings) error) (*SettingsEdit, error) {
+	format, err := FormatFromPath(settingsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(settingsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := decodeValues(format, content)
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := ResolveSettings(settingsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	editor, err := newSettingsEditor(format, content)
+	if err != nil {
+		return nil, err
+	}
+	if err := edit(editor, values, resolved); err != nil {
+		return nil, err
+	}
+
+	edited, err := editor.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	editedValues, err := decodeValues(format, edited)
+	if err != nil {
+		return nil, fmt.Errorf("failed to edit %s: %w", settingsPath, err)
+	}
+	if reflect.DeepEqual(values, editedValues) {
+		return &SettingsEdit{Content: content}, nil
+	}
+
+	if err := validateEdit(settingsPath, resolved, edited); err != nil {
+		return nil, err
+	}
+
+	return &SettingsEdit{Content: edited, Changed: true}, nil
+}
+
+// validateEdit は書き換え後の設定を検証し、書き換え前にはなかったエラーを返す
+func validateEdit(settingsPath string, before *ResolvedSettings, edited []byte) error {
+	absPath, err := filepath.Abs(settingsPath)
+	if err != nil {
+		return err
+	}
+	currentDir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	after, err := resolveSettingsContent(settingsPath, edited, currentDir, []string{absPath})
+	if err != nil {
+		return err
+	}
+
+	afterSettings, err := after.Decode()
+	if err != nil {
+		return err
+	}
+	if afterSettings.Tools, err = normalizeTools(afterSettings.Tools); err != nil {
+		return err
+	}
+
+	existing := make(map[string]bool)
+	if beforeSettings, err := before.Decode(); err == nil {
+		if beforeSettings.Tools, err = normalizeTools(beforeSettings.Tools); err == nil {
+			for _, finding := range beforeSettings.Validate() {
+				existing[finding.String()] = true
+			}
+		}
+	}
+
+	for _, finding := range afterSettings.Validate() {
+		if finding.Severity == SeverityError && !existing[finding.String()] {
+			return fmt.Errorf("%s", finding.String())
+		}
+	}
+	return nil
+}
+
+// isKnownTool は name がビルトインツールまたは設定ファイルに定義されたツールかを返す
+func isKnownTool(name string, resolved *ResolvedSettings) bool {
+	if _, ok := DefaultKnownToolFileNames[name]; ok {
+		return true
+	}
+	_, ok := lookupTable(resolved.Values, toml.Key{"tools", name})
+	return ok
+}
+
+func unknownToolError(name string, resolved *ResolvedSettings) error {
+	var candidates []string
+	for candidate := range DefaultKnownToolFileNames {
+		candidates = append(candidates, candidate)
+	}
+	if tools, ok := resolved.Values["tools"].(map[string]any); ok {
+		for candidate := range tools {
+			candidates = append(candidates, candidate)
+		}
+	}
+	sort.Strings(candidates)
+
+	if suggestion := closestName(name, candidates); suggestion != "" {
+		return fmt.Errorf("unknown tool %q (did you mean %q?)", name, suggestion)
+	}
+	return fmt.Errorf("unknown tool %q", name)
+}
+
+func lookupValue(values map[string]any, key toml.Key) (any, bool) {
+	table, ok := lookupTable(values, key[:len(key)-1])
+	if !ok {
+		return nil, false
+	}
+	value, ok := table[key[len(key)-1]]
+	return value, ok
+}
@@ -0,0 +1,173 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const toolSettingsTOML = `version = 2
+
+# Claude の設定
+[tools.claude]
+generate = true # 生成する
+exclude = [
+  "draft.md", # 下書き
+]
+
+# Cline の設定
+[tools.cline]
+generate = false
+`
+
+func TestAddTool(t *testing.T) {
+	settingsPath := writeSettingsFile(t, filepath.Join(t.TempDir(), "settings.toml"), toolSettingsTOML)
+
+	t.Run("custom tool", func(t *testing.T) {
+		result, err := AddTool(settingsPath, "cursor", AIToolPaths{DirName: ".cursor", FileName: "rules.md"})
+		require.NoError(t, err)
+		assert.True(t, result.Changed)
+		assert.Equal(t, toolSettingsTOML+"\n[tools.cursor]\ngenerate = true\ndir_name = \".cursor\"\nfile_name = \"rules.md\"\n", string(result.Content))
+	})
+
+	t.Run("built-in tool without paths", func(t *testing.T) {
+		result, err := AddTool(settingsPath, "agents", AIToolPaths{})
+		require.NoError(t, err)
+		assert.Equal(t, toolSettingsTOML+"\n[tools.agents]\ngenerate = true\n", string(result.Content))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := AddTool(settingsPath, "claude", AIToolPaths{})
+		assert.ErrorContains(t, err, "already defined")
+
+		_, err = AddTool(settingsPath, "My Tool", AIToolPaths{FileName: "a.md"})
+		assert.ErrorContains(t, err, "invalid tool name")
+
+		_, err = AddTool(settingsPath, "cursor", AIToolPaths{})
+		assert.ErrorContains(t, err, "missing file_name")
+
+		_, err = AddTool(settingsPath, "cursor", AIToolPaths{FileName: "CLAUDE.md"})
+		assert.ErrorContains(t, err, string(CodeDuplicateOutput))
+
+		_, err = AddTool(settingsPath, "cursor", AIToolPaths{FileName: "/tmp/rules.md"})
+		assert.ErrorContains(t, err, string(CodeAbsolutePath))
+	})
+}
+
+func TestRemoveTool(t *testing.T) {
+	tempDir := t.TempDir()
+	writeSettingsFile(t, filepath.Join(tempDir, "shared.toml"), "[tools.agents]\ngenerate = true\n")
+	settingsPath := writeSettingsFile(t, filepath.Join(tempDir, "settings.toml"), "extends = [\"shared.toml\"]\n"+toolSettingsTOML)
+
+	result, err := RemoveTool(settingsPath, "claude")
+	require.NoError(t, err)
+	assert.Equal(t, "extends = [\"shared.toml\"]\nversion = 2\n\n# Cline の設定\n[tools.cline]\ngenerate = false\n", string(result.Content))
+
+	result, err = RemoveTool(settingsPath, "cline")
+	require.NoError(t, err)
+	assert.Equal(t, "extends = [\"shared.toml\"]\nversion = 2\n\n# Claude の設定\n[tools.claude]\ngenerate = true # 生成する\nexclude = [\n  \"draft.md\", # 下書き\n]\n", string(result.Content))
+
+	_, err = RemoveTool(settingsPath, "agents")
+	assert.ErrorContains(t, err, "extended settings file")
+
+	_, err = RemoveTool(settingsPath, "clin")
+	assert.ErrorContains(t, err, `did you mean "cline"`)
+}
+
+func TestSetToolGenerate(t *testing.T) {
+	settingsPath := writeSettingsFile(t, filepath.Join(t.TempDir(), "settings.toml"), toolSettingsTOML)
+
+	result, err := SetToolGenerate(settingsPath, "claude", false)
+	require.NoError(t, err)
+	assert.True(t, result.Changed)
+	assert.Contains(t, string(result.Content), "generate = false # 生成する\n")
+
+	result, err = SetToolGenerate(settingsPath, "claude", true)
+	require.NoError(t, err)
+	assert.False(t, result.Changed)
+	assert.Equal(t, toolSettingsTOML, string(result.Content))
+
+	result, err = SetToolGenerate(settingsPath, "github_copilot", true)
+	require.NoError(t, err)
+	assert.True(t, result.Changed)
+	assert.Contains(t, string(result.Content), "\n[tools.github_copilot]\ngenerate = true\n")
+
+	_, err = SetToolGenerate(settingsPath, "cursor", true)
+	assert.ErrorContains(t, err, `unknown tool "cursor"`)
+}
+
+func TestAddToolExclude(t *testing.T) {
+	settingsPath := writeSettingsFile(t, filepath.Join(t.TempDir(), "settings.toml"), toolSettingsTOML)
+
+	t.Run("multi-line array keeps comments", func(t *testing.T) {
+		result, err := AddToolExclude(settingsPath, "claude", "secret*.md")
+		require.NoError(t, err)
+		assert.Contains(t, string(result.Content), "exclude = [\n  \"draft.md\", # 下書き\n  \"secret*.md\",\n]\n")
+	})
+
+	t.Run("new array", func(t *testing.T) {
+		result, err := AddToolExclude(settingsPath, "cline", "draft.md")
+		require.NoError(t, err)
+		assert.Contains(t, string(result.Content), "[tools.cline]\ngenerate = false\nexclude = [\"draft.md\"]\n")
+	})
+
+	t.Run("existing pattern", func(t *testing.T) {
+		result, err := AddToolExclude(settingsPath, "claude", "draft.md")
+		require.NoError(t, err)
+		assert.False(t, result.Changed)
+	})
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		_, err := AddToolExclude(settingsPath, "claude", "[")
+		assert.ErrorContains(t, err, "invalid pattern")
+	})
+}
+
+func TestAddToolExcludeInherited(t *testing.T) {
+	tempDir := t.TempDir()
+	writeSettingsFile(t, filepath.Join(tempDir, "shared.toml"), "[tools.claude]\ngenerate = true\nexclude = [\"shared.md\"]\n")
+	settingsPath := writeSettingsFile(t, filepath.Join(tempDir, "settings.toml"), "extends = [\"shared.toml\"]\n")
+
+	// 継承元の exclude を置き換えないよう、継承元のパターンも含める
+	result, err := AddToolExclude(settingsPath, "claude", "local.md")
+	require.NoError(t, err)
+	assert.Equal(t, "extends = [\"shared.toml\"]\n\n[tools.claude]\nexclude = [\"shared.md\", \"local.md\"]\n", string(result.Content))
+}
+
+func TestToolEditYAML(t *testing.T) {
+	settingsPath := writeSettingsFile(t, filepath.Join(t.TempDir(), "settings.yaml"), "# 設定\ntools:\n  claude:\n    generate: true # 生成する\n    exclude:\n      - draft.md # 下書き\n")
+
+	result, err := AddToolExclude(settingsPath, "claude", "secret.md")
+	require.NoError(t, err)
+	assert.Equal(t, "# 設定\ntools:\n  claude:\n    generate: true # 生成する\n    exclude:\n      - draft.md # 下書き\n      - secret.md\n", string(result.Content))
+
+	require.NoError(t, os.WriteFile(settingsPath, result.Content, 0644))
+	result, err = AddTool(settingsPath, "cursor", AIToolPaths{FileName: ".cursorrules"})
+	require.NoError(t, err)
+	assert.Contains(t, string(result.Content), "  cursor:\n    generate: true\n    file_name: .cursorrules\n")
+}
+
+func TestListTools(t *testing.T) {
+	settingsPath := writeSettingsFile(t, filepath.Join(t.TempDir(), "settings.toml"), toolSettingsTOML+"\n[tools.cursor]\ngenerate = true\nfile_name = \".cursorrules\"\n")
+
+	tools, err := ListTools(settingsPath)
+	require.NoError(t, err)
+
+	var names []string
+	for _, tool := range tools {
+		names = append(names, tool.Name)
+	}
+	assert.Equal(t, []string{"agents", "claude", "cline", "cursor", "github_copilot"}, names)
+
+	assert.Equal(t, ToolInfo{Name: "agents", BuiltIn: true, AIToolSettings: AIToolSettings{AIToolPaths: AIToolPaths{FileName: "AGENTS.md"}}}, tools[0])
+	assert.True(t, tools[1].Defined)
+	assert.True(t, tools[1].Generate)
+	assert.Equal(t, []string{"draft.md"}, tools[1].Exclude)
+	assert.False(t, tools[2].Generate)
+	assert.False(t, tools[3].BuiltIn)
+	assert.Equal(t, FileName(".cursorrules"), tools[3].FileName)
+	assert.Equal(t, DirName(".github"), tools[4].DirName)
+}
@@ -0,0 +1,397 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// UnknownKey は設定ファイル内で使用されていない（未知の）キーを表す
+type UnknownKey struct {
+	// File はキーが記述された設定ファイルのパス
+	File string
+	// Line はキーが記述された行番号（不明な場合は 0）
+	Line int
+	// Key はキーのパス（例: "tools.claude.exlude"）
+	Key string
+	// Suggestion は typo と思われる場合の候補（候補がない場合は空）
+	Suggestion string
+}
+
+func (k UnknownKey) String() string {
+	location := k.File
+	if k.Line > 0 {
+		location = fmt.Sprintf("%s:%d", k.File, k.Line)
+	}
+
+	message := fmt.Sprintf("%s: unknown key %q", location, k.Key)
+	if k.Suggestion != "" {
+		message += fmt.Sprintf(" (did you mean %q?)", k.Suggestion)
+	}
+	return message
+}
+
+// UnknownKeysError は strict モードで未知のキーが見つかった場合のエラー
+type UnknownKeysError struct {
+	Keys []UnknownKey
+}
+
+func (e *UnknownKeysError) Error() string {
+	var messages []string
+	for _, key := range e.Keys {
+		messages = append(messages, key.String())
+	}
+	return strings.Join(messages, "\n")
+}
+
+// findUnknownKeys は content を Settings にデコードし、対応するフィールドがないキーを返す。
+// 未知のテーブル配下のキーは、そのテーブルのキーのみを報告する。
+func findUnknownKeys(file string, content string) ([]UnknownKey, error) {
+	var settings Settings
+	md, err := toml.Decode(content, &settings)
+	if err != nil {
+		return nil, err
+	}
+
+	reported := make(map[string]bool)
+
+	var unknownKeys []UnknownKey
+	for _, key := range md.Undecoded() {
+		key = unknownPrefix(key)
+		if reported[key.String()] {
+			continue
+		}
+		reported[key.String()] = true
+
+		unknownKeys = append(unknownKeys, UnknownKey{
+			File:       file,
+			Line:       findKeyLine(content, key),
+			Key:        key.String(),
+			Suggestion: suggestKey(key),
+		})
+	}
+
+	return unknownKeys, nil
+}
+
+// findUnknownKeysInValues は YAML/JSON から読み込んだ設定値のうち、Settings に対応するフィールドがないキーを返す
+func findUnknownKeysInValues(file string, format Format, content []byte, values map[string]any) []UnknownKey {
+	keyLine := keyLineFinder(format, content)
+
+	var unknownKeys []UnknownKey
+	walkKeys(values, nil, func(key toml.Key) bool {
+		if _, ok := typeAtPath(key); ok {
+			return true
+		}
+
+		unknownKeys = append(unknownKeys, UnknownKey{
+			File:       file,
+			Line:       keyLine(key),
+			Key:        key.String(),
+			Suggestion: suggestKey(key),
+		})
+		return false
+	})
+
+	sort.Slice(unknownKeys, func(i, j int) bool {
+		if unknownKeys[i].Line != unknownKeys[j].Line {
+			return unknownKeys[i].Line < unknownKeys[j].Line
+		}
+		return unknownKeys[i].Key < unknownKeys[j].Key
+	})
+
+	return unknownKeys
+}
+
+// walkKeys は values の全てのキーを深さ優先でたどる。fn が false を返した場合はその配下をたどらない。
+func walkKeys(values map[string]any, prefix toml.Key, fn func(key toml.Key) bool) {
+	for name, value := range values {
+		key := append(append(toml.Key{}, prefix...), name)
+		if !fn(key) {
+			continue
+		}
+		if table, ok := value.(map[string]any); ok {
+			walkKeys(table, key, fn)
+		}
+	}
+}
+
+// keyLineFinder はキーが記述されている行番号を返す関数を返す
+func keyLineFinder(format Format, content []byte) func(key toml.Key) int {
+	var lines map[string]int
+	switch format {
+	case FormatYAML:
+		lines = yamlKeyLines(content)
+	case FormatJSON:
+		lines = jsonKeyLines(content)
+	default:
+		return func(key toml.Key) int {
+			return findKeyLine(string(content), key)
+		}
+	}
+
+	return func(key toml.Key) int {
+		return lines[key.String()]
+	}
+}
+
+// yamlKeyLines は YAML のキーのパスごとに記述されている行番号を返す
+func yamlKeyLines(content []byte) map[string]int {
+	lines := make(map[string]int)
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(content, &document); err != nil || len(document.Content) == 0 {
+		return lines
+	}
+
+	var walk func(node *yaml.Node, prefix toml.Key)
+	walk = func(node *yaml.Node, prefix toml.Key) {
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := append(append(toml.Key{}, prefix...), node.Content[i].Value)
+			lines[key.String()] = node.Content[i].Line
+			walk(node.Content[i+1], key)
+		}
+	}
+	walk(document.Content[0], nil)
+
+	return lines
+}
+
+// jsonKeyLines は JSON のキーのパスごとに記述されている行番号を返す
+func jsonKeyLines(content []byte) map[string]int {
+	lines := make(map[string]int)
+	decoder := json.NewDecoder(bytes.NewReader(content))
+
+	var walk func(prefix toml.Key) error
+	walk = func(prefix toml.Key) error {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		switch token {
+		case json.Delim('{'):
+			for decoder.More() {
+				keyToken, err := decoder.Token()
+				if err != nil {
+					return err
+				}
+				key := append(append(toml.Key{}, prefix...), fmt.Sprint(keyToken))
+				lines[key.String()] = bytes.Count(content[:decoder.InputOffset()], []byte("\n")) + 1
+
+				if err := walk(key); err != nil {
+					return err
+				}
+			}
+			_, err = decoder.Token()
+			return err
+		case json.Delim('['):
+			for decoder.More() {
+				if err := walk(prefix); err != nil {
+					return err
+				}
+			}
+			_, err = decoder.Token()
+			return err
+		}
+
+		return nil
+	}
+
+	// 構文エラーの場合は見つかった範囲の行番号のみを返す
+	_ = walk(nil)
+
+	return lines
+}
+
+// unknownPrefix は key のうち、最初に未知となる要素までのパスを返す
+func unknownPrefix(key toml.Key) toml.Key {
+	for i := range key {
+		if _, ok := typeAtPath(key[:i+1]); !ok {
+			return key[:i+1]
+		}
+	}
+	return key
+}
+
+// typeAtPath は Settings を path に沿ってたどった先の型を返す。
+// map の要素はキー名によらず同じ型として扱う。
+func typeAtPath(path []string) (reflect.Type, bool) {
+	typ := reflect.TypeOf(Settings{})
+	for _, part := range path {
+		switch typ.Kind() {
+		case reflect.Map:
+			typ = typ.Elem()
+		case reflect.Struct:
+			field, ok := fieldByTag(typ, part)
+			if !ok {
+				return nil, false
+			}
+			typ = field.Type
+		default:
+			return nil, false
+		}
+	}
+	return typ, true
+}
+
+// findKeyLine はキーが記述されている行番号を返す。見つからない場合は 0 を返す。
+func findKeyLine(content string, key toml.Key) int {
+	var table []string
+	prefixLine := 0
+
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			header := strings.Trim(strings.SplitN(line, "]", 2)[0], "[ ")
+			table = splitDottedKey(header)
+
+			if keyEquals(table, key) {
+				return i + 1
+			}
+			if prefixLine == 0 && len(table) > len(key) && keyEquals(table[:len(key)], key) {
+				prefixLine = i + 1
+			}
+			continue
+		}
+
+		name, _, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+		fullKey := append(append([]string{}, table...), splitDottedKey(name)...)
+		if keyEquals(fullKey, key) {
+			return i + 1
+		}
+		if prefixLine == 0 && len(fullKey) > len(key) && keyEquals(fullKey[:len(key)], key) {
+			prefixLine = i + 1
+		}
+	}
+
+	return prefixLine
+}
+
+// splitDottedKey は "tools.\"my tool\"" のようなドット区切りのキーを分割する
+func splitDottedKey(dotted string) []string {
+	var parts []string
+	for _, part := range strings.Split(dotted, ".") {
+		parts = append(parts, strings.Trim(strings.TrimSpace(part), `"'`))
+	}
+	return parts
+}
+
+func keyEquals(parts []string, key toml.Key) bool {
+	if len(parts) != len(key) {
+		return false
+	}
+	for i := range parts {
+		if parts[i] != key[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// suggestKey は同じ階層で有効なキーのうち、key の最後の要素に最も近いものを返す
+func suggestKey(key toml.Key) string {
+	suggestion := closestName(key[len(key)-1], knownKeys(key[:len(key)-1]))
+	if suggestion == "" {
+		return ""
+	}
+	return append(append(toml.Key{}, key[:len(key)-1]...), suggestion).String()
+}
+
+// closestName は candidates のうち name に最も近い名前を返す。近いものがない場合は空文字を返す
+func closestName(name string, candidates []string) string {
+	suggestion := ""
+	bestDistance := 0
+	for _, candidate := range candidates {
+		distance := levenshtein(name, candidate)
+		if distance > 2 || distance >= len(name) {
+			continue
+		}
+		if suggestion == "" || distance < bestDistance {
+			suggestion = candidate
+			bestDistance = distance
+		}
+	}
+	return suggestion
+}
+
+// knownKeys は path の位置にある構造体で有効なキーの一覧を返す
+func knownKeys(path []string) []string {
+	typ, ok := typeAtPath(path)
+	if !ok || typ.Kind() != reflect.Struct {
+		return nil
+	}
+	return tagNames(typ)
+}
+
+func fieldByTag(typ reflect.Type, tag string) (reflect.StructField, bool) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.Anonymous {
+			if embedded, ok := fieldByTag(field.Type, tag); ok {
+				return embedded, true
+			}
+			continue
+		}
+		if field.Tag.Get("toml") == tag {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func tagNames(typ reflect.Type) []string {
+	var names []string
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.Anonymous {
+			names = append(names, tagNames(field.Type)...)
+			continue
+		}
+		if tag := field.Tag.Get("toml"); tag != "" && tag != "-" {
+			names = append(names, tag)
+		}
+	}
+	return names
+}
+
+// levenshtein は 2 つの文字列の編集距離を返す
+func levenshtein(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+
+	prev := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ar); i++ {
+		curr := make([]int, len(br)+1)
+		curr[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev = curr
+	}
+
+	return prev[len(br)]
+}
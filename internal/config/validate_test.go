@@ -0,0 +1,99 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindUnknownKeys(t *testing.T) {
+	content := `# comment
+[app]
+headr = "header"
+
+[tool.claude]
+generate = true
+
+[tools.claude]
+generate = true
+exlude = ["secret*.md"]
+
+[tools.mytool]
+file_name = "mytool.md"
+unrelated = true
+
+[profiles.public.app]
+output_dri = "dist"
+`
+
+	keys, err := findUnknownKeys("settings.toml", content)
+	require.NoError(t, err)
+
+	expected := []UnknownKey{
+		{File: "settings.toml", Line: 3, Key: "app.headr", Suggestion: "app.header"},
+		{File: "settings.toml", Line: 5, Key: "tool", Suggestion: "tools"},
+		{File: "settings.toml", Line: 10, Key: "tools.claude.exlude", Suggestion: "tools.claude.exclude"},
+		{File: "settings.toml", Line: 14, Key: "tools.mytool.unrelated"},
+		{File: "settings.toml", Line: 17, Key: "profiles.public.app.output_dri", Suggestion: "profiles.public.app.output_dir"},
+	}
+	assert.ElementsMatch(t, expected, keys)
+}
+
+func TestFindKeyLine(t *testing.T) {
+	content := "[app]\nheader = \"a\"\n\n[tools.\"my tool\"]\ngenerate = true\n"
+
+	assert.Equal(t, 2, findKeyLine(content, toml.Key{"app", "header"}))
+	assert.Equal(t, 4, findKeyLine(content, toml.Key{"tools", "my tool"}))
+	assert.Equal(t, 5, findKeyLine(content, toml.Key{"tools", "my tool", "generate"}))
+	assert.Equal(t, 0, findKeyLine(content, toml.Key{"missing"}))
+}
+
+func TestLevenshtein(t *testing.T) {
+	assert.Equal(t, 0, levenshtein("exclude", "exclude"))
+	assert.Equal(t, 1, levenshtein("exlude", "exclude"))
+	assert.Equal(t, 2, levenshtein("generete", "generat"))
+	assert.Equal(t, 3, levenshtein("", "abc"))
+}
+
+func TestLoadSettingsWithOptionsStrict(t *testing.T) {
+	settingsPath := writeSettingsFile(t, filepath.Join(t.TempDir(), "settings.toml"), `[tools.claude]
+generate = true
+exlude = ["secret*.md"]
+`)
+
+	t.Run("unknown keys are recorded", func(t *testing.T) {
+		settings, err := LoadSettingsWithOptions(settingsPath, LoadOptions{})
+		require.NoError(t, err)
+
+		require.Len(t, settings.UnknownKeys, 1)
+		assert.Equal(t, "tools.claude.exlude", settings.UnknownKeys[0].Key)
+		assert.Equal(t, 3, settings.UnknownKeys[0].Line)
+	})
+
+	t.Run("strict mode returns an error", func(t *testing.T) {
+		_, err := LoadSettingsWithOptions(settingsPath, LoadOptions{Strict: true})
+		require.Error(t, err)
+
+		var unknownKeysErr *UnknownKeysError
+		require.True(t, errors.As(err, &unknownKeysErr))
+		assert.Len(t, unknownKeysErr.Keys, 1)
+		assert.Contains(t, err.Error(), `did you mean "tools.claude.exclude"?`)
+	})
+
+	t.Run("unknown keys in extended files are reported", func(t *testing.T) {
+		tempDir := t.TempDir()
+		writeSettingsFile(t, filepath.Join(tempDir, "shared.toml"), "[app]\nfootr = \"footer\"\n")
+		projectPath := writeSettingsFile(t, filepath.Join(tempDir, "settings.toml"), "extends = [\"shared.toml\"]\n")
+
+		settings, err := LoadSettingsWithOptions(projectPath, LoadOptions{})
+		require.NoError(t, err)
+
+		require.Len(t, settings.UnknownKeys, 1)
+		assert.Equal(t, "app.footr", settings.UnknownKeys[0].Key)
+		assert.Equal(t, filepath.Join(tempDir, "shared.toml"), settings.UnknownKeys[0].File)
+	})
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"fmt"
+	"math"
+)
+
+// CurrentVersion は現在の設定ファイルの形式のバージョン。
+// version が省略された設定ファイルはバージョン 1 として扱う。
+//
+//   - 1: 相対パスをカレントディレクトリ（またはプロジェクトルート）から解決する
+//   - 2: 相対パスを設定ファイルのディレクトリから解決する
+const CurrentVersion = 2
+
+// settingsVersion は設定値の version を返す。version がない場合は 1 を返す。
+func settingsVersion(values map[string]any) (int, error) {
+	value, ok := values["version"]
+	if !ok {
+		return 1, nil
+	}
+
+	var version int
+	switch v := value.(type) {
+	case int64:
+		version = int(v)
+	case int:
+		version = v
+	case float64:
+		// JSON の数値は float64 としてデコードされる
+		if v != math.Trunc(v) {
+			return 0, fmt.Errorf("version must be an integer, got %v", v)
+		}
+		version = int(v)
+	default:
+		return 0, fmt.Errorf("version must be an integer, got %T", value)
+	}
+
+	if version < 1 {
+		return 0, fmt.Errorf("version must be 1 or greater, got %d", version)
+	}
+	if version > CurrentVersion {
+		return 0, fmt.Errorf("settings version %d is newer than the supported version %d; update system-prompt-gen", version, CurrentVersion)
+	}
+
+	return version, nil
+}
@@ -0,0 +1,127 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cateiru/system-prompt-gen/internal/config"
+	"github.com/cateiru/system-prompt-gen/internal/i18n"
+	"github.com/cateiru/system-prompt-gen/internal/tokenizer"
+	"github.com/cateiru/system-prompt-gen/internal/util"
+)
+
+// BudgetViolation は出力ファイルがツールの max_tokens/max_bytes を超えていることを表す
+type BudgetViolation struct {
+	ToolName string
+	Path     string
+	Stats    tokenizer.Stats
+	// MaxTokens/MaxBytes は超えた上限（超えていない上限は 0）
+	MaxTokens int
+	MaxBytes  int
+}
+
+// String は警告やエラーとして表示するメッセージを返す
+func (v BudgetViolation) String() string {
+	var details []string
+	if v.MaxTokens > 0 {
+		details = append(details, i18n.T("budget_exceeded_tokens", map[string]any{
+			"Tokens":    v.Stats.Tokens,
+			"MaxTokens": v.MaxTokens,
+		}))
+	}
+	if v.MaxBytes > 0 {
+		details = append(details, i18n.T("budget_exceeded_bytes", map[string]any{
+			"Bytes":    v.Stats.Bytes,
+			"MaxBytes": v.MaxBytes,
+		}))
+	}
+
+	return i18n.T("budget_exceeded", map[string]any{
+		"Path":     util.ToRelativePath(v.Path),
+		"ToolName": v.ToolName,
+		"Details":  strings.Join(details, ", "),
+	})
+}
+
+// Tokenizer は設定の app.tokenizer のトークナイザーを返す
+func (g *Generator) Tokenizer() (tokenizer.Tokenizer, error) {
+	if g.tokenizer == nil {
+		tok, err := tokenizer.New(g.settings.App.Tokenizer)
+		if err != nil {
+			return nil, err
+		}
+		g.tokenizer = tok
+	}
+	return g.tokenizer, nil
+}
+
+// Measure は content のトークン数、単語数、バイト数を数える
+func (g *Generator) Measure(content string) (tokenizer.Stats, error) {
+	tok, err := g.Tokenizer()
+	if err != nil {
+		return tokenizer.Stats{}, err
+	}
+	return tokenizer.Measure(tok, content), nil
+}
+
+// CheckBudget は stats が target のツールの上限を超えているかを判定する
+func (g *Generator) CheckBudget(target OutputTarget, stats tokenizer.Stats) (BudgetViolation, bool) {
+	tool := g.settings.Tools[target.ToolName]
+
+	violation := BudgetViolation{
+		ToolName: target.ToolName,
+		Path:     target.Path,
+		Stats:    stats,
+	}
+	if tool.MaxTokens > 0 && stats.Tokens > tool.MaxTokens {
+		violation.MaxTokens = tool.MaxTokens
+	}
+	if tool.MaxBytes > 0 && stats.Bytes > tool.MaxBytes {
+		violation.MaxBytes = tool.MaxBytes
+	}
+
+	return violation, violation.MaxTokens > 0 || violation.MaxBytes > 0
+}
+
+// CheckBudgets は全ての出力先の内容を生成し、上限を超えている出力先を返す
+func (g *Generator) CheckBudgets() ([]BudgetViolation, error) {
+	targets, err := g.BuildTargets()
+	if err != nil {
+		return nil, err
+	}
+	return g.checkTargetBudgets(targets)
+}
+
+func (g *Generator) checkTargetBudgets(targets []OutputTarget) ([]BudgetViolation, error) {
+	var violations []BudgetViolation
+	for _, target := range targets {
+		tool := g.settings.Tools[target.ToolName]
+		if tool.MaxTokens == 0 && tool.MaxBytes == 0 {
+			continue
+		}
+
+		stats, err := g.Measure(g.GeneratePrompt(target.Files))
+		if err != nil {
+			return nil, err
+		}
+		if violation, exceeded := g.CheckBudget(target, stats); exceeded {
+			violations = append(violations, violation)
+		}
+	}
+	return violations, nil
+}
+
+// budgetError は budget_policy が error の場合に、上限を超えた出力先をまとめたエラーを返す
+func (g *Generator) budgetError(violations []BudgetViolation) error {
+	if len(violations) == 0 || g.settings.App.BudgetPolicy != config.BudgetPolicyError {
+		return nil
+	}
+
+	messages := make([]string, len(violations))
+	for i, violation := range violations {
+		messages[i] = "  - " + violation.String()
+	}
+	return fmt.Errorf("%s", i18n.T("budget_exceeded_error", map[string]any{
+		"Violations": strings.Join(messages, "\n"),
+	}))
+}
@@ -0,0 +1,84 @@
+package generator
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cateiru/system-prompt-gen/internal/config"
+	"github.com/cateiru/system-prompt-gen/internal/i18n"
+	"github.com/cateiru/system-prompt-gen/internal/testutil"
+	"github.com/cateiru/system-prompt-gen/internal/tokenizer"
+)
+
+// budgetSettings は test ツールに上限を設定した設定を返す
+func budgetSettings(t *testing.T, policy config.BudgetPolicy, maxTokens, maxBytes int) *config.Settings {
+	t.Helper()
+
+	settings := config.TestSettings(t)
+	settings.App.Tokenizer = "chars"
+	settings.App.BudgetPolicy = policy
+
+	tool := settings.Tools["test"]
+	tool.MaxTokens = maxTokens
+	tool.MaxBytes = maxBytes
+	settings.Tools["test"] = tool
+
+	// "Test Header\n# test\n\n" + 40 バイトの本文 + "\n\n" + "Test Footer\n" = 74 バイト
+	testutil.CreateTestFile(t, filepath.Join(settings.App.InputDir, "test.md"), "0123456789012345678901234567890123456789")
+
+	return settings
+}
+
+func TestRun_BudgetWarn(t *testing.T) {
+	i18n.TestSetupI18n(t)
+
+	settings := budgetSettings(t, config.BudgetPolicyWarn, 10, 100)
+
+	gen := New(settings)
+	require.NoError(t, gen.Run())
+
+	outputPath := filepath.Join(settings.App.OutputDir, "test.md")
+	testutil.AssertFileExists(t, outputPath)
+
+	warnings := gen.BudgetWarnings()
+	require.Len(t, warnings, 1)
+	assert.Equal(t, BudgetViolation{
+		ToolName:  "test",
+		Path:      outputPath,
+		Stats:     tokenizer.Stats{Tokens: 19, Words: 7, Bytes: 74},
+		MaxTokens: 10,
+	}, warnings[0])
+	assert.Contains(t, warnings[0].String(), "exceeds its size budget: 19 tokens (max_tokens: 10)")
+}
+
+func TestRun_BudgetError(t *testing.T) {
+	i18n.TestSetupI18n(t)
+
+	settings := budgetSettings(t, config.BudgetPolicyError, 0, 50)
+
+	gen := New(settings)
+	err := gen.Run()
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "74 bytes (max_bytes: 50)")
+
+	// 上限を超えた場合は何も書き込まない
+	testutil.AssertFileNotExists(t, filepath.Join(settings.App.OutputDir, "test.md"))
+	testutil.AssertFileNotExists(t, filepath.Join(settings.App.OutputDir, "CLAUDE.md"))
+}
+
+func TestCheckBudgets(t *testing.T) {
+	i18n.TestSetupI18n(t)
+
+	violations, err := New(budgetSettings(t, config.BudgetPolicyError, 100, 100)).CheckBudgets()
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+
+	settings := budgetSettings(t, config.BudgetPolicyWarn, 10, 0)
+	settings.App.Tokenizer = "unknown"
+	_, err = New(settings).CheckBudgets()
+	assert.Error(t, err)
+}
@@ -10,21 +10,33 @@ import (
 
 	"github.com/cateiru/system-prompt-gen/internal/config"
 	"github.com/cateiru/system-prompt-gen/internal/i18n"
+	"github.com/cateiru/system-prompt-gen/internal/tokenizer"
+	"github.com/cateiru/system-prompt-gen/internal/util"
 )
 
 type Generator struct {
 	settings *config.Settings
+
+	tokenizer tokenizer.Tokenizer
+	// budgetWarnings は最後の書き込みで上限を超えた出力先（budget_policy が warn の場合）
+	budgetWarnings []BudgetViolation
 }
 
 type PromptFile struct {
 	Path     string
 	Filename string
 	Content  string
+	// RelPath はレイヤー（入力ディレクトリ）からの相対パス
+	RelPath string
+	// Layer はこのファイルを含む入力ディレクトリ（設定ファイルのディレクトリから解決したパス）
+	Layer string
 }
 
 type OutputTarget struct {
 	Path     string
 	ToolName string
+	// Files はこの出力先に書き込まれるプロンプトファイル
+	Files []PromptFile
 }
 
 func New(settings *config.Settings) *Generator {
@@ -32,83 +44,111 @@ func New(settings *config.Settings) *Generator {
 }
 
 func (g *Generator) CollectPromptFiles() ([]PromptFile, error) {
-	var files []PromptFile
+	return g.collectLayeredFiles(func(relPath string) bool {
+		return true
+	})
+}
 
-	err := filepath.WalkDir(
-		g.settings.App.InputDir,
-		func(path string, d fs.DirEntry, err error) error {
-			if err != nil {
-				return err
+// CollectPromptFilesForTool はツールの include/exclude に合致するプロンプトファイルを収集する。
+// 不正なパターンがある場合は filepath.ErrBadPattern を含むエラーを返す（何にも合致しないパターンとして無視しない）
+func (g *Generator) CollectPromptFilesForTool(toolName string, toolSettings config.AIToolSettings) ([]PromptFile, error) {
+	for _, patterns := range [][]string{toolSettings.Include, toolSettings.Exclude} {
+		for _, pattern := range patterns {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("%s: invalid pattern %q: %w", toolName, pattern, err)
 			}
+		}
+	}
 
-			if d.IsDir() || !strings.HasSuffix(path, ".md") {
-				return nil
+	// パターンは検証済みのため、以下の filepath.Match はエラーを返さない
+	return g.collectLayeredFiles(func(relPath string) bool {
+		// Include パターンのチェック（未定義の場合は全てを含める）
+		if len(toolSettings.Include) > 0 {
+			includeMatched := false
+			for _, pattern := range toolSettings.Include {
+				if matched, _ := filepath.Match(pattern, relPath); matched {
+					includeMatched = true
+					break
+				}
 			}
+			if !includeMatched {
+				return false
+			}
+		}
 
-			content, err := os.ReadFile(path)
-			if err != nil {
-				return err
+		// Exclude パターンのチェック（結果的にExcludeが優先される）
+		for _, pattern := range toolSettings.Exclude {
+			if matched, _ := filepath.Match(pattern, relPath); matched {
+				return false
 			}
+		}
 
-			files = append(files, PromptFile{
-				Path:     path,
-				Filename: d.Name(),
-				Content:  string(content),
-			})
+		return true
+	})
+}
 
-			return nil
-		},
-	)
+// collectLayeredFiles は全てのレイヤーから filter に合致するプロンプトファイルを収集し、
+// LayerPolicy に従って同じ相対パスのファイルを解決する
+func (g *Generator) collectLayeredFiles(filter func(relPath string) bool) ([]PromptFile, error) {
+	layers := g.settings.App.Layers()
+
+	var files []PromptFile
+	for _, layer := range layers {
+		layerDir := util.ExpandHome(layer)
+
+		// 複数レイヤー構成では存在しないレイヤーをスキップする（ユーザー単位のディレクトリなど）
+		if len(layers) > 1 {
+			if _, err := os.Stat(layerDir); os.IsNotExist(err) {
+				continue
+			}
+		}
 
+		layerFiles, err := collectFromDir(layer, layerDir, filter)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, layerFiles...)
+	}
+
+	files, err := g.resolveLayers(files)
 	if err != nil {
 		return nil, err
 	}
 
-	sort.Slice(files, func(i, j int) bool {
+	sort.SliceStable(files, func(i, j int) bool {
 		return files[i].Filename < files[j].Filename
 	})
 
 	return files, nil
 }
 
-func (g *Generator) CollectPromptFilesForTool(toolName string, toolSettings config.AIToolSettings) ([]PromptFile, error) {
+// collectFromDir は dir 以下の .md ファイルのうち filter に合致するものを収集する
+func collectFromDir(layer string, dir string, filter func(relPath string) bool) ([]PromptFile, error) {
 	var files []PromptFile
 
 	err := filepath.WalkDir(
-		g.settings.App.InputDir,
+		dir,
 		func(path string, d fs.DirEntry, err error) error {
 			if err != nil {
 				return err
 			}
 
+			// init のバックアップは生成に含めない
+			if d.IsDir() && path != dir && d.Name() == config.BackupDirName {
+				return filepath.SkipDir
+			}
+
 			if d.IsDir() || !strings.HasSuffix(path, ".md") {
 				return nil
 			}
 
-			relPath, err := filepath.Rel(g.settings.App.InputDir, path)
+			relPath, err := filepath.Rel(dir, path)
 			if err != nil {
 				return err
 			}
 
-			// Include パターンのチェック（未定義の場合は全てを含める）
-			if len(toolSettings.Include) > 0 {
-				includeMatched := false
-				for _, pattern := range toolSettings.Include {
-					if matched, _ := filepath.Match(pattern, relPath); matched {
-						includeMatched = true
-						break
-					}
-				}
-				if !includeMatched {
-					return nil
-				}
-			}
-
-			// Exclude パターンのチェック（結果的にExcludeが優先される）
-			for _, pattern := range toolSettings.Exclude {
-				if matched, _ := filepath.Match(pattern, relPath); matched {
-					return nil
-				}
+			if !filter(relPath) {
+				return nil
 			}
 
 			content, err := os.ReadFile(path)
@@ -120,6 +160,8 @@ func (g *Generator) CollectPromptFilesForTool(toolName string, toolSettings conf
 				Path:     path,
 				Filename: d.Name(),
 				Content:  string(content),
+				RelPath:  relPath,
+				Layer:    layer,
 			})
 
 			return nil
@@ -130,20 +172,67 @@ func (g *Generator) CollectPromptFilesForTool(toolName string, toolSettings conf
 		return nil, err
 	}
 
-	sort.Slice(files, func(i, j int) bool {
-		return files[i].Filename < files[j].Filename
-	})
-
 	return files, nil
 }
 
+// resolveLayers は同じ相対パスを持つファイルを LayerPolicy に従って解決する。
+// files はレイヤーの優先度の低い順に並んでいる必要がある。
+func (g *Generator) resolveLayers(files []PromptFile) ([]PromptFile, error) {
+	policy := g.settings.App.LayerPolicy
+
+	indexes := make(map[string][]int)
+	for i, file := range files {
+		indexes[file.RelPath] = append(indexes[file.RelPath], i)
+	}
+
+	var resolved []PromptFile
+	for i, file := range files {
+		same := indexes[file.RelPath]
+		if len(same) == 1 {
+			resolved = append(resolved, file)
+			continue
+		}
+
+		switch policy {
+		case config.LayerPolicyAppend:
+			resolved = append(resolved, file)
+		case config.LayerPolicyKeep:
+			if same[0] == i {
+				resolved = append(resolved, file)
+			}
+		case config.LayerPolicyError:
+			var conflictLayers []string
+			for _, index := range same {
+				conflictLayers = append(conflictLayers, files[index].Layer)
+			}
+			return nil, fmt.Errorf("%s", i18n.T("layer_conflict", map[string]interface{}{
+				"RelPath": file.RelPath,
+				"Layers":  strings.Join(conflictLayers, ", "),
+			}))
+		default:
+			// 後ろのレイヤーが優先される
+			if same[len(same)-1] == i {
+				resolved = append(resolved, file)
+			}
+		}
+	}
+
+	return resolved, nil
+}
+
 func (g *Generator) GeneratePrompt(files []PromptFile) string {
 	var content strings.Builder
 
 	content.WriteString(g.settings.App.Header)
 
+	// 複数レイヤー構成では各セクションの取得元を記録する
+	recordLayer := len(g.settings.App.Layers()) > 1
+
 	for _, file := range files {
 		content.WriteString(fmt.Sprintf("# %s\n\n", strings.TrimSuffix(file.Filename, ".md")))
+		if recordLayer {
+			content.WriteString(fmt.Sprintf("<!-- source: %s -->\n", g.sourcePath(file)))
+		}
 		content.WriteString(file.Content)
 
 		if !strings.HasSuffix(file.Content, "\n") {
@@ -157,21 +246,27 @@ func (g *Generator) GeneratePrompt(files []PromptFile) string {
 	return content.String()
 }
 
+// sourcePath は出力ファイルに記録するプロンプトファイルのパスを返す。
+// 生成したファイルは共有されるため、マシン固有の絶対パスではなく OutputDir からの相対パスとする。
+// "~" から始まるレイヤーは設定に記述されたまま記録する。
+func (g *Generator) sourcePath(file PromptFile) string {
+	path := filepath.Join(file.Layer, file.RelPath)
+	if file.Layer == "~" || strings.HasPrefix(file.Layer, "~/") {
+		return filepath.ToSlash(path)
+	}
+	if relPath, err := filepath.Rel(g.settings.App.OutputDir, path); err == nil {
+		return filepath.ToSlash(relPath)
+	}
+	return filepath.ToSlash(path)
+}
+
 func (g *Generator) WriteOutputFiles(content string) error {
 	// TOML設定を使用
 	var outputs []OutputTarget
 
 	for name, tool := range g.settings.Tools {
-		paths := []string{
-			g.settings.App.OutputDir,
-		}
-		if tool.DirName != "" {
-			paths = append(paths, string(tool.DirName))
-		}
-		paths = append(paths, string(tool.FileName))
-
 		outputs = append(outputs, OutputTarget{
-			Path:     filepath.Join(paths...),
+			Path:     g.outputPath("", tool),
 			ToolName: name,
 		})
 	}
@@ -198,33 +293,143 @@ func (g *Generator) WriteOutputFiles(content string) error {
 	return nil
 }
 
-func (g *Generator) WriteOutputFilesWithExcludes() error {
-	for name, tool := range g.settings.Tools {
+// BuildTargets は有効な各ツールについて、出力先とそこに含めるプロンプトファイルを決定する。
+// DirectoryMapping が有効な場合は InputDir のディレクトリ構造ごとに出力先を分ける。
+func (g *Generator) BuildTargets() ([]OutputTarget, error) {
+	var toolNames []string
+	for name := range g.settings.Tools {
+		toolNames = append(toolNames, name)
+	}
+	sort.Strings(toolNames)
+
+	var targets []OutputTarget
+
+	for _, name := range toolNames {
+		tool := g.settings.Tools[name]
+
 		files, err := g.CollectPromptFilesForTool(name, tool)
 		if err != nil {
-			return fmt.Errorf("%s", i18n.T("failed_to_collect_files", map[string]interface{}{
+			return nil, fmt.Errorf("%s", i18n.T("failed_to_collect_files", map[string]interface{}{
 				"Error": err,
 			}))
 		}
 
 		if len(files) == 0 {
-			return fmt.Errorf("%s", i18n.T("no_prompt_files_found", map[string]interface{}{
-				"InputDir": g.settings.App.InputDir,
+			return nil, fmt.Errorf("%s", i18n.T("no_prompt_files_found", map[string]interface{}{
+				"InputDir": strings.Join(g.settings.App.Layers(), ", "),
 			}))
 		}
 
-		content := g.GeneratePrompt(files)
+		if !g.settings.App.DirectoryMapping {
+			targets = append(targets, OutputTarget{
+				Path:     g.outputPath("", tool),
+				ToolName: name,
+				Files:    files,
+			})
+			continue
+		}
+
+		for _, group := range g.groupByDirectory(files) {
+			targets = append(targets, OutputTarget{
+				Path:     g.outputPath(group.dir, tool),
+				ToolName: name,
+				Files:    group.files,
+			})
+		}
+	}
+
+	return targets, nil
+}
 
-		paths := []string{
-			g.settings.App.OutputDir,
+type directoryGroup struct {
+	dir   string
+	files []PromptFile
+}
+
+// groupByDirectory はプロンプトファイルを InputDir からの相対ディレクトリごとにまとめる。
+// InheritParent が有効な場合、各グループの先頭に親ディレクトリのファイルをルートから順に追加する。
+func (g *Generator) groupByDirectory(files []PromptFile) []directoryGroup {
+	byDir := make(map[string][]PromptFile)
+	for _, file := range files {
+		dir := filepath.Dir(file.RelPath)
+		if dir == "." {
+			dir = ""
 		}
-		if tool.DirName != "" {
-			paths = append(paths, string(tool.DirName))
+		byDir[dir] = append(byDir[dir], file)
+	}
+
+	var dirs []string
+	for dir := range byDir {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	var groups []directoryGroup
+	for _, dir := range dirs {
+		var groupFiles []PromptFile
+		if g.settings.App.InheritParent {
+			for _, parent := range parentDirs(dir) {
+				groupFiles = append(groupFiles, byDir[parent]...)
+			}
 		}
-		paths = append(paths, string(tool.FileName))
+		groupFiles = append(groupFiles, byDir[dir]...)
+
+		groups = append(groups, directoryGroup{dir: dir, files: groupFiles})
+	}
+
+	return groups
+}
 
-		outputPath := filepath.Join(paths...)
-		dir := filepath.Dir(outputPath)
+// parentDirs は dir の親ディレクトリをルート ("") から近い順に返す
+func parentDirs(dir string) []string {
+	if dir == "" {
+		return nil
+	}
+
+	parents := []string{""}
+	parts := strings.Split(filepath.ToSlash(dir), "/")
+	for i := 1; i < len(parts); i++ {
+		parents = append(parents, filepath.Join(parts[:i]...))
+	}
+	return parents
+}
+
+// outputPath は OutputDir/subDir/DirName/FileName の形式で出力先のパスを組み立てる
+func (g *Generator) outputPath(subDir string, tool config.AIToolSettings) string {
+	paths := []string{
+		g.settings.App.OutputDir,
+	}
+	if subDir != "" {
+		paths = append(paths, subDir)
+	}
+	if tool.DirName != "" {
+		paths = append(paths, string(tool.DirName))
+	}
+	paths = append(paths, string(tool.FileName))
+
+	return filepath.Join(paths...)
+}
+
+func (g *Generator) WriteOutputFilesWithExcludes() error {
+	targets, err := g.BuildTargets()
+	if err != nil {
+		return err
+	}
+
+	// budget_policy が error の場合は上限を超えた出力先があれば何も書き込まない
+	violations, err := g.checkTargetBudgets(targets)
+	if err != nil {
+		return err
+	}
+	if err := g.budgetError(violations); err != nil {
+		return err
+	}
+	g.budgetWarnings = violations
+
+	for _, target := range targets {
+		content := g.GeneratePrompt(target.Files)
+
+		dir := filepath.Dir(target.Path)
 		if err := os.MkdirAll(dir, 0755); err != nil {
 			return fmt.Errorf("%s", i18n.T("failed_to_create_directory", map[string]interface{}{
 				"DirName": dir,
@@ -232,10 +437,10 @@ func (g *Generator) WriteOutputFilesWithExcludes() error {
 			}))
 		}
 
-		if err := os.WriteFile(outputPath, []byte(content), 0644); err != nil {
+		if err := os.WriteFile(target.Path, []byte(content), 0644); err != nil {
 			return fmt.Errorf("%s", i18n.T("failed_to_write_tool_file", map[string]interface{}{
-				"FileName": outputPath,
-				"ToolName": name,
+				"FileName": target.Path,
+				"ToolName": target.ToolName,
 				"Error":    err,
 			}))
 		}
@@ -244,24 +449,48 @@ func (g *Generator) WriteOutputFilesWithExcludes() error {
 	return nil
 }
 
-func (g *Generator) GetGeneratedTargets() []string {
+// BudgetWarnings は最後の書き込みで max_tokens/max_bytes を超えた出力先を返す
+func (g *Generator) BudgetWarnings() []BudgetViolation {
+	return g.budgetWarnings
+}
+
+// GetGeneratedTargets は生成される出力先のパスを返す。
+// ディレクトリマッピングでは BuildTargets で出力先を決定し、そのエラーを返す。
+func (g *Generator) GetGeneratedTargets() ([]string, error) {
 	var targets []string
 
-	for _, tool := range g.settings.Tools {
-		paths := []string{
-			g.settings.App.OutputDir,
+	// ディレクトリマッピングでは出力先が入力ディレクトリの構造に依存する
+	if g.settings.App.DirectoryMapping {
+		outputs, err := g.BuildTargets()
+		if err != nil {
+			return nil, err
 		}
-		if tool.DirName != "" {
-			paths = append(paths, string(tool.DirName))
+		for _, output := range outputs {
+			targets = append(targets, output.Path)
 		}
-		paths = append(paths, string(tool.FileName))
+		return targets, nil
+	}
 
-		targets = append(targets, filepath.Join(paths...))
+	for _, tool := range g.settings.Tools {
+		targets = append(targets, g.outputPath("", tool))
 	}
 
-	return targets
+	return targets, nil
 }
 
 func (g *Generator) Run() error {
+	// 出力先やパターンに問題がある場合は何も書き込まない
+	if findings := g.settings.Validate(); config.HasErrors(findings) {
+		var messages []string
+		for _, finding := range findings {
+			if finding.Severity == config.SeverityError {
+				messages = append(messages, finding.String())
+			}
+		}
+		return fmt.Errorf("%s", i18n.T("settings_validation_failed", map[string]interface{}{
+			"Findings": strings.Join(messages, "\n"),
+		}))
+	}
+
 	return g.WriteOutputFilesWithExcludes()
 }
@@ -272,7 +272,8 @@ func TestGetGeneratedTargets_TOMLMode(t *testing.T) {
 	}
 	gen := New(settings)
 
-	targets := gen.GetGeneratedTargets()
+	targets, err := gen.GetGeneratedTargets()
+	require.NoError(t, err)
 
 	expected := []string{
 		filepath.Join(tmpDir, "CLAUDE.md"),
@@ -573,6 +574,38 @@ func TestCollectPromptFilesForToolNoExclude(t *testing.T) {
 	}
 }
 
+func TestCollectPromptFilesForToolInvalidPattern(t *testing.T) {
+	i18n.TestSetupI18n(t)
+
+	settings := config.TestSettings(t)
+	testutil.CreateTestFile(t, filepath.Join(settings.App.InputDir, "01_first.md"), "# First\n")
+
+	gen := New(settings)
+
+	// 不正なパターンは何にも合致しないパターンとして無視せずにエラーとする
+	for _, toolSettings := range []config.AIToolSettings{
+		{Generate: true, Include: []string{"[.md"}},
+		{Generate: true, Exclude: []string{"*.md", "[.md"}},
+	} {
+		_, err := gen.CollectPromptFilesForTool("test_tool", toolSettings)
+		assert.ErrorIs(t, err, filepath.ErrBadPattern)
+		assert.ErrorContains(t, err, `test_tool: invalid pattern "[.md"`)
+	}
+
+	// BuildTargets を使用する stats、verify、budget のチェックと出力先の一覧もエラーを返す
+	tool := settings.Tools["test"]
+	tool.Exclude = []string{"[.md"}
+	settings.Tools["test"] = tool
+	_, err := gen.BuildTargets()
+	assert.ErrorContains(t, err, "invalid pattern")
+	_, err = gen.CheckBudgets()
+	assert.ErrorContains(t, err, "invalid pattern")
+
+	settings.App.DirectoryMapping = true
+	_, err = gen.GetGeneratedTargets()
+	assert.ErrorContains(t, err, "invalid pattern")
+}
+
 func TestWriteOutputFilesWithExcludes(t *testing.T) {
 	i18n.TestSetupI18n(t)
 
@@ -630,3 +663,248 @@ func TestWriteOutputFilesWithExcludes(t *testing.T) {
 	assert.Contains(t, clineContent, "Content of second file")
 	assert.Contains(t, clineContent, "Content of third file")
 }
+
+func TestRun_DirectoryMapping(t *testing.T) {
+	i18n.TestSetupI18n(t)
+
+	tests := []struct {
+		name             string
+		inheritParent    bool
+		expectRootInNest bool
+	}{
+		{
+			name:             "without inheritance",
+			inheritParent:    false,
+			expectRootInNest: false,
+		},
+		{
+			name:             "with inheritance",
+			inheritParent:    true,
+			expectRootInNest: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+
+			settings := &config.Settings{
+				App: config.AppSettings{
+					InputDir:         filepath.Join(tempDir, "input"),
+					OutputDir:        tempDir,
+					DirectoryMapping: true,
+					InheritParent:    tt.inheritParent,
+				},
+				Tools: map[string]config.AIToolSettings{
+					"claude": {
+						Generate: true,
+						AIToolPaths: config.AIToolPaths{
+							FileName: "CLAUDE.md",
+						},
+					},
+				},
+			}
+
+			testutil.CreateTestFile(t, filepath.Join(settings.App.InputDir, "001_root.md"), "Root content\n")
+			testutil.CreateTestFile(t, filepath.Join(settings.App.InputDir, "services", "010_services.md"), "Services content\n")
+			testutil.CreateTestFile(t, filepath.Join(settings.App.InputDir, "services", "api", "001_api.md"), "API content\n")
+
+			gen := New(settings)
+			err := gen.Run()
+			require.NoError(t, err)
+
+			rootContent := testutil.ReadTestFile(t, filepath.Join(tempDir, "CLAUDE.md"))
+			assert.Contains(t, rootContent, "Root content")
+			assert.NotContains(t, rootContent, "Services content")
+			assert.NotContains(t, rootContent, "API content")
+
+			apiContent := testutil.ReadTestFile(t, filepath.Join(tempDir, "services", "api", "CLAUDE.md"))
+			assert.Contains(t, apiContent, "API content")
+
+			if tt.expectRootInNest {
+				assert.Contains(t, apiContent, "Root content")
+				assert.Contains(t, apiContent, "Services content")
+				// 親ディレクトリのプロンプトが先に出力される
+				assert.Less(t, strings.Index(apiContent, "Root content"), strings.Index(apiContent, "Services content"))
+				assert.Less(t, strings.Index(apiContent, "Services content"), strings.Index(apiContent, "API content"))
+			} else {
+				assert.NotContains(t, apiContent, "Root content")
+				assert.NotContains(t, apiContent, "Services content")
+			}
+		})
+	}
+}
+
+func TestGetGeneratedTargets_DirectoryMapping(t *testing.T) {
+	i18n.TestSetupI18n(t)
+
+	tempDir := t.TempDir()
+
+	settings := &config.Settings{
+		App: config.AppSettings{
+			InputDir:         filepath.Join(tempDir, "input"),
+			OutputDir:        tempDir,
+			DirectoryMapping: true,
+		},
+		Tools: map[string]config.AIToolSettings{
+			"github_copilot": {
+				Generate: true,
+				AIToolPaths: config.AIToolPaths{
+					DirName:  ".github",
+					FileName: "copilot-instructions.md",
+				},
+			},
+		},
+	}
+
+	testutil.CreateTestFile(t, filepath.Join(settings.App.InputDir, "001_root.md"), "Root content\n")
+	testutil.CreateTestFile(t, filepath.Join(settings.App.InputDir, "web", "001_web.md"), "Web content\n")
+
+	gen := New(settings)
+	targets, err := gen.GetGeneratedTargets()
+	require.NoError(t, err)
+
+	expected := []string{
+		filepath.Join(tempDir, ".github", "copilot-instructions.md"),
+		filepath.Join(tempDir, "web", ".github", "copilot-instructions.md"),
+	}
+	assert.ElementsMatch(t, expected, targets)
+}
+
+func TestParentDirs(t *testing.T) {
+	assert.Nil(t, parentDirs(""))
+	assert.Equal(t, []string{""}, parentDirs("services"))
+	assert.Equal(t, []string{"", "services", filepath.Join("services", "api")}, parentDirs(filepath.Join("services", "api", "v1")))
+}
+
+func TestCollectPromptFiles_Layers(t *testing.T) {
+	i18n.TestSetupI18n(t)
+
+	tests := []struct {
+		name            string
+		policy          config.LayerPolicy
+		expectedContent []string
+		expectError     bool
+	}{
+		{
+			name:            "override uses the last layer",
+			policy:          config.LayerPolicyOverride,
+			expectedContent: []string{"Project base", "Org only"},
+		},
+		{
+			name:            "keep uses the first layer",
+			policy:          config.LayerPolicyKeep,
+			expectedContent: []string{"Org base", "Org only"},
+		},
+		{
+			name:            "append includes every layer",
+			policy:          config.LayerPolicyAppend,
+			expectedContent: []string{"Org base", "Project base", "Org only"},
+		},
+		{
+			name:        "error rejects duplicates",
+			policy:      config.LayerPolicyError,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			orgDir := filepath.Join(tempDir, "org")
+			projectDir := filepath.Join(tempDir, "project")
+
+			testutil.CreateTestFile(t, filepath.Join(orgDir, "01-base.md"), "Org base")
+			testutil.CreateTestFile(t, filepath.Join(orgDir, "02-org.md"), "Org only")
+			testutil.CreateTestFile(t, filepath.Join(projectDir, "01-base.md"), "Project base")
+
+			settings := config.TestSettings(t, config.AppSettings{
+				InputDirs:   []string{orgDir, filepath.Join(tempDir, "missing"), projectDir},
+				LayerPolicy: tt.policy,
+			})
+
+			gen := New(settings)
+			files, err := gen.CollectPromptFiles()
+
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			var contents []string
+			for _, file := range files {
+				contents = append(contents, file.Content)
+			}
+			assert.Equal(t, tt.expectedContent, contents)
+		})
+	}
+}
+
+func TestRun_LayersRecordSource(t *testing.T) {
+	i18n.TestSetupI18n(t)
+
+	tempDir := t.TempDir()
+	testutil.CreateTestFile(t, filepath.Join(tempDir, "org", "01-org.md"), "Org content\n")
+	testutil.CreateTestFile(t, filepath.Join(tempDir, "project", ".system_prompt", "02-project.md"), "Project content\n")
+
+	// 相対パスのレイヤーは設定ファイルのディレクトリから絶対パスに解決される
+	settingsPath := filepath.Join(tempDir, "project", ".system_prompt", "settings.toml")
+	testutil.CreateTestFile(t, settingsPath, `version = 2
+
+[app]
+input_dirs = ["../../org", "."]
+output_dir = ".."
+
+[tools.claude]
+generate = true
+`)
+	settings, err := config.LoadSettings(settingsPath)
+	require.NoError(t, err)
+
+	gen := New(settings)
+	require.NoError(t, gen.Run())
+
+	// 出力ファイルにはマシン固有の絶対パスではなく、出力先からの相対パスを記録する
+	content := testutil.ReadTestFile(t, filepath.Join(tempDir, "project", "CLAUDE.md"))
+	assert.Contains(t, content, "<!-- source: ../org/01-org.md -->")
+	assert.Contains(t, content, "<!-- source: .system_prompt/02-project.md -->")
+	assert.NotContains(t, content, filepath.ToSlash(tempDir))
+}
+
+func TestRun_ValidationError(t *testing.T) {
+	i18n.TestSetupI18n(t)
+
+	settings := config.TestSettings(t)
+	settings.Tools["escape"] = config.AIToolSettings{
+		Generate: true,
+		AIToolPaths: config.AIToolPaths{
+			DirName:  "../outside",
+			FileName: "escape.md",
+		},
+	}
+
+	testutil.CreateTestFile(t, filepath.Join(settings.App.InputDir, "test.md"), "# Test\nTest content\n")
+
+	gen := New(settings)
+	err := gen.Run()
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), string(config.CodePathEscapesOutput))
+
+	// 検証に失敗した場合は何も書き込まない
+	testutil.AssertFileNotExists(t, filepath.Join(settings.App.OutputDir, "CLAUDE.md"))
+}
+
+func TestCollectPromptFilesSkipsBackup(t *testing.T) {
+	i18n.TestSetupI18n(t)
+
+	settings := config.TestSettings(t)
+	testutil.CreateTestFile(t, filepath.Join(settings.App.InputDir, "001_default.md"), "Current\n")
+	testutil.CreateTestFile(t, filepath.Join(settings.App.InputDir, config.BackupDirName, "20250101-000000", "001_default.md"), "Backup\n")
+
+	files, err := New(settings).CollectPromptFiles()
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, "Current\n", files[0].Content)
+}
@@ -4,19 +4,53 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/cateiru/system-prompt-gen/internal/config"
 )
 
 // InitState は初期化プロセスの状態を管理する
 type InitState struct {
-	WorkDir            string
-	SystemPromptDir    string
-	ExistingFiles      []ExistingFile
-	SelectedFiles      []ExistingFile
+	WorkDir         string
+	SystemPromptDir string
+	ExistingFiles   []ExistingFile
+	SelectedFiles   []ExistingFile
+	// SelectedTools は生成を有効にするツール。Template がある場合、テンプレートで有効なツールのうち
+	// 選択されなかったものは無効にする（nil の場合はテンプレートの設定をそのまま使用する）
 	SelectedTools      []string
 	OverwriteConfirmed bool
+	// SplitByHeading が true の場合、選択されたファイルを見出しごとに番号付きのファイルへ分割する
+	SplitByHeading bool
+	// DuplicateSections は分割モードで重複として取り込まなかったセクション
+	DuplicateSections []Section
+	// DetectShared が true の場合、選択されたファイルの共通の内容とツール固有の内容を別のファイルに分ける
+	DetectShared bool
+	// ToolExcludes は settings.toml に書き込むツールごとの exclude
+	ToolExcludes map[string][]string
+	// Merge が true の場合、既存のプロンプトファイルを残し、選択されたツールを既存の設定ファイルに追加する
+	Merge bool
+	// BackupDir は置き換える前のファイルを保存したディレクトリ（バックアップしていない場合は空）
+	BackupDir string
+	// UpdatedFiles は Merge で書き換えた既存のファイル（WorkDir からの相対パス）
+	UpdatedFiles []string
+	// Template はプロンプトファイルと settings.toml の雛形として使用するテンプレート
+	Template *Template
+	// templateSettings は Template の settings.toml の内容
+	templateSettings string
+	// Drafts はプロジェクトの解析から作成したプロンプトファイルの下書き（AnalyzeProject を参照）
+	Drafts []TemplateFile
+	// CustomTools は settings.toml に追加して生成を有効にするカスタムツール
+	CustomTools []CustomTool
+}
+
+// CustomTool はビルトインツール以外の出力先を持つツール
+type CustomTool struct {
+	Name string
+	config.AIToolPaths
 }
 
 // ExistingFile は既存のシステムプロンプトファイルを表す
@@ -24,6 +58,10 @@ type ExistingFile struct {
 	Path     string
 	ToolName string
 	Content  string
+	// Kind はファイルの種類（出力先のファイル、以前の形式のファイル、ルールのディレクトリ内のファイルなど）
+	Kind FileKind
+	// Metadata はルールのファイルのフロントマターの値（例: .mdc の globs、.instructions.md の applyTo）
+	Metadata map[string]string
 }
 
 // NewInitState は新しい InitState を作成する
@@ -63,11 +101,13 @@ func (state *InitState) CreateSystemPromptDir() error {
 	return nil
 }
 
-// WriteDefaultFile は選択されたファイルの内容を 001_default.md に書き込む
-func (state *InitState) WriteDefaultFile() error {
+// WriteDefaultFile は選択されたファイルの内容を 001_default.md に書き込み、作成したファイル名を返す。
+// Merge の場合は既存のファイルと重ならない名前で作成する。
+func (state *InitState) WriteDefaultFile() (string, error) {
+	filename := state.promptFileName("001_default.md")
 	if len(state.SelectedFiles) == 0 {
 		// 選択されたファイルがない場合は空のファイルを作成
-		return state.writeFile("001_default.md", "")
+		return filename, state.writeFile(filename, "")
 	}
 
 	var content string
@@ -78,16 +118,363 @@ func (state *InitState) WriteDefaultFile() error {
 		content += fmt.Sprintf("# Imported from %s\n\n%s", file.Path, file.Content)
 	}
 
-	return state.writeFile("001_default.md", content)
+	return filename, state.writeFile(filename, content)
+}
+
+// Apply は .system_prompt ディレクトリを作成し、プロンプトファイルと settings.toml を書き込む。
+// プロンプトファイルは 001_default.md、または分割モードでは見出しごとの番号付きのファイルとなる。
+// Template を指定した場合はテンプレートのファイルも、Drafts がある場合は下書きのファイルも書き込む。
+// 置き換える既存のファイルは BackupDir にバックアップする。
+// Merge の場合は既存のファイルを置き換えず、既存の設定ファイルに選択されたツールを追加する。
+// 作成したファイルのパスを WorkDir からの相対パスで返す。
+func (state *InitState) Apply() ([]string, error) {
+	state.BackupDir = ""
+	state.UpdatedFiles = nil
+	state.templateSettings = ""
+
+	if err := state.CreateSystemPromptDir(); err != nil {
+		return nil, err
+	}
+
+	var filenames []string
+	if state.Template != nil {
+		templateFiles, err := state.WriteTemplateFiles()
+		if err != nil {
+			return nil, err
+		}
+		filenames = append(filenames, templateFiles...)
+	}
+	if len(state.Drafts) > 0 {
+		draftFiles, err := state.WriteDraftFiles()
+		if err != nil {
+			return nil, err
+		}
+		filenames = append(filenames, draftFiles...)
+	}
+
+	var importedFiles []string
+	var err error
+	switch {
+	case state.DetectShared && len(state.SelectedFiles) > 0:
+		importedFiles, err = state.WriteSharedFiles()
+	case state.SplitByHeading && len(state.SelectedFiles) > 0:
+		importedFiles, err = state.WriteSplitFiles()
+	case (state.Merge || state.Template != nil || len(state.Drafts) > 0) && len(state.SelectedFiles) == 0:
+		// 取り込むファイルがない場合は既存のプロンプトファイル、テンプレートのファイル、下書きのファイルのみを使用する
+	default:
+		var filename string
+		filename, err = state.WriteDefaultFile()
+		importedFiles = []string{filename}
+	}
+	if err != nil {
+		return nil, err
+	}
+	filenames = append(filenames, importedFiles...)
+
+	settingsPath := config.FindSettingsFile(filepath.Join(state.SystemPromptDir, "settings.toml"))
+	if _, err := os.Stat(settingsPath); state.Merge && err == nil {
+		if err := state.MergeSettingsFile(settingsPath); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := state.WriteSettingsFile(); err != nil {
+			return nil, err
+		}
+		filenames = append(filenames, "settings.toml")
+	}
+
+	var created []string
+	for _, filename := range filenames {
+		created = append(created, state.relativePath(filepath.Join(state.SystemPromptDir, filename)))
+	}
+	return created, nil
+}
+
+// PlannedFiles は Apply で書き込むファイルのパスを、ファイルを書き込まずに WorkDir からの相対パスで返す
+func (state *InitState) PlannedFiles() ([]string, error) {
+	var names []string
+	if state.Template != nil {
+		files, err := state.Template.Render(DetectTemplateVars(state.WorkDir))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if file.Name != "settings.toml" {
+				names = append(names, file.Name)
+			}
+		}
+	}
+	for _, file := range state.Drafts {
+		names = append(names, file.Name)
+	}
+
+	switch {
+	case state.DetectShared && len(state.SelectedFiles) > 0:
+		plan, err := PlanSharedFiles(state.SelectedFiles, knownToolNames())
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range plan.Files {
+			names = append(names, file.Name)
+		}
+	case state.SplitByHeading && len(state.SelectedFiles) > 0:
+		splitFiles, _ := PlanSplitFiles(state.SelectedFiles)
+		for _, file := range splitFiles {
+			names = append(names, file.Name)
+		}
+	case (state.Merge || state.Template != nil || len(state.Drafts) > 0) && len(state.SelectedFiles) == 0:
+		// Apply と同様に空の 001_default.md は作成しない
+	default:
+		names = append(names, "001_default.md")
+	}
+
+	// promptFileName と同じ名前を付けるため、先に書き込むファイルの名前を予約する
+	reserved := make(map[string]bool)
+	var planned []string
+	for _, name := range names {
+		filename := name
+		if state.Merge {
+			ext := filepath.Ext(name)
+			for i := 2; reserved[filename] || fileExists(filepath.Join(state.SystemPromptDir, filename)); i++ {
+				filename = fmt.Sprintf("%s-%d%s", strings.TrimSuffix(name, ext), i, ext)
+			}
+		} else if reserved[filename] {
+			continue
+		}
+		reserved[filename] = true
+		planned = append(planned, state.relativePath(filepath.Join(state.SystemPromptDir, filename)))
+	}
+
+	settingsPath := config.FindSettingsFile(filepath.Join(state.SystemPromptDir, "settings.toml"))
+	if !state.Merge || !fileExists(settingsPath) {
+		settingsPath = filepath.Join(state.SystemPromptDir, "settings.toml")
+	}
+	planned = append(planned, state.relativePath(settingsPath))
+	return planned, nil
+}
+
+// WriteSplitFiles は選択されたファイルを見出しごとに分割して書き込み、作成したファイル名を返す。
+// 重複して取り込まなかったセクションは DuplicateSections に記録する。
+func (state *InitState) WriteSplitFiles() ([]string, error) {
+	splitFiles, duplicates := PlanSplitFiles(state.SelectedFiles)
+	state.DuplicateSections = duplicates
+
+	var filenames []string
+	for _, file := range splitFiles {
+		filename := state.promptFileName(file.Name)
+		if err := state.writeFile(filename, file.Content+"\n"); err != nil {
+			return nil, err
+		}
+		filenames = append(filenames, filename)
+	}
+	return filenames, nil
+}
+
+// WriteSharedFiles は選択されたファイルの共通の内容とツール固有の内容を別のファイルに書き込み、作成したファイル名を返す。
+// 各ツールに含めないファイルは ToolExcludes に記録され、settings.toml の exclude に書き込まれる。
+func (state *InitState) WriteSharedFiles() ([]string, error) {
+	plan, err := PlanSharedFiles(state.SelectedFiles, knownToolNames())
+	if err != nil {
+		return nil, err
+	}
+	renamed := make(map[string]string)
+	var filenames []string
+	for _, file := range plan.Files {
+		filename := state.promptFileName(file.Name)
+		if err := state.writeFile(filename, file.Content+"\n"); err != nil {
+			return nil, err
+		}
+		renamed[file.Name] = filename
+		filenames = append(filenames, filename)
+	}
+
+	state.ToolExcludes = make(map[string][]string)
+	for tool, excludes := range plan.Excludes {
+		for _, exclude := range excludes {
+			state.ToolExcludes[tool] = append(state.ToolExcludes[tool], renamed[exclude])
+		}
+	}
+	return filenames, nil
+}
+
+// WriteTemplateFiles は Template のプロンプトファイルをプロジェクトの情報で展開して書き込み、作成したファイル名を返す。
+// テンプレートの settings.toml は WriteSettingsFile で使用する。
+func (state *InitState) WriteTemplateFiles() ([]string, error) {
+	files, err := state.Template.Render(DetectTemplateVars(state.WorkDir))
+	if err != nil {
+		return nil, err
+	}
+
+	var filenames []string
+	for _, file := range files {
+		if file.Name == "settings.toml" {
+			state.templateSettings = file.Content
+			continue
+		}
+		filename := state.promptFileName(file.Name)
+		if err := state.writeFile(filename, file.Content); err != nil {
+			return nil, err
+		}
+		filenames = append(filenames, filename)
+	}
+	return filenames, nil
+}
+
+// WriteDraftFiles は Drafts のファイルを書き込み、作成したファイル名を返す
+func (state *InitState) WriteDraftFiles() ([]string, error) {
+	var filenames []string
+	for _, file := range state.Drafts {
+		filename := state.promptFileName(file.Name)
+		if err := state.writeFile(filename, file.Content); err != nil {
+			return nil, err
+		}
+		filenames = append(filenames, filename)
+	}
+	return filenames, nil
 }
 
-// WriteSettingsFile は settings.toml ファイルを生成する
+// WriteSettingsFile は settings.toml ファイルを生成する。
+// テンプレートの settings.toml がある場合はそれを使用し、選択されたツールの生成を有効にして、
+// 選択されなかったツールの生成を無効にする。
 func (state *InitState) WriteSettingsFile() error {
-	content := state.generateSettingsContent()
-	return state.writeFile("settings.toml", content)
+	if state.templateSettings == "" {
+		content := state.generateSettingsContent()
+		return state.writeFile("settings.toml", content)
+	}
+
+	if err := state.writeFile("settings.toml", state.templateSettings); err != nil {
+		return err
+	}
+	settingsPath := filepath.Join(state.SystemPromptDir, "settings.toml")
+	if err := state.disableUnselectedTools(settingsPath); err != nil {
+		return err
+	}
+	_, err := state.editSettingsFile(settingsPath, false)
+	return err
+}
+
+// disableUnselectedTools は settingsPath で生成が有効なツールのうち、選択されなかったツールの生成を無効にする。
+// SelectedTools が nil の場合は何もしない。
+func (state *InitState) disableUnselectedTools(settingsPath string) error {
+	if state.SelectedTools == nil {
+		return nil
+	}
+
+	infos, err := config.ListTools(settingsPath)
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", filepath.Base(settingsPath), err)
+	}
+	for _, info := range infos {
+		custom := slices.ContainsFunc(state.CustomTools, func(tool CustomTool) bool { return tool.Name == info.Name })
+		if !info.Generate || custom || slices.Contains(state.SelectedTools, info.Name) {
+			continue
+		}
+
+		result, err := config.SetToolGenerate(settingsPath, info.Name, false)
+		if err != nil {
+			return fmt.Errorf("failed to update %s: %w", filepath.Base(settingsPath), err)
+		}
+		if err := os.WriteFile(settingsPath, result.Content, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", filepath.Base(settingsPath), err)
+		}
+	}
+	return nil
+}
+
+// MergeSettingsFile は既存の設定ファイル settingsPath で選択されたツールの生成を有効にし、
+// ToolExcludes を各ツールの exclude に追加する。コメントや書式は保持する。
+func (state *InitState) MergeSettingsFile(settingsPath string) error {
+	changed, err := state.editSettingsFile(settingsPath, true)
+	if err != nil {
+		return err
+	}
+	if changed {
+		state.UpdatedFiles = append(state.UpdatedFiles, state.relativePath(settingsPath))
+	}
+	return nil
+}
+
+// editSettingsFile は settingsPath で選択されたツールの生成を有効にし、ToolExcludes を exclude に追加する。
+// backup が true の場合は書き換える前の内容をバックアップする。
+func (state *InitState) editSettingsFile(settingsPath string, backup bool) (bool, error) {
+	var edits []func() (*config.SettingsEdit, error)
+	for _, tool := range state.SelectedTools {
+		edits = append(edits, func() (*config.SettingsEdit, error) {
+			return config.SetToolGenerate(settingsPath, tool, true)
+		})
+	}
+	for _, tool := range state.CustomTools {
+		edits = append(edits, func() (*config.SettingsEdit, error) {
+			return addOrEnableTool(settingsPath, tool)
+		})
+	}
+	for _, tool := range knownToolNames() {
+		for _, exclude := range state.ToolExcludes[tool] {
+			edits = append(edits, func() (*config.SettingsEdit, error) {
+				return config.AddToolExclude(settingsPath, tool, exclude)
+			})
+		}
+	}
+
+	changed := false
+	for _, edit := range edits {
+		result, err := edit()
+		if err != nil {
+			return false, fmt.Errorf("failed to update %s: %w", filepath.Base(settingsPath), err)
+		}
+		if !result.Changed {
+			continue
+		}
+
+		if backup {
+			err = state.writeFile(filepath.Base(settingsPath), string(result.Content))
+		} else if err = os.WriteFile(settingsPath, result.Content, 0644); err != nil {
+			err = fmt.Errorf("failed to write %s: %w", filepath.Base(settingsPath), err)
+		}
+		if err != nil {
+			return false, err
+		}
+		changed = true
+	}
+	return changed, nil
+}
+
+// addOrEnableTool は settingsPath に tool を追加する。既に定義されている場合は生成を有効にする
+func addOrEnableTool(settingsPath string, tool CustomTool) (*config.SettingsEdit, error) {
+	infos, err := config.ListTools(settingsPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, info := range infos {
+		if info.Name == tool.Name && info.Defined {
+			return config.SetToolGenerate(settingsPath, tool.Name, true)
+		}
+	}
+	return config.AddTool(settingsPath, tool.Name, tool.AIToolPaths)
+}
+
+// promptFileName は Merge の場合、既存のファイルと重ならないよう name に連番を付けた名前を返す
+func (state *InitState) promptFileName(name string) string {
+	if !state.Merge {
+		return name
+	}
+
+	ext := filepath.Ext(name)
+	filename := name
+	for i := 2; ; i++ {
+		if _, err := os.Stat(filepath.Join(state.SystemPromptDir, filename)); os.IsNotExist(err) {
+			return filename
+		}
+		filename = fmt.Sprintf("%s-%d%s", strings.TrimSuffix(name, ext), i, ext)
+	}
 }
 
+// writeFile は SystemPromptDir に filename を書き込む。既存のファイルは置き換える前にバックアップする
 func (state *InitState) writeFile(filename, content string) error {
+	if err := state.backupFile(filename); err != nil {
+		return err
+	}
+
 	filePath := filepath.Join(state.SystemPromptDir, filename)
 	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
 		return fmt.Errorf("failed to write %s: %w", filename, err)
@@ -95,8 +482,73 @@ func (state *InitState) writeFile(filename, content string) error {
 	return nil
 }
 
+// backupFile は SystemPromptDir の filename が存在する場合、BackupDir にコピーする。
+// 同じファイルを複数回書き換える場合は、最初の内容のみを保存する。
+func (state *InitState) backupFile(filename string) error {
+	path := filepath.Join(state.SystemPromptDir, filename)
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to back up %s: %w", filename, err)
+	}
+
+	if state.BackupDir == "" {
+		dir, err := state.createBackupDir()
+		if err != nil {
+			return err
+		}
+		state.BackupDir = dir
+	}
+
+	backupPath := filepath.Join(state.BackupDir, filename)
+	if _, err := os.Stat(backupPath); err == nil {
+		return nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to back up %s: %w", filename, err)
+	}
+	if err := os.WriteFile(backupPath, content, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to back up %s: %w", filename, err)
+	}
+	return nil
+}
+
+// createBackupDir は .system_prompt/.backup/<日時> のディレクトリを作成する。
+// 同じ日時のディレクトリがある場合は連番を付ける。
+func (state *InitState) createBackupDir() (string, error) {
+	base := filepath.Join(state.SystemPromptDir, config.BackupDirName, time.Now().Format("20060102-150405"))
+	if err := os.MkdirAll(filepath.Dir(base), 0755); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	dir := base
+	for i := 2; ; i++ {
+		err := os.Mkdir(dir, 0755)
+		if err == nil {
+			return dir, nil
+		}
+		if !os.IsExist(err) {
+			return "", fmt.Errorf("failed to create backup directory: %w", err)
+		}
+		dir = fmt.Sprintf("%s-%d", base, i)
+	}
+}
+
+func (state *InitState) relativePath(path string) string {
+	relPath, err := filepath.Rel(state.WorkDir, path)
+	if err != nil {
+		return path
+	}
+	return relPath
+}
+
 func (state *InitState) generateSettingsContent() string {
-	content := "[app]\n"
+	content := fmt.Sprintf("version = %d\n\n", config.CurrentVersion)
+	content += "[app]\n"
 	content += "# header = \"Custom header content\"\n"
 	content += "# footer = \"Custom footer content\"\n\n"
 
@@ -106,14 +558,7 @@ func (state *InitState) generateSettingsContent() string {
 		selectedToolsMap[tool] = true
 	}
 
-	// DefaultKnownToolFileNamesからツール名を取得してソート
-	var allTools []string
-	for tool := range config.DefaultKnownToolFileNames {
-		allTools = append(allTools, tool)
-	}
-	sort.Strings(allTools)
-
-	for _, tool := range allTools {
+	for _, tool := range knownToolNames() {
 		generate := selectedToolsMap[tool]
 		content += fmt.Sprintf("[tools.%s]\n", tool)
 		content += fmt.Sprintf("generate = %t\n", generate)
@@ -123,12 +568,37 @@ func (state *InitState) generateSettingsContent() string {
 			content += "# dir_name = \".github\"  # GitHub Copilot uses .github directory\n"
 			content += "# file_name = \"copilot-instructions.md\"\n"
 		}
-		content += "# exclude = [\"temp*.md\"]\n\n"
+		if excludes := state.ToolExcludes[tool]; len(excludes) > 0 {
+			var quoted []string
+			for _, exclude := range excludes {
+				quoted = append(quoted, strconv.Quote(exclude))
+			}
+			content += fmt.Sprintf("exclude = [%s]\n\n", strings.Join(quoted, ", "))
+		} else {
+			content += "# exclude = [\"temp*.md\"]\n\n"
+		}
+	}
+
+	for _, tool := range state.CustomTools {
+		content += fmt.Sprintf("[tools.%s]\n", tool.Name)
+		content += "generate = true\n"
+		content += fmt.Sprintf("dir_name = %s\n", strconv.Quote(string(tool.DirName)))
+		content += fmt.Sprintf("file_name = %s\n\n", strconv.Quote(string(tool.FileName)))
 	}
 
 	return content
 }
 
+// knownToolNames は DefaultKnownToolFileNames のツール名を名前順に返す
+func knownToolNames() []string {
+	var names []string
+	for name := range config.DefaultKnownToolFileNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // RunInit はinit処理のエントリーポイント
 func RunInit() error {
 	state, err := NewInitState()
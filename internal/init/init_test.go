@@ -7,6 +7,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/cateiru/system-prompt-gen/internal/config"
 )
 
 func TestNewInitState(t *testing.T) {
@@ -75,7 +77,7 @@ func TestWriteDefaultFile(t *testing.T) {
 		},
 	}
 
-	err = state.WriteDefaultFile()
+	_, err = state.WriteDefaultFile()
 	require.NoError(t, err)
 
 	// ファイルが作成されたことを確認
@@ -102,7 +104,7 @@ func TestWriteDefaultFileEmpty(t *testing.T) {
 		SelectedFiles:   []ExistingFile{}, // 空のファイルリスト
 	}
 
-	err = state.WriteDefaultFile()
+	_, err = state.WriteDefaultFile()
 	require.NoError(t, err)
 
 	// 空のファイルが作成されたことを確認
@@ -165,3 +167,70 @@ generate = true`
 generate = false`
 	assert.Contains(t, content, clineSection)
 }
+
+func TestGenerateSettingsContentCustomTools(t *testing.T) {
+	state := &InitState{
+		SelectedTools: []string{"claude"},
+		CustomTools: []CustomTool{
+			{Name: "zed", AIToolPaths: config.AIToolPaths{DirName: ".zed", FileName: "rules.md"}},
+		},
+	}
+
+	content := state.generateSettingsContent()
+
+	assert.Contains(t, content, "[tools.zed]\ngenerate = true\ndir_name = \".zed\"\nfile_name = \"rules.md\"\n")
+}
+
+func TestMergeSettingsFileCustomTools(t *testing.T) {
+	state := newTestInitState(t)
+	require.NoError(t, state.CreateSystemPromptDir())
+	settingsPath := filepath.Join(state.SystemPromptDir, "settings.toml")
+	require.NoError(t, os.WriteFile(settingsPath, []byte("version = 2\n\n[tools.zed]\ngenerate = false\nfile_name = \"rules.md\"\n"), 0644))
+
+	state.CustomTools = []CustomTool{
+		{Name: "zed", AIToolPaths: config.AIToolPaths{FileName: "rules.md"}},
+		{Name: "aider", AIToolPaths: config.AIToolPaths{FileName: "CONVENTIONS.md"}},
+	}
+	require.NoError(t, state.MergeSettingsFile(settingsPath))
+
+	infos, err := config.ListTools(settingsPath)
+	require.NoError(t, err)
+	generate := make(map[string]bool)
+	for _, info := range infos {
+		generate[info.Name] = info.Generate
+	}
+	assert.True(t, generate["zed"])
+	assert.True(t, generate["aider"])
+}
+
+func TestPlannedFiles(t *testing.T) {
+	t.Run("split", func(t *testing.T) {
+		state := newTestInitState(t)
+		state.SplitByHeading = true
+		state.SelectedFiles = []ExistingFile{{Path: "CLAUDE.md", ToolName: "claude", Content: "## Style\n\nTabs\n\n## Tests\n\nRun them"}}
+		state.Drafts = []TemplateFile{{Name: "520_directory-layout.md", Content: "## Directory Layout\n"}}
+
+		planned, err := state.PlannedFiles()
+		require.NoError(t, err)
+
+		created, err := state.Apply()
+		require.NoError(t, err)
+		assert.Equal(t, created, planned)
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		state := newTestInitState(t)
+		require.NoError(t, state.CreateSystemPromptDir())
+		require.NoError(t, os.WriteFile(filepath.Join(state.SystemPromptDir, "001_default.md"), []byte("Existing\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(state.SystemPromptDir, "settings.toml"), []byte("version = 2\n"), 0644))
+		state.Merge = true
+		state.SelectedFiles = []ExistingFile{{Path: "CLAUDE.md", ToolName: "claude", Content: "Claude"}}
+
+		planned, err := state.PlannedFiles()
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			filepath.Join(".system_prompt", "001_default-2.md"),
+			filepath.Join(".system_prompt", "settings.toml"),
+		}, planned)
+	})
+}
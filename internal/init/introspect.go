@@ -0,0 +1,388 @@
+package init
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxCIRunCommands はドラフトに含める CI のコマンドの最大数
+const maxCIRunCommands = 10
+
+// Command はプロジェクトで使用するコマンド
+type Command struct {
+	// Kind は build、test、lint のいずれか
+	Kind string
+	Run  string
+	// Source はコマンドを検出したファイル（例: Makefile、package.json）
+	Source string
+}
+
+// CIWorkflow は検出した CI の設定
+type CIWorkflow struct {
+	// Path は WorkDir からの相対パス
+	Path string
+	Name string
+	// Commands は run で実行されるコマンド（複数行の場合は最初の行）
+	Commands []string
+}
+
+// ProjectAnalysis は init で下書きのプロンプトファイルを作成するために収集したプロジェクトの情報
+type ProjectAnalysis struct {
+	// Languages は検出した言語（例: "Go 1.24"）
+	Languages []string
+	// ModuleName は go.mod のモジュール名、または package.json の name
+	ModuleName string
+	Commands   []Command
+	// MakeTargets は build/test/lint に分類できなかった Makefile のターゲット
+	MakeTargets []string
+	CI          []CIWorkflow
+	// Directories は最上位のディレクトリ（隠しディレクトリなどを除く）
+	Directories []string
+}
+
+// directoryDescriptions はよく使われるディレクトリの説明
+var directoryDescriptions = map[string]string{
+	"api":      "API definitions",
+	"app":      "application code",
+	"apps":     "applications",
+	"bin":      "scripts and binaries",
+	"build":    "build configuration and output",
+	"cmd":      "command entry points",
+	"config":   "configuration",
+	"configs":  "configuration",
+	"deploy":   "deployment configuration",
+	"docs":     "documentation",
+	"examples": "examples",
+	"internal": "private packages",
+	"lib":      "library code",
+	"packages": "workspace packages",
+	"pkg":      "public packages",
+	"public":   "static assets",
+	"scripts":  "development scripts",
+	"src":      "source code",
+	"test":     "tests",
+	"tests":    "tests",
+	"testdata": "test fixtures",
+	"tools":    "development tools",
+	"web":      "web frontend",
+}
+
+// lockFiles はパッケージマネージャーを判定するロックファイル。複数ある場合は先に一致したものを使用する
+var lockFiles = []struct {
+	name    string
+	manager string
+}{
+	{name: "pnpm-lock.yaml", manager: "pnpm"},
+	{name: "yarn.lock", manager: "yarn"},
+	{name: "bun.lock", manager: "bun"},
+	{name: "bun.lockb", manager: "bun"},
+	{name: "package-lock.json", manager: "npm"},
+}
+
+var makeTargetPattern = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9_.-]*)\s*:([^=]|$)`)
+
+// AnalyzeProject は workDir の go.mod、package.json、Cargo.toml、pyproject.toml、Makefile、CI の設定、
+// ディレクトリ構成を調べる。読み込めないファイルは無視する。
+func AnalyzeProject(workDir string) *ProjectAnalysis {
+	analysis := &ProjectAnalysis{}
+
+	vars := DetectTemplateVars(workDir)
+	if vars.ModuleName != "" {
+		language := "Go"
+		if vars.GoVersion != "" {
+			language += " " + vars.GoVersion
+		}
+		analysis.Languages = append(analysis.Languages, language)
+		analysis.ModuleName = vars.ModuleName
+		analysis.Commands = append(analysis.Commands,
+			Command{Kind: "build", Run: "go build ./...", Source: "go.mod"},
+			Command{Kind: "test", Run: "go test ./...", Source: "go.mod"},
+			Command{Kind: "lint", Run: "go vet ./...", Source: "go.mod"},
+		)
+		for _, name := range []string{".golangci.yml", ".golangci.yaml", ".golangci.toml"} {
+			if fileExists(filepath.Join(workDir, name)) {
+				analysis.Commands = append(analysis.Commands, Command{Kind: "lint", Run: "golangci-lint run", Source: name})
+				break
+			}
+		}
+	}
+
+	analysis.analyzePackageJSON(workDir)
+
+	if fileExists(filepath.Join(workDir, "Cargo.toml")) {
+		analysis.Languages = append(analysis.Languages, "Rust")
+		analysis.Commands = append(analysis.Commands,
+			Command{Kind: "build", Run: "cargo build", Source: "Cargo.toml"},
+			Command{Kind: "test", Run: "cargo test", Source: "Cargo.toml"},
+			Command{Kind: "lint", Run: "cargo clippy", Source: "Cargo.toml"},
+		)
+	}
+	if fileExists(filepath.Join(workDir, "pyproject.toml")) || fileExists(filepath.Join(workDir, "requirements.txt")) {
+		analysis.Languages = append(analysis.Languages, "Python")
+		analysis.Commands = append(analysis.Commands, Command{Kind: "test", Run: "pytest", Source: "Python project"})
+	}
+
+	analysis.analyzeMakefile(workDir)
+	analysis.analyzeCI(workDir)
+	analysis.analyzeDirectories(workDir)
+
+	return analysis
+}
+
+func (analysis *ProjectAnalysis) analyzePackageJSON(workDir string) {
+	content, err := os.ReadFile(filepath.Join(workDir, "package.json"))
+	if err != nil {
+		return
+	}
+	var pkg struct {
+		Name    string            `json:"name"`
+		Scripts map[string]string `json:"scripts"`
+	}
+	if json.Unmarshal(content, &pkg) != nil {
+		return
+	}
+
+	language := "JavaScript"
+	if fileExists(filepath.Join(workDir, "tsconfig.json")) {
+		language = "TypeScript"
+	}
+	analysis.Languages = append(analysis.Languages, language)
+	if analysis.ModuleName == "" {
+		analysis.ModuleName = pkg.Name
+	}
+
+	manager := "npm"
+	for _, lockFile := range lockFiles {
+		if fileExists(filepath.Join(workDir, lockFile.name)) {
+			manager = lockFile.manager
+			break
+		}
+	}
+
+	var scripts []string
+	for name := range pkg.Scripts {
+		scripts = append(scripts, name)
+	}
+	sort.Strings(scripts)
+	for _, name := range scripts {
+		kind := commandKind(name)
+		if kind == "" {
+			continue
+		}
+		run := fmt.Sprintf("%s run %s", manager, name)
+		if name == "test" && manager != "bun" {
+			run = manager + " test"
+		}
+		analysis.Commands = append(analysis.Commands, Command{Kind: kind, Run: run, Source: "package.json"})
+	}
+}
+
+func (analysis *ProjectAnalysis) analyzeMakefile(workDir string) {
+	content, err := os.ReadFile(filepath.Join(workDir, "Makefile"))
+	if err != nil {
+		return
+	}
+
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(string(content), "\n") {
+		match := makeTargetPattern.FindStringSubmatch(line)
+		if match == nil || seen[match[1]] {
+			continue
+		}
+		target := match[1]
+		seen[target] = true
+
+		if kind := commandKind(target); kind != "" {
+			analysis.Commands = append(analysis.Commands, Command{Kind: kind, Run: "make " + target, Source: "Makefile"})
+		} else {
+			analysis.MakeTargets = append(analysis.MakeTargets, target)
+		}
+	}
+}
+
+func (analysis *ProjectAnalysis) analyzeCI(workDir string) {
+	var paths []string
+	for _, pattern := range []string{".github/workflows/*.yml", ".github/workflows/*.yaml"} {
+		matches, _ := filepath.Glob(filepath.Join(workDir, filepath.FromSlash(pattern)))
+		paths = append(paths, matches...)
+	}
+	for _, name := range []string{".gitlab-ci.yml", filepath.Join(".circleci", "config.yml")} {
+		if fileExists(filepath.Join(workDir, name)) {
+			paths = append(paths, filepath.Join(workDir, name))
+		}
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var doc any
+		if yaml.Unmarshal(content, &doc) != nil {
+			continue
+		}
+
+		relPath, err := filepath.Rel(workDir, path)
+		if err != nil {
+			relPath = path
+		}
+		workflow := CIWorkflow{Path: relPath}
+		if root, ok := doc.(map[string]any); ok {
+			workflow.Name, _ = root["name"].(string)
+		}
+		collectRunCommands(doc, &workflow.Commands)
+		analysis.CI = append(analysis.CI, workflow)
+	}
+}
+
+// collectRunCommands は CI の設定から run（GitHub Actions、CircleCI）と script（GitLab CI）のコマンドを収集する
+func collectRunCommands(node any, commands *[]string) {
+	add := func(command string) {
+		command = strings.TrimSpace(strings.SplitN(strings.TrimSpace(command), "\n", 2)[0])
+		if command != "" && !slices.Contains(*commands, command) {
+			*commands = append(*commands, command)
+		}
+	}
+
+	switch value := node.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			switch child := value[key].(type) {
+			case string:
+				if key == "run" || key == "script" || key == "command" {
+					add(child)
+				}
+			case []any:
+				if key == "script" {
+					for _, line := range child {
+						if command, ok := line.(string); ok {
+							add(command)
+						}
+					}
+					continue
+				}
+				collectRunCommands(child, commands)
+			default:
+				collectRunCommands(child, commands)
+			}
+		}
+	case []any:
+		for _, child := range value {
+			collectRunCommands(child, commands)
+		}
+	}
+}
+
+func (analysis *ProjectAnalysis) analyzeDirectories(workDir string) {
+	entries, err := os.ReadDir(workDir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") && !skipScanDirs[entry.Name()] {
+			analysis.Directories = append(analysis.Directories, entry.Name())
+		}
+	}
+}
+
+// commandKind はスクリプトや Makefile のターゲットの名前から build、test、lint を判定する
+func commandKind(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.Contains(lower, "test"):
+		return "test"
+	case slices.Contains([]string{"lint", "vet", "fmt", "format", "check", "typecheck"}, lower) || strings.HasPrefix(lower, "lint"):
+		return "lint"
+	case lower == "build" || strings.HasPrefix(lower, "build"):
+		return "build"
+	default:
+		return ""
+	}
+}
+
+// Drafts は収集した情報からプロンプトファイルの下書きを作成する。
+// テンプレートのファイル（010_ から）と混ざらないよう、下書きのファイル名は 500_ から始める。
+func (analysis *ProjectAnalysis) Drafts() []TemplateFile {
+	var drafts []TemplateFile
+
+	if len(analysis.Languages) > 0 {
+		var b strings.Builder
+		b.WriteString("## Project Overview\n\n")
+		fmt.Fprintf(&b, "- Languages: %s\n", strings.Join(analysis.Languages, ", "))
+		if analysis.ModuleName != "" {
+			fmt.Fprintf(&b, "- Module: `%s`\n", analysis.ModuleName)
+		}
+		drafts = append(drafts, TemplateFile{Name: "500_project-overview.md", Content: b.String()})
+	}
+
+	if len(analysis.Commands) > 0 || len(analysis.MakeTargets) > 0 || len(analysis.CI) > 0 {
+		var b strings.Builder
+		b.WriteString("## Build, Test and Lint\n")
+		for _, kind := range []string{"build", "test", "lint"} {
+			var lines []string
+			for _, command := range analysis.Commands {
+				if command.Kind == kind {
+					lines = append(lines, fmt.Sprintf("- `%s` (%s)", command.Run, command.Source))
+				}
+			}
+			if len(lines) > 0 {
+				fmt.Fprintf(&b, "\n%s%s:\n\n%s\n", strings.ToUpper(kind[:1]), kind[1:], strings.Join(lines, "\n"))
+			}
+		}
+		if len(analysis.MakeTargets) > 0 {
+			var targets []string
+			for _, target := range analysis.MakeTargets {
+				targets = append(targets, fmt.Sprintf("`%s`", target))
+			}
+			fmt.Fprintf(&b, "\nOther Makefile targets: %s\n", strings.Join(targets, ", "))
+		}
+		if len(analysis.CI) > 0 {
+			b.WriteString("\nCI runs these commands, so make sure they pass before pushing:\n\n")
+			count := 0
+			for _, workflow := range analysis.CI {
+				for _, command := range workflow.Commands {
+					if count == maxCIRunCommands {
+						break
+					}
+					fmt.Fprintf(&b, "- `%s` (%s)\n", command, filepath.ToSlash(workflow.Path))
+					count++
+				}
+			}
+		}
+		drafts = append(drafts, TemplateFile{Name: "510_commands.md", Content: b.String()})
+	}
+
+	if len(analysis.Directories) > 0 {
+		var b strings.Builder
+		b.WriteString("## Directory Layout\n\n")
+		for _, dir := range analysis.Directories {
+			if description, ok := directoryDescriptions[dir]; ok {
+				fmt.Fprintf(&b, "- `%s/`: %s\n", dir, description)
+			} else {
+				fmt.Fprintf(&b, "- `%s/`\n", dir)
+			}
+		}
+		drafts = append(drafts, TemplateFile{Name: "520_directory-layout.md", Content: b.String()})
+	}
+
+	return drafts
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,189 @@
+package init
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cateiru/system-prompt-gen/internal/testutil"
+)
+
+func writeProjectFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+func TestAnalyzeProject(t *testing.T) {
+	dir := t.TempDir()
+	writeProjectFiles(t, dir, map[string]string{
+		"go.mod":        "module example.com/app\n\ngo 1.24\n",
+		".golangci.yml": "linters: {}\n",
+		"Makefile":      "VERSION := 1.0\n\n.PHONY: build\nbuild:\n\tgo build ./...\n\ntest-unit: build\n\tgo test ./...\n\nlint:\n\tgolangci-lint run\n\nrelease:\n\t./release.sh\n",
+		".github/workflows/ci.yml": `name: CI
+on: push
+jobs:
+  test:
+    steps:
+      - uses: actions/checkout@v4
+      - run: make build
+      - run: |
+          go test ./...
+          echo done
+`,
+		"cmd/main.go":         "package main\n",
+		"internal/app/app.go": "package app\n",
+		"assets/logo.svg":     "",
+		"node_modules/x/a.js": "",
+	})
+
+	analysis := AnalyzeProject(dir)
+
+	assert.Equal(t, []string{"Go 1.24"}, analysis.Languages)
+	assert.Equal(t, "example.com/app", analysis.ModuleName)
+	assert.Equal(t, []Command{
+		{Kind: "build", Run: "go build ./...", Source: "go.mod"},
+		{Kind: "test", Run: "go test ./...", Source: "go.mod"},
+		{Kind: "lint", Run: "go vet ./...", Source: "go.mod"},
+		{Kind: "lint", Run: "golangci-lint run", Source: ".golangci.yml"},
+		{Kind: "build", Run: "make build", Source: "Makefile"},
+		{Kind: "test", Run: "make test-unit", Source: "Makefile"},
+		{Kind: "lint", Run: "make lint", Source: "Makefile"},
+	}, analysis.Commands)
+	assert.Equal(t, []string{"release"}, analysis.MakeTargets)
+	assert.Equal(t, []CIWorkflow{
+		{Path: filepath.Join(".github", "workflows", "ci.yml"), Name: "CI", Commands: []string{"make build", "go test ./..."}},
+	}, analysis.CI)
+	assert.Equal(t, []string{"assets", "cmd", "internal"}, analysis.Directories)
+
+	drafts := analysis.Drafts()
+	require.Len(t, drafts, 3)
+	assert.Equal(t, "500_project-overview.md", drafts[0].Name)
+	assert.Contains(t, drafts[0].Content, "- Module: `example.com/app`\n")
+	assert.Equal(t, "510_commands.md", drafts[1].Name)
+	assert.Contains(t, drafts[1].Content, "Test:\n\n- `go test ./...` (go.mod)\n- `make test-unit` (Makefile)\n")
+	assert.Contains(t, drafts[1].Content, "Other Makefile targets: `release`\n")
+	assert.Contains(t, drafts[1].Content, "- `make build` (.github/workflows/ci.yml)\n")
+	assert.Equal(t, "520_directory-layout.md", drafts[2].Name)
+	assert.Equal(t, "## Directory Layout\n\n- `assets/`\n- `cmd/`: command entry points\n- `internal/`: private packages\n", drafts[2].Content)
+}
+
+func TestAnalyzeProjectPackageJSON(t *testing.T) {
+	dir := t.TempDir()
+	writeProjectFiles(t, dir, map[string]string{
+		"package.json":   `{"name": "web", "scripts": {"build": "vite build", "dev": "vite", "lint": "eslint .", "test": "vitest"}}`,
+		"tsconfig.json":  "{}",
+		"pnpm-lock.yaml": "",
+		".gitlab-ci.yml": "test:\n  script:\n    - pnpm install\n    - pnpm test\n",
+	})
+
+	analysis := AnalyzeProject(dir)
+
+	assert.Equal(t, []string{"TypeScript"}, analysis.Languages)
+	assert.Equal(t, "web", analysis.ModuleName)
+	assert.Equal(t, []Command{
+		{Kind: "build", Run: "pnpm run build", Source: "package.json"},
+		{Kind: "lint", Run: "pnpm run lint", Source: "package.json"},
+		{Kind: "test", Run: "pnpm test", Source: "package.json"},
+	}, analysis.Commands)
+	assert.Equal(t, []CIWorkflow{
+		{Path: ".gitlab-ci.yml", Commands: []string{"pnpm install", "pnpm test"}},
+	}, analysis.CI)
+}
+
+func TestAnalyzeProjectLockFiles(t *testing.T) {
+	tests := map[string]struct {
+		lockFiles []string
+		expected  string
+	}{
+		"none": {expected: "npm test"},
+		"yarn": {lockFiles: []string{"yarn.lock"}, expected: "yarn test"},
+		"bun":  {lockFiles: []string{"bun.lock"}, expected: "bun run test"},
+		"npm":  {lockFiles: []string{"package-lock.json"}, expected: "npm test"},
+		// 複数のロックファイルがある場合は常に同じものを選ぶ
+		"multiple":     {lockFiles: []string{"bun.lockb", "package-lock.json", "yarn.lock", "pnpm-lock.yaml"}, expected: "pnpm test"},
+		"yarn and npm": {lockFiles: []string{"package-lock.json", "yarn.lock"}, expected: "yarn test"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			files := map[string]string{"package.json": `{"scripts": {"test": "vitest"}}`}
+			for _, lockFile := range test.lockFiles {
+				files[lockFile] = ""
+			}
+			dir := t.TempDir()
+			writeProjectFiles(t, dir, files)
+
+			for range 5 {
+				analysis := AnalyzeProject(dir)
+				require.Len(t, analysis.Commands, 1)
+				assert.Equal(t, test.expected, analysis.Commands[0].Run)
+			}
+		})
+	}
+}
+
+func TestAnalyzeProjectEmpty(t *testing.T) {
+	analysis := AnalyzeProject(t.TempDir())
+
+	assert.Empty(t, analysis.Drafts())
+}
+
+func TestRunNonInteractiveAnalyze(t *testing.T) {
+	state := newTestInitState(t)
+	writeProjectFiles(t, state.WorkDir, map[string]string{
+		"go.mod":      "module example.com/app\n\ngo 1.24\n",
+		"cmd/main.go": "package main\n",
+	})
+
+	summary, err := state.runNonInteractive(Options{Tools: []string{"claude"}, Analyze: true})
+	require.NoError(t, err)
+
+	// 下書きを書き込む場合は空の 001_default.md を作成しない
+	assert.Equal(t, []string{
+		filepath.Join(".system_prompt", "500_project-overview.md"),
+		filepath.Join(".system_prompt", "510_commands.md"),
+		filepath.Join(".system_prompt", "520_directory-layout.md"),
+		filepath.Join(".system_prompt", "settings.toml"),
+	}, summary.CreatedFiles)
+
+	content, err := os.ReadFile(filepath.Join(state.SystemPromptDir, "510_commands.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "- `go build ./...` (go.mod)\n")
+}
+
+func TestRunNonInteractiveAnalyzeWithTemplateAndImport(t *testing.T) {
+	t.Run("template", func(t *testing.T) {
+		state := newTestInitState(t)
+		writeProjectFiles(t, state.WorkDir, map[string]string{"go.mod": "module example.com/app\n\ngo 1.24\n"})
+
+		summary, err := state.runNonInteractive(Options{Template: "go-service", Analyze: true})
+		require.NoError(t, err)
+
+		// 下書きはテンプレートのファイルの後に並ぶ
+		assert.Equal(t, []string{
+			filepath.Join(".system_prompt", "010_overview.md"),
+			filepath.Join(".system_prompt", "020_coding-style.md"),
+			filepath.Join(".system_prompt", "030_testing.md"),
+			filepath.Join(".system_prompt", "040_operations.md"),
+			filepath.Join(".system_prompt", "500_project-overview.md"),
+			filepath.Join(".system_prompt", "510_commands.md"),
+			filepath.Join(".system_prompt", "settings.toml"),
+		}, summary.CreatedFiles)
+	})
+
+	t.Run("import", func(t *testing.T) {
+		state := newTestInitState(t)
+		writeProjectFiles(t, state.WorkDir, map[string]string{"CLAUDE.md": "Claude rules\n"})
+
+		_, err := state.runNonInteractive(Options{Import: []string{"CLAUDE.md"}, Analyze: true})
+		assert.ErrorIs(t, err, ErrAnalyzeWithImport)
+		testutil.AssertFileNotExists(t, state.SystemPromptDir)
+	})
+}
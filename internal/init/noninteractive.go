@@ -0,0 +1,247 @@
+package init
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cateiru/system-prompt-gen/internal/config"
+)
+
+// ErrSystemPromptDirExists は Force または Merge を指定せずに既存の .system_prompt ディレクトリへ init しようとした
+var ErrSystemPromptDirExists = errors.New(".system_prompt directory already exists")
+
+// ErrAnalyzeWithImport は Analyze と Import を同時に指定した場合のエラー
+var ErrAnalyzeWithImport = errors.New("drafts from the project analysis are only created when no files are imported")
+
+// Options は非対話モードの init の指定
+type Options struct {
+	// Tools は生成を有効にするツール名（空の場合は全てのビルトインツール、Template がある場合はテンプレートの設定）。
+	// Template がある場合、テンプレートで有効なツールのうち Tools に含まれないものは無効にする
+	Tools []string
+	// Import は取り込むファイルのパス（作業ディレクトリからの相対パス）
+	Import []string
+	// Force が true の場合、既存の .system_prompt ディレクトリのファイルをバックアップして上書きする
+	Force bool
+	// Merge が true の場合、既存の .system_prompt ディレクトリのプロンプトファイルを残し、設定ファイルにツールを追加する
+	Merge bool
+	// Split が true の場合、取り込むファイルを見出しごとに番号付きのファイルへ分割する
+	Split bool
+	// Shared が true の場合、取り込むファイルの共通の内容とツール固有の内容を別のファイルに分ける
+	Shared bool
+	// Verify が true の場合、init の後に生成結果と元のファイルを比較する
+	Verify bool
+	// Template は使用するテンプレートの名前またはディレクトリのパス
+	Template string
+	// TemplateDir はユーザーのテンプレートを探すディレクトリ（空の場合は DefaultUserTemplateDir）
+	TemplateDir string
+	// Analyze が true の場合、プロジェクトのビルドシステムや CI の設定を解析してプロンプトファイルの下書きを書き込む。
+	// 下書きは既存のファイルを取り込まない場合のみ作成するため、Import とは同時に指定できない
+	Analyze bool
+}
+
+// Summary は init の結果
+type Summary struct {
+	// SystemPromptDir は作成した .system_prompt ディレクトリ（作業ディレクトリからの相対パス）
+	SystemPromptDir string `json:"system_prompt_dir"`
+	// CreatedFiles は作成したファイル（作業ディレクトリからの相対パス）
+	CreatedFiles []string `json:"created_files"`
+	// ImportedFiles は取り込んだファイル
+	ImportedFiles []string `json:"imported_files"`
+	// Tools は生成を有効にしたツール
+	Tools []string `json:"tools"`
+	// UpdatedFiles は Merge で書き換えた既存のファイル
+	UpdatedFiles []string `json:"updated_files"`
+	// BackupDir は置き換える前のファイルを保存したディレクトリ（バックアップしていない場合は空）
+	BackupDir string `json:"backup_dir,omitempty"`
+	// Overwritten は既存の .system_prompt ディレクトリを上書きしたかどうか
+	Overwritten bool `json:"overwritten"`
+	// Merged は既存の .system_prompt ディレクトリに追加したかどうか
+	Merged bool `json:"merged"`
+	// Template は使用したテンプレートの名前
+	Template string `json:"template,omitempty"`
+	// DuplicateSections は分割モードで重複として取り込まなかったセクション
+	DuplicateSections []DuplicateSection `json:"duplicate_sections"`
+	// Excludes は settings.toml に書き込んだツールごとの exclude
+	Excludes map[string][]string `json:"excludes"`
+	// Verification は Verify を指定した場合の生成結果と元のファイルの比較結果
+	Verification []VerifyResult `json:"verification,omitempty"`
+}
+
+// DuplicateSection は重複として取り込まなかったセクション
+type DuplicateSection struct {
+	Title  string `json:"title"`
+	Source string `json:"source"`
+}
+
+// RunNonInteractive は Bubble Tea の UI を使わずに options の内容で init を実行する
+func RunNonInteractive(options Options) (*Summary, error) {
+	state, err := NewInitState()
+	if err != nil {
+		return nil, err
+	}
+	return state.runNonInteractive(options)
+}
+
+func (state *InitState) runNonInteractive(options Options) (*Summary, error) {
+	exists, err := state.CheckSystemPromptDir()
+	if err != nil {
+		return nil, err
+	}
+	if exists && !options.Force && !options.Merge {
+		return nil, ErrSystemPromptDirExists
+	}
+	if options.Analyze && len(options.Import) > 0 {
+		return nil, ErrAnalyzeWithImport
+	}
+
+	tools, err := selectTools(options.Tools)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.Template != "" {
+		templateDir := options.TemplateDir
+		if templateDir == "" {
+			templateDir = DefaultUserTemplateDir
+		}
+		if state.Template, err = FindTemplate(options.Template, templateDir); err != nil {
+			return nil, err
+		}
+		// ツールを指定しない場合はテンプレートの settings.toml の設定を使用する
+		if len(options.Tools) == 0 {
+			tools = nil
+		}
+	}
+	state.SelectedTools = tools
+	state.SplitByHeading = options.Split
+	state.DetectShared = options.Shared
+	state.Merge = exists && options.Merge
+	if options.Analyze {
+		state.Drafts = AnalyzeProject(state.WorkDir).Drafts()
+	}
+
+	for _, path := range options.Import {
+		file, err := state.readImportFile(path)
+		if err != nil {
+			return nil, err
+		}
+		state.SelectedFiles = append(state.SelectedFiles, file)
+	}
+	// 対話形式で選択できない組み合わせは、ファイルを書き込む前にエラーにする
+	if options.Shared && len(state.SelectedFiles) > 0 {
+		if err := checkSharedFiles(state.SelectedFiles); err != nil {
+			return nil, err
+		}
+	}
+
+	created, err := state.Apply()
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &Summary{
+		SystemPromptDir:   state.relativePath(state.SystemPromptDir),
+		CreatedFiles:      created,
+		ImportedFiles:     []string{},
+		UpdatedFiles:      []string{},
+		Tools:             tools,
+		Overwritten:       exists && !state.Merge,
+		Merged:            state.Merge,
+		DuplicateSections: []DuplicateSection{},
+		Excludes:          map[string][]string{},
+	}
+	summary.UpdatedFiles = append(summary.UpdatedFiles, state.UpdatedFiles...)
+	if state.Template != nil {
+		summary.Template = state.Template.Name
+		if summary.Tools, err = enabledTools(state.SystemPromptDir); err != nil {
+			return nil, err
+		}
+	}
+	if state.BackupDir != "" {
+		summary.BackupDir = state.relativePath(state.BackupDir)
+	}
+	for _, file := range state.SelectedFiles {
+		summary.ImportedFiles = append(summary.ImportedFiles, file.Path)
+	}
+	for _, section := range state.DuplicateSections {
+		summary.DuplicateSections = append(summary.DuplicateSections, DuplicateSection{Title: section.Title, Source: section.Source})
+	}
+	for tool, excludes := range state.ToolExcludes {
+		summary.Excludes[tool] = excludes
+	}
+
+	if options.Verify {
+		if summary.Verification, err = state.Verify(); err != nil {
+			return nil, err
+		}
+	}
+	return summary, nil
+}
+
+// enabledTools は systemPromptDir の設定ファイルで生成が有効なツールを名前順に返す
+func enabledTools(systemPromptDir string) ([]string, error) {
+	infos, err := config.ListTools(config.FindSettingsFile(filepath.Join(systemPromptDir, "settings.toml")))
+	if err != nil {
+		return nil, err
+	}
+
+	tools := []string{}
+	for _, info := range infos {
+		if info.Generate {
+			tools = append(tools, info.Name)
+		}
+	}
+	return tools, nil
+}
+
+// selectTools は names を検証し、重複を除いて名前順に返す。names が空の場合は全てのビルトインツールを返す
+func selectTools(names []string) ([]string, error) {
+	allTools := knownToolNames()
+	if len(names) == 0 {
+		return allTools, nil
+	}
+
+	selected := make(map[string]bool)
+	for _, name := range names {
+		if _, ok := config.DefaultKnownToolFileNames[name]; !ok {
+			return nil, fmt.Errorf("unknown tool %q (available: %s)", name, strings.Join(allTools, ", "))
+		}
+		selected[name] = true
+	}
+
+	var tools []string
+	for _, name := range allTools {
+		if selected[name] {
+			tools = append(tools, name)
+		}
+	}
+	return tools, nil
+}
+
+// readImportFile は取り込むファイルを読み込む。ビルトインツールのファイルの場合は ToolName を設定する
+func (state *InitState) readImportFile(path string) (ExistingFile, error) {
+	fullPath := path
+	if !filepath.IsAbs(fullPath) {
+		fullPath = filepath.Join(state.WorkDir, path)
+	}
+
+	content, err := os.ReadFile(fullPath)
+	if err != nil {
+		return ExistingFile{}, fmt.Errorf("failed to import %s: %w", path, err)
+	}
+
+	file := ExistingFile{
+		Path:    state.relativePath(fullPath),
+		Content: strings.TrimSpace(string(content)),
+	}
+	for name, paths := range config.DefaultKnownToolFileNames {
+		if filepath.Join(string(paths.DirName), string(paths.FileName)) == file.Path {
+			file.ToolName = name
+			file.Kind = FileKindPrimary
+		}
+	}
+	return file, nil
+}
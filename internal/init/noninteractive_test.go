@@ -0,0 +1,197 @@
+package init
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cateiru/system-prompt-gen/internal/config"
+	"github.com/cateiru/system-prompt-gen/internal/generator"
+)
+
+func newTestInitState(t *testing.T) *InitState {
+	tempDir := t.TempDir()
+	return &InitState{
+		WorkDir:         tempDir,
+		SystemPromptDir: filepath.Join(tempDir, ".system_prompt"),
+	}
+}
+
+func TestRunNonInteractive(t *testing.T) {
+	state := newTestInitState(t)
+	require.NoError(t, os.WriteFile(filepath.Join(state.WorkDir, "CLAUDE.md"), []byte("Claude prompt\n"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(state.WorkDir, "docs"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(state.WorkDir, "docs", "rules.md"), []byte("Rules\n"), 0644))
+
+	summary, err := state.runNonInteractive(Options{
+		Tools:  []string{"claude", "agents", "claude"},
+		Import: []string{"CLAUDE.md", "docs/rules.md"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, &Summary{
+		SystemPromptDir:   ".system_prompt",
+		CreatedFiles:      []string{filepath.Join(".system_prompt", "001_default.md"), filepath.Join(".system_prompt", "settings.toml")},
+		ImportedFiles:     []string{"CLAUDE.md", filepath.Join("docs", "rules.md")},
+		Tools:             []string{"agents", "claude"},
+		UpdatedFiles:      []string{},
+		DuplicateSections: []DuplicateSection{},
+		Excludes:          map[string][]string{},
+	}, summary)
+	assert.Equal(t, "claude", state.SelectedFiles[0].ToolName)
+	assert.Empty(t, state.SelectedFiles[1].ToolName)
+
+	content, err := os.ReadFile(filepath.Join(state.SystemPromptDir, "001_default.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# Imported from CLAUDE.md\n\nClaude prompt\n\n# Imported from docs/rules.md\n\nRules", string(content))
+
+	settings, err := os.ReadFile(filepath.Join(state.SystemPromptDir, "settings.toml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(settings), "[tools.claude]\ngenerate = true\n")
+	assert.Contains(t, string(settings), "[tools.cline]\ngenerate = false\n")
+}
+
+func TestRunNonInteractiveDefaultTools(t *testing.T) {
+	state := newTestInitState(t)
+
+	summary, err := state.runNonInteractive(Options{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"agents", "claude", "cline", "github_copilot"}, summary.Tools)
+	assert.Empty(t, summary.ImportedFiles)
+}
+
+func TestRunNonInteractiveErrors(t *testing.T) {
+	t.Run("existing directory", func(t *testing.T) {
+		state := newTestInitState(t)
+		require.NoError(t, os.MkdirAll(state.SystemPromptDir, 0755))
+
+		_, err := state.runNonInteractive(Options{})
+		assert.ErrorIs(t, err, ErrSystemPromptDirExists)
+
+		summary, err := state.runNonInteractive(Options{Force: true})
+		require.NoError(t, err)
+		assert.True(t, summary.Overwritten)
+	})
+
+	t.Run("force backs up replaced files", func(t *testing.T) {
+		state := newTestInitState(t)
+		require.NoError(t, os.MkdirAll(state.SystemPromptDir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(state.SystemPromptDir, "001_default.md"), []byte("Old prompt\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(state.SystemPromptDir, "settings.toml"), []byte("# old settings\n"), 0644))
+
+		summary, err := state.runNonInteractive(Options{Force: true})
+		require.NoError(t, err)
+		require.NotEmpty(t, summary.BackupDir)
+		assert.Equal(t, filepath.Join(".system_prompt", ".backup"), filepath.Dir(summary.BackupDir))
+
+		backup, err := os.ReadFile(filepath.Join(state.WorkDir, summary.BackupDir, "001_default.md"))
+		require.NoError(t, err)
+		assert.Equal(t, "Old prompt\n", string(backup))
+		backup, err = os.ReadFile(filepath.Join(state.WorkDir, summary.BackupDir, "settings.toml"))
+		require.NoError(t, err)
+		assert.Equal(t, "# old settings\n", string(backup))
+
+		// 同じ秒に再度実行しても前のバックアップは上書きしない
+		second, err := state.runNonInteractive(Options{Force: true})
+		require.NoError(t, err)
+		assert.NotEqual(t, summary.BackupDir, second.BackupDir)
+	})
+
+	t.Run("unknown tool", func(t *testing.T) {
+		state := newTestInitState(t)
+
+		_, err := state.runNonInteractive(Options{Tools: []string{"cursor"}})
+		assert.ErrorContains(t, err, `unknown tool "cursor"`)
+		assert.NoDirExists(t, state.SystemPromptDir)
+	})
+
+	t.Run("missing import", func(t *testing.T) {
+		state := newTestInitState(t)
+
+		_, err := state.runNonInteractive(Options{Import: []string{"missing.md"}})
+		assert.ErrorContains(t, err, "failed to import missing.md")
+		assert.NoDirExists(t, state.SystemPromptDir)
+	})
+
+	t.Run("shared with a file of a non built-in tool", func(t *testing.T) {
+		state := newTestInitState(t)
+		require.NoError(t, os.WriteFile(filepath.Join(state.WorkDir, "CLAUDE.md"), []byte("Claude\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(state.WorkDir, ".cursorrules"), []byte("Cursor\n"), 0644))
+
+		_, err := state.runNonInteractive(Options{Import: []string{"CLAUDE.md", ".cursorrules"}, Shared: true})
+		assert.ErrorContains(t, err, "cannot detect shared content in .cursorrules")
+		assert.NoDirExists(t, state.SystemPromptDir)
+	})
+}
+
+func TestRunNonInteractiveShared(t *testing.T) {
+	state := newTestInitState(t)
+	originals := map[string]string{
+		"claude": "# Rules\n\nUse gofmt.\n\nClaude only.\n\n## Commit\n\nSmall commits.",
+		"cline":  "# Rules\n\nUse gofmt.\n\n## Commit\n\nSmall commits.\n\nCline only.",
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(state.WorkDir, "CLAUDE.md"), []byte(originals["claude"]), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(state.WorkDir, ".clinerules"), []byte(originals["cline"]), 0644))
+
+	summary, err := state.runNonInteractive(Options{
+		Tools:  []string{"claude", "cline", "agents"},
+		Import: []string{"CLAUDE.md", ".clinerules"},
+		Shared: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"020_claude_claude-only.md", "040_cline_cline-only.md"}, summary.Excludes["agents"])
+
+	// 生成される各ツールの出力は、見出しを除いて元のファイルと同じ段落を含む
+	t.Chdir(state.WorkDir)
+	settings, err := config.LoadSettings(filepath.Join(state.SystemPromptDir, "settings.toml"))
+	require.NoError(t, err)
+	gen := generator.New(settings)
+	for tool, original := range originals {
+		files, err := gen.CollectPromptFilesForTool(tool, settings.Tools[tool])
+		require.NoError(t, err)
+
+		var blocks []string
+		for _, file := range files {
+			blocks = append(blocks, splitBlocks(file.Content)...)
+		}
+		assert.Equal(t, splitBlocks(original), blocks, tool)
+	}
+}
+
+func TestRunNonInteractiveMerge(t *testing.T) {
+	state := newTestInitState(t)
+	require.NoError(t, os.MkdirAll(state.SystemPromptDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(state.SystemPromptDir, "001_default.md"), []byte("Existing prompt\n"), 0644))
+	settings := "version = 2\n\n# Claude settings\n[tools.claude]\ngenerate = true\n"
+	require.NoError(t, os.WriteFile(filepath.Join(state.SystemPromptDir, "settings.toml"), []byte(settings), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(state.WorkDir, ".clinerules"), []byte("Cline rules\n"), 0644))
+
+	summary, err := state.runNonInteractive(Options{
+		Tools:  []string{"cline"},
+		Import: []string{".clinerules"},
+		Merge:  true,
+	})
+	require.NoError(t, err)
+
+	assert.True(t, summary.Merged)
+	assert.False(t, summary.Overwritten)
+	assert.Equal(t, []string{filepath.Join(".system_prompt", "001_default-2.md")}, summary.CreatedFiles)
+	assert.Equal(t, []string{filepath.Join(".system_prompt", "settings.toml")}, summary.UpdatedFiles)
+
+	// 既存のプロンプトファイルは残る
+	content, err := os.ReadFile(filepath.Join(state.SystemPromptDir, "001_default.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "Existing prompt\n", string(content))
+
+	// 選択したツールをコメントを保持したまま追加する
+	content, err = os.ReadFile(filepath.Join(state.SystemPromptDir, "settings.toml"))
+	require.NoError(t, err)
+	assert.Equal(t, settings+"\n[tools.cline]\ngenerate = true\n", string(content))
+
+	backup, err := os.ReadFile(filepath.Join(state.WorkDir, summary.BackupDir, "settings.toml"))
+	require.NoError(t, err)
+	assert.Equal(t, settings, string(backup))
+}
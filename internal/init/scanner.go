@@ -2,13 +2,54 @@ package init
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/cateiru/system-prompt-gen/internal/config"
 )
 
+// FileKind は検出したファイルの種類
+type FileKind string
+
+const (
+	// FileKindPrimary はツールの出力先と同じパスのファイル（例: CLAUDE.md）
+	FileKindPrimary FileKind = "primary"
+	// FileKindLegacy は以前の形式の単一のルールファイル（例: .cursorrules）
+	FileKindLegacy FileKind = "legacy"
+	// FileKindRule はルールのディレクトリ内のファイル（例: .cursor/rules/*.mdc）
+	FileKindRule FileKind = "rule"
+	// FileKindNested はサブディレクトリ内のツールのファイル（例: packages/api/CLAUDE.md）
+	FileKindNested FileKind = "nested"
+)
+
+// ruleSource は組み込みの出力先以外に探すルールファイルまたはルールのディレクトリ
+type ruleSource struct {
+	ToolName string
+	Kind     FileKind
+	// Path は WorkDir からの相対パス
+	Path string
+	// Pattern はディレクトリ内で対象とするファイル名のパターン（Path がファイルの場合は空）
+	Pattern string
+}
+
+var ruleSources = []ruleSource{
+	{ToolName: "cursor", Kind: FileKindLegacy, Path: ".cursorrules"},
+	{ToolName: "cursor", Kind: FileKindRule, Path: filepath.Join(".cursor", "rules"), Pattern: "*.mdc"},
+	{ToolName: "cline", Kind: FileKindRule, Path: ".clinerules", Pattern: "*.md"},
+	{ToolName: "github_copilot", Kind: FileKindRule, Path: filepath.Join(".github", "instructions"), Pattern: "*.instructions.md"},
+	{ToolName: "windsurf", Kind: FileKindLegacy, Path: ".windsurfrules"},
+	{ToolName: "windsurf", Kind: FileKindRule, Path: filepath.Join(".windsurf", "rules"), Pattern: "*.md"},
+}
+
+// skipScanDirs はサブディレクトリのファイルを探す際に無視するディレクトリ
+var skipScanDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+}
+
 // FileScanner は既存のシステムプロンプトファイルをスキャンする
 type FileScanner struct {
 	WorkDir string
@@ -21,7 +62,9 @@ func NewFileScanner(workDir string) *FileScanner {
 	}
 }
 
-// ScanExistingFiles は既存のシステムプロンプトファイルをスキャンする
+// ScanExistingFiles は既存のシステムプロンプトファイルをスキャンする。
+// ツールの出力先のファイルに加え、以前の形式のファイル、ルールのディレクトリ内のファイル、
+// サブディレクトリ内のツールのファイルを検出し、ツール名とパスの順に返す。
 func (scanner *FileScanner) ScanExistingFiles() ([]ExistingFile, error) {
 	var files []ExistingFile
 
@@ -35,6 +78,27 @@ func (scanner *FileScanner) ScanExistingFiles() ([]ExistingFile, error) {
 		}
 	}
 
+	for _, source := range ruleSources {
+		sourceFiles, err := scanner.findRuleFiles(source)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning %s files: %w", source.ToolName, err)
+		}
+		files = append(files, sourceFiles...)
+	}
+
+	nestedFiles, err := scanner.findNestedFiles()
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, nestedFiles...)
+
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].ToolName != files[j].ToolName {
+			return files[i].ToolName < files[j].ToolName
+		}
+		return files[i].Path < files[j].Path
+	})
+
 	return files, nil
 }
 
@@ -47,6 +111,107 @@ func (scanner *FileScanner) findToolFile(toolName string, paths config.AIToolPat
 		filePath = filepath.Join(scanner.WorkDir, string(paths.DirName), string(paths.FileName))
 	}
 
+	return scanner.readFile(filePath, toolName, FileKindPrimary)
+}
+
+// findRuleFiles は source のファイル、または source のディレクトリ以下で Pattern に合致するファイルを返す
+func (scanner *FileScanner) findRuleFiles(source ruleSource) ([]ExistingFile, error) {
+	root := filepath.Join(scanner.WorkDir, source.Path)
+	info, err := os.Stat(root)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if source.Pattern == "" {
+		file, found, err := scanner.readFile(root, source.ToolName, source.Kind)
+		if err != nil || !found {
+			return nil, err
+		}
+		return []ExistingFile{file}, nil
+	}
+
+	// ファイルの場合は出力先のファイルとして検出する（例: ファイルの .clinerules）
+	if !info.IsDir() {
+		return nil, nil
+	}
+
+	var files []ExistingFile
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if matched, _ := filepath.Match(source.Pattern, d.Name()); !matched {
+			return nil
+		}
+
+		file, found, err := scanner.readFile(path, source.ToolName, source.Kind)
+		if err != nil {
+			return err
+		}
+		if found {
+			files = append(files, file)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// findNestedFiles はサブディレクトリ内の、ルートに出力するツールのファイル（CLAUDE.md など）を返す。
+// 隠しディレクトリと skipScanDirs のディレクトリは探さない。
+func (scanner *FileScanner) findNestedFiles() ([]ExistingFile, error) {
+	toolNames := make(map[string]string)
+	for toolName, paths := range config.DefaultKnownToolFileNames {
+		if paths.DirName == "" {
+			toolNames[string(paths.FileName)] = toolName
+		}
+	}
+
+	var files []ExistingFile
+	err := filepath.WalkDir(scanner.WorkDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != scanner.WorkDir && (strings.HasPrefix(d.Name(), ".") || skipScanDirs[d.Name()]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		toolName, ok := toolNames[d.Name()]
+		if !ok || filepath.Dir(path) == scanner.WorkDir {
+			return nil
+		}
+
+		file, found, err := scanner.readFile(path, toolName, FileKindNested)
+		if err != nil {
+			return err
+		}
+		if found {
+			files = append(files, file)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error scanning subdirectories: %w", err)
+	}
+
+	return files, nil
+}
+
+// readFile は filePath を読み込む。存在しない場合、ディレクトリの場合、空の場合は found = false を返す。
+// ルールのディレクトリ内のファイルのフロントマターは Metadata に設定し、Content からは取り除く。
+func (scanner *FileScanner) readFile(filePath string, toolName string, kind FileKind) (ExistingFile, bool, error) {
 	// ファイルの存在を確認
 	info, err := os.Stat(filePath)
 	if os.IsNotExist(err) {
@@ -67,19 +232,55 @@ func (scanner *FileScanner) findToolFile(toolName string, paths config.AIToolPat
 		return ExistingFile{}, false, fmt.Errorf("failed to read file %s: %w", filePath, err)
 	}
 
+	contentStr := string(content)
+	var metadata map[string]string
+	if kind == FileKindRule {
+		metadata, contentStr = parseFrontMatter(contentStr)
+	}
+
 	// 空のファイルはスキップ
-	contentStr := strings.TrimSpace(string(content))
+	contentStr = strings.TrimSpace(contentStr)
 	if contentStr == "" {
 		return ExistingFile{}, false, nil
 	}
 
-	file := ExistingFile{
+	return ExistingFile{
 		Path:     scanner.makeRelativePath(filePath),
 		ToolName: toolName,
 		Content:  contentStr,
+		Kind:     kind,
+		Metadata: metadata,
+	}, true, nil
+}
+
+// parseFrontMatter は content の先頭の "---" で囲まれたフロントマターを "key: value" の形式で解析し、
+// フロントマターを除いた内容を返す。.mdc の globs など YAML として解釈できない値もそのまま文字列として扱う。
+func parseFrontMatter(content string) (map[string]string, string) {
+	lines := strings.Split(content, "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return nil, content
+	}
+
+	metadata := make(map[string]string)
+	for i := 1; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "---" {
+			return metadata, strings.Join(lines[i+1:], "\n")
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(key) == "" {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' && value[len(value)-1] == '"' || value[0] == '\'' && value[len(value)-1] == '\'') {
+			value = value[1 : len(value)-1]
+		}
+		metadata[strings.TrimSpace(key)] = value
 	}
 
-	return file, true, nil
+	// 閉じる "---" がない場合はフロントマターとして扱わない
+	return nil, content
 }
 
 func (scanner *FileScanner) makeRelativePath(fullPath string) string {
@@ -89,4 +290,4 @@ func (scanner *FileScanner) makeRelativePath(fullPath string) string {
 		return fullPath
 	}
 	return relPath
-}
\ No newline at end of file
+}
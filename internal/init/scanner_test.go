@@ -117,4 +117,75 @@ func TestFileScanner_findToolFile_Directory(t *testing.T) {
 	files, err := scanner.ScanExistingFiles()
 	require.NoError(t, err)
 	assert.Empty(t, files)
-}
\ No newline at end of file
+}
+func TestFileScanner_ScanExistingFiles_RuleSources(t *testing.T) {
+	tempDir := t.TempDir()
+	writeFile := func(path, content string) {
+		fullPath := filepath.Join(tempDir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(fullPath), 0755))
+		require.NoError(t, os.WriteFile(fullPath, []byte(content), 0644))
+	}
+
+	writeFile(".cursorrules", "Legacy cursor rules")
+	writeFile(".cursor/rules/go.mdc", "---\ndescription: Go rules\nglobs: *.go,*.mod\nalwaysApply: false\n---\n\nUse gofmt.\n")
+	writeFile(".cursor/rules/frontend/react.mdc", "React rules")
+	writeFile(".cursor/rules/README.md", "not a rule")
+	writeFile(".clinerules/01-style.md", "Cline style")
+	writeFile(".clinerules/02-empty.md", "---\npaths: src\n---\n")
+	writeFile(".github/instructions/go.instructions.md", "---\napplyTo: \"**/*.go\"\n---\nCopilot Go rules")
+	writeFile(".windsurfrules", "Windsurf rules")
+	writeFile("packages/api/CLAUDE.md", "API rules")
+	writeFile("packages/api/AGENTS.md", "API agents")
+	writeFile("node_modules/lib/CLAUDE.md", "ignored")
+	writeFile(".hidden/CLAUDE.md", "ignored")
+	writeFile("CLAUDE.md", "Root rules")
+
+	files, err := NewFileScanner(tempDir).ScanExistingFiles()
+	require.NoError(t, err)
+
+	var paths []string
+	for _, file := range files {
+		paths = append(paths, file.Path)
+	}
+	assert.Equal(t, []string{
+		filepath.Join("packages", "api", "AGENTS.md"),
+		"CLAUDE.md",
+		filepath.Join("packages", "api", "CLAUDE.md"),
+		filepath.Join(".clinerules", "01-style.md"),
+		filepath.Join(".cursor", "rules", "frontend", "react.mdc"),
+		filepath.Join(".cursor", "rules", "go.mdc"),
+		".cursorrules",
+		filepath.Join(".github", "instructions", "go.instructions.md"),
+		".windsurfrules",
+	}, paths)
+
+	byPath := make(map[string]ExistingFile)
+	for _, file := range files {
+		byPath[file.Path] = file
+	}
+
+	assert.Equal(t, ExistingFile{
+		Path:     filepath.Join(".cursor", "rules", "go.mdc"),
+		ToolName: "cursor",
+		Content:  "Use gofmt.",
+		Kind:     FileKindRule,
+		Metadata: map[string]string{"description": "Go rules", "globs": "*.go,*.mod", "alwaysApply": "false"},
+	}, byPath[filepath.Join(".cursor", "rules", "go.mdc")])
+	assert.Equal(t, map[string]string{"applyTo": "**/*.go"}, byPath[filepath.Join(".github", "instructions", "go.instructions.md")].Metadata)
+	assert.Equal(t, FileKindLegacy, byPath[".cursorrules"].Kind)
+	assert.Equal(t, FileKindPrimary, byPath["CLAUDE.md"].Kind)
+	assert.Equal(t, FileKindNested, byPath[filepath.Join("packages", "api", "CLAUDE.md")].Kind)
+	assert.Equal(t, "claude", byPath[filepath.Join("packages", "api", "CLAUDE.md")].ToolName)
+	assert.Equal(t, "cline", byPath[filepath.Join(".clinerules", "01-style.md")].ToolName)
+}
+
+func TestParseFrontMatter(t *testing.T) {
+	metadata, content := parseFrontMatter("---\ntitle: 'Rules'\n---\nBody")
+	assert.Equal(t, map[string]string{"title": "Rules"}, metadata)
+	assert.Equal(t, "Body", content)
+
+	// 閉じる --- がない場合はそのまま返す
+	metadata, content = parseFrontMatter("---\nBody")
+	assert.Nil(t, metadata)
+	assert.Equal(t, "---\nBody", content)
+}
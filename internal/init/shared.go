@@ -0,0 +1,242 @@
+package init
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/cateiru/system-prompt-gen/internal/config"
+)
+
+// SharedPlan は共通の内容とツール固有の内容を分けて取り込むためのファイル構成
+type SharedPlan struct {
+	// Files は番号付きのプロンプトファイル。Sources はその内容を含んでいた取り込み元のファイル
+	Files []SplitFile
+	// Excludes はツールごとに exclude に追加するファイル名
+	Excludes map[string][]string
+}
+
+// sharedBlock は段落単位で統合したブロックと、それを含むツール
+type sharedBlock struct {
+	text  string
+	key   string
+	tools map[string]bool
+}
+
+// checkSharedFiles は files の共通の内容を検出できるかを確認する。
+// 組み込みのツールの出力先と同じパスのファイル（FileKindPrimary）のみを比較でき、2 つ以上のツールのファイルが必要。
+// サブディレクトリのファイルやルールのディレクトリのファイルは、同じツールの出力先のファイルと区別できないため対象外とする
+func checkSharedFiles(files []ExistingFile) error {
+	tools := make(map[string]bool)
+	for _, file := range files {
+		if _, ok := config.DefaultKnownToolFileNames[file.ToolName]; !ok || file.Kind != FileKindPrimary {
+			return fmt.Errorf("cannot detect shared content in %s: it is not the output file of a built-in tool", file.Path)
+		}
+		tools[file.ToolName] = true
+	}
+	if len(tools) < 2 {
+		return fmt.Errorf("cannot detect shared content: files of at least two tools are required")
+	}
+	return nil
+}
+
+// PlanSharedFiles は files をツールごとに段落単位で比較し、連続する段落をそれを含むツールの組み合わせごとに
+// 番号付きのファイルにまとめる。各ツールの exclude には、そのツールに含まれないファイルを設定する。
+// 取り込んでいないツール（allTools のみに含まれるツール）には、全てのツールに共通するファイルのみを含める。
+// 各ツールの出力は、元のファイルと同じ段落を同じ順序で含む。
+func PlanSharedFiles(files []ExistingFile, allTools []string) (*SharedPlan, error) {
+	if err := checkSharedFiles(files); err != nil {
+		return nil, err
+	}
+
+	var tools []string
+	blocksByTool := make(map[string][]string)
+	sources := make(map[string][]string)
+	for _, file := range files {
+		if _, ok := blocksByTool[file.ToolName]; !ok {
+			tools = append(tools, file.ToolName)
+		}
+		blocksByTool[file.ToolName] = append(blocksByTool[file.ToolName], splitBlocks(file.Content)...)
+		sources[file.ToolName] = append(sources[file.ToolName], file.Path)
+	}
+
+	var merged []sharedBlock
+	for _, tool := range tools {
+		merged = mergeBlocks(merged, blocksByTool[tool], tool)
+	}
+
+	// 統合結果から各ツールの段落を復元できることを確認する
+	for _, tool := range tools {
+		var restored []string
+		for _, block := range merged {
+			if block.tools[tool] {
+				restored = append(restored, block.key)
+			}
+		}
+		var original []string
+		for _, block := range blocksByTool[tool] {
+			original = append(original, blockKey(block))
+		}
+		if !slices.Equal(restored, original) {
+			return nil, fmt.Errorf("failed to separate the shared content of %s", tool)
+		}
+	}
+
+	// 同じツールの組み合わせが連続するブロックを 1 つのファイルにまとめる
+	type segment struct {
+		tools  []string
+		blocks []string
+	}
+	var segments []segment
+	for _, block := range merged {
+		var blockTools []string
+		for _, tool := range tools {
+			if block.tools[tool] {
+				blockTools = append(blockTools, tool)
+			}
+		}
+		if len(segments) > 0 && slices.Equal(segments[len(segments)-1].tools, blockTools) {
+			segments[len(segments)-1].blocks = append(segments[len(segments)-1].blocks, block.text)
+			continue
+		}
+		segments = append(segments, segment{tools: blockTools, blocks: []string{block.text}})
+	}
+
+	plan := &SharedPlan{Excludes: make(map[string][]string)}
+	width := max(3, len(fmt.Sprint(len(segments)*10)))
+	used := make(map[string]int)
+	for i, seg := range segments {
+		common := len(seg.tools) == len(tools)
+
+		slug := segmentSlug(seg.blocks)
+		if !common {
+			sortedTools := slices.Clone(seg.tools)
+			sort.Strings(sortedTools)
+			slug = strings.Join(sortedTools, "-") + "_" + slug
+		}
+		used[slug]++
+		if used[slug] > 1 {
+			slug = fmt.Sprintf("%s-%d", slug, used[slug])
+		}
+		name := fmt.Sprintf("%0*d_%s.md", width, (i+1)*10, slug)
+
+		var fileSources []string
+		for _, tool := range seg.tools {
+			fileSources = append(fileSources, sources[tool]...)
+		}
+		plan.Files = append(plan.Files, SplitFile{
+			Name:    name,
+			Content: strings.Join(seg.blocks, "\n\n"),
+			Sources: fileSources,
+		})
+
+		for _, tool := range allTools {
+			included := slices.Contains(seg.tools, tool)
+			if !slices.Contains(tools, tool) {
+				included = common
+			}
+			if !included {
+				plan.Excludes[tool] = append(plan.Excludes[tool], name)
+			}
+		}
+	}
+
+	return plan, nil
+}
+
+// mergeBlocks は統合済みのブロックと tool のブロックを最長共通部分列で対応付け、
+// 両方の順序を保ったまま 1 つの列にまとめる
+func mergeBlocks(merged []sharedBlock, blocks []string, tool string) []sharedBlock {
+	keys := make([]string, len(blocks))
+	for i, block := range blocks {
+		keys[i] = blockKey(block)
+	}
+
+	// lcs[i][j] は merged[i:] と blocks[j:] の最長共通部分列の長さ
+	lcs := make([][]int, len(merged)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(blocks)+1)
+	}
+	for i := len(merged) - 1; i >= 0; i-- {
+		for j := len(blocks) - 1; j >= 0; j-- {
+			if merged[i].key == keys[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var result []sharedBlock
+	i, j := 0, 0
+	for i < len(merged) || j < len(blocks) {
+		switch {
+		case i < len(merged) && j < len(blocks) && merged[i].key == keys[j]:
+			merged[i].tools[tool] = true
+			result = append(result, merged[i])
+			i++
+			j++
+		case j < len(blocks) && (i == len(merged) || lcs[i][j+1] >= lcs[i+1][j]):
+			result = append(result, sharedBlock{text: blocks[j], key: keys[j], tools: map[string]bool{tool: true}})
+			j++
+		default:
+			result = append(result, merged[i])
+			i++
+		}
+	}
+	return result
+}
+
+// splitBlocks は content を空行で段落に分割する。コードブロック内の空行では分割しない
+func splitBlocks(content string) []string {
+	var blocks []string
+	var current []string
+	inFence := false
+
+	for _, line := range strings.Split(content, "\n") {
+		if fencePattern.MatchString(line) {
+			inFence = !inFence
+		}
+		if !inFence && strings.TrimSpace(line) == "" {
+			if len(current) > 0 {
+				blocks = append(blocks, strings.Join(current, "\n"))
+				current = nil
+			}
+			continue
+		}
+		current = append(current, line)
+	}
+	if len(current) > 0 {
+		blocks = append(blocks, strings.Join(current, "\n"))
+	}
+
+	return blocks
+}
+
+// blockKey は段落の比較に使用する値（行末の空白を除いたもの）を返す
+func blockKey(block string) string {
+	lines := strings.Split(block, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRightFunc(line, unicode.IsSpace)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// segmentSlug はファイル名に使用する名前を、最初の見出し（ない場合は最初の数語）から作成する
+func segmentSlug(blocks []string) string {
+	for _, block := range blocks {
+		for _, line := range strings.Split(block, "\n") {
+			if match := headingPattern.FindStringSubmatch(line); match != nil {
+				return slugify(match[2])
+			}
+		}
+	}
+
+	words := strings.Fields(blocks[0])
+	if len(words) > 5 {
+		words = words[:5]
+	}
+	return slugify(strings.Join(words, " "))
+}
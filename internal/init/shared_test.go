@@ -0,0 +1,85 @@
+package init
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitBlocks(t *testing.T) {
+	blocks := splitBlocks("# Title\n\nFirst paragraph\ncontinued.\n\n\n```go\nfunc main() {\n\n}\n```\n\nLast.")
+
+	assert.Equal(t, []string{
+		"# Title",
+		"First paragraph\ncontinued.",
+		"```go\nfunc main() {\n\n}\n```",
+		"Last.",
+	}, blocks)
+}
+
+func TestPlanSharedFiles(t *testing.T) {
+	files := []ExistingFile{
+		{Path: "CLAUDE.md", ToolName: "claude", Kind: FileKindPrimary, Content: "# Rules\n\nUse gofmt.\n\nClaude only.\n\n## Commit\n\nSmall commits."},
+		{Path: ".clinerules", ToolName: "cline", Kind: FileKindPrimary, Content: "# Rules\n\nUse gofmt.\n\n## Commit\n\nSmall commits.\n\nCline only."},
+	}
+
+	plan, err := PlanSharedFiles(files, []string{"agents", "claude", "cline"})
+	require.NoError(t, err)
+
+	require.Len(t, plan.Files, 4)
+	assert.Equal(t, SplitFile{Name: "010_rules.md", Content: "# Rules\n\nUse gofmt.", Sources: []string{"CLAUDE.md", ".clinerules"}}, plan.Files[0])
+	assert.Equal(t, SplitFile{Name: "020_claude_claude-only.md", Content: "Claude only.", Sources: []string{"CLAUDE.md"}}, plan.Files[1])
+	assert.Equal(t, SplitFile{Name: "030_commit.md", Content: "## Commit\n\nSmall commits.", Sources: []string{"CLAUDE.md", ".clinerules"}}, plan.Files[2])
+	assert.Equal(t, SplitFile{Name: "040_cline_cline-only.md", Content: "Cline only.", Sources: []string{".clinerules"}}, plan.Files[3])
+
+	assert.Equal(t, map[string][]string{
+		"agents": {"020_claude_claude-only.md", "040_cline_cline-only.md"},
+		"claude": {"040_cline_cline-only.md"},
+		"cline":  {"020_claude_claude-only.md"},
+	}, plan.Excludes)
+}
+
+func TestPlanSharedFilesReordered(t *testing.T) {
+	// 順序が異なる段落は、各ツールの順序を保つために別のファイルになる
+	files := []ExistingFile{
+		{Path: "CLAUDE.md", ToolName: "claude", Kind: FileKindPrimary, Content: "A\n\nB"},
+		{Path: ".clinerules", ToolName: "cline", Kind: FileKindPrimary, Content: "B\n\nA"},
+	}
+
+	plan, err := PlanSharedFiles(files, []string{"claude", "cline"})
+	require.NoError(t, err)
+
+	restore := func(tool string) []string {
+		var blocks []string
+		for _, file := range plan.Files {
+			if !slices.Contains(plan.Excludes[tool], file.Name) {
+				blocks = append(blocks, splitBlocks(file.Content)...)
+			}
+		}
+		return blocks
+	}
+	assert.Equal(t, []string{"A", "B"}, restore("claude"))
+	assert.Equal(t, []string{"B", "A"}, restore("cline"))
+}
+
+func TestPlanSharedFilesRequiresToolName(t *testing.T) {
+	claude := ExistingFile{Path: "CLAUDE.md", ToolName: "claude", Kind: FileKindPrimary, Content: "Claude"}
+
+	_, err := PlanSharedFiles([]ExistingFile{claude, {Path: "NOTES.md", Content: "notes"}}, []string{"claude"})
+	assert.ErrorContains(t, err, "NOTES.md")
+
+	// 組み込みのツールではないファイルの内容は、どのツールにも含まれなくなるため対象外
+	_, err = PlanSharedFiles([]ExistingFile{claude, {Path: ".cursorrules", ToolName: "cursor", Kind: FileKindLegacy, Content: "Cursor"}}, knownToolNames())
+	assert.ErrorContains(t, err, ".cursorrules")
+
+	// サブディレクトリのファイルは同じツールの出力先のファイルと統合されるため対象外
+	nested := filepath.Join("packages", "api", "CLAUDE.md")
+	_, err = PlanSharedFiles([]ExistingFile{claude, {Path: nested, ToolName: "claude", Kind: FileKindNested, Content: "API"}}, knownToolNames())
+	assert.ErrorContains(t, err, nested)
+
+	_, err = PlanSharedFiles([]ExistingFile{claude}, knownToolNames())
+	assert.ErrorContains(t, err, "at least two tools")
+}
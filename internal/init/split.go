@@ -0,0 +1,196 @@
+package init
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Section は取り込んだファイルを見出しで区切った 1 つのセクション
+type Section struct {
+	// Title は見出しのテキスト（見出しより前の部分はファイルのタイトルまたはファイル名）
+	Title string
+	// Content は見出しの行を含むセクションの内容
+	Content string
+	// Source は取り込み元のファイルのパス
+	Source string
+}
+
+// SplitFile は分割モードで作成するプロンプトファイル
+type SplitFile struct {
+	// Name は番号付きのファイル名（例: 010_coding-style.md）
+	Name    string
+	Content string
+	// Sources は同じ内容のセクションを含んでいた取り込み元のファイル
+	Sources []string
+}
+
+var (
+	headingPattern = regexp.MustCompile(`^(#{1,6})\s+(.+?)\s*#*\s*$`)
+	fencePattern   = regexp.MustCompile("^\\s*(```|~~~)")
+)
+
+// PlanSplitFiles は files を見出しごとに分割し、番号付きのファイルに割り当てる。
+// 複数のファイルに同じ内容のセクションがある場合は最初のもののみを使用し、残りを duplicates として返す。
+func PlanSplitFiles(files []ExistingFile) (splitFiles []SplitFile, duplicates []Section) {
+	var sections []Section
+	for _, file := range files {
+		sections = append(sections, splitSections(file)...)
+	}
+
+	var titles []string
+	seen := make(map[string]int)
+	for _, section := range sections {
+		key := normalizeSection(section.Content)
+		if index, ok := seen[key]; ok {
+			splitFiles[index].Sources = append(splitFiles[index].Sources, section.Source)
+			duplicates = append(duplicates, section)
+			continue
+		}
+		seen[key] = len(splitFiles)
+		titles = append(titles, section.Title)
+		splitFiles = append(splitFiles, SplitFile{Content: section.Content, Sources: []string{section.Source}})
+	}
+
+	// 10 刻みの番号を付け、後からファイルを間に追加できるようにする
+	width := max(3, len(fmt.Sprint(len(splitFiles)*10)))
+	used := make(map[string]int)
+	for i := range splitFiles {
+		slug := slugify(titles[i])
+		used[slug]++
+		if used[slug] > 1 {
+			slug = fmt.Sprintf("%s-%d", slug, used[slug])
+		}
+		splitFiles[i].Name = fmt.Sprintf("%0*d_%s.md", width, (i+1)*10, slug)
+	}
+
+	return splitFiles, duplicates
+}
+
+// splitSections は file をトップレベルの見出しで分割する。
+// ファイルが 1 つのタイトル見出しで始まる場合は、その次のレベルの見出しで分割する。
+// コードブロック内の見出しは無視する。
+func splitSections(file ExistingFile) []Section {
+	type heading struct {
+		line  int
+		level int
+		title string
+	}
+
+	lines := strings.Split(file.Content, "\n")
+	var headings []heading
+	inFence := false
+	for i, line := range lines {
+		if fencePattern.MatchString(line) {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if match := headingPattern.FindStringSubmatch(line); match != nil {
+			headings = append(headings, heading{line: i, level: len(match[1]), title: match[2]})
+		}
+	}
+
+	defaultTitle := strings.TrimPrefix(filepath.Base(file.Path), ".")
+	defaultTitle = strings.TrimSuffix(defaultTitle, filepath.Ext(defaultTitle))
+
+	if len(headings) == 0 {
+		return []Section{{Title: defaultTitle, Content: file.Content, Source: file.Path}}
+	}
+
+	level := headings[0].level
+	for _, h := range headings {
+		level = min(level, h.level)
+	}
+
+	// 先頭にあるただ 1 つの最上位の見出しはファイルのタイトルとして扱う
+	var topLevel []heading
+	for _, h := range headings {
+		if h.level == level {
+			topLevel = append(topLevel, h)
+		}
+	}
+	if len(topLevel) == 1 && strings.TrimSpace(strings.Join(lines[:topLevel[0].line], "\n")) == "" {
+		defaultTitle = topLevel[0].title
+		next := 0
+		for _, h := range headings {
+			if h.level > level && (next == 0 || h.level < next) {
+				next = h.level
+			}
+		}
+		topLevel = nil
+		for _, h := range headings {
+			if h.level == next {
+				topLevel = append(topLevel, h)
+			}
+		}
+	}
+
+	var sections []Section
+	addSection := func(title string, start, end int) {
+		content := strings.TrimSpace(strings.Join(lines[start:end], "\n"))
+		if content == "" || headingPattern.MatchString(content) && defaultTitle == title {
+			// タイトルの見出しのみのセクションは作成しない
+			return
+		}
+		sections = append(sections, Section{Title: title, Content: content, Source: file.Path})
+	}
+
+	start := 0
+	title := defaultTitle
+	for _, h := range topLevel {
+		addSection(title, start, h.line)
+		start = h.line
+		title = h.title
+	}
+	addSection(title, start, len(lines))
+
+	return sections
+}
+
+// normalizeSection は重複の判定のため、行末の空白と連続する空行を取り除く
+func normalizeSection(content string) string {
+	var lines []string
+	blank := false
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimRightFunc(line, unicode.IsSpace)
+		if line == "" {
+			if blank {
+				continue
+			}
+			blank = true
+		} else {
+			blank = false
+		}
+		lines = append(lines, line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// slugify は見出しをファイル名に使用できる形式に変換する（例: "Coding Style" → "coding-style"）。
+// 文字と数字以外は "-" に置き換え、日本語などの文字はそのまま残す。
+func slugify(title string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			hyphen = false
+			continue
+		}
+		if !hyphen && b.Len() > 0 {
+			b.WriteRune('-')
+			hyphen = true
+		}
+	}
+
+	slug := strings.TrimSuffix(b.String(), "-")
+	if slug == "" {
+		return "section"
+	}
+	return slug
+}
@@ -0,0 +1,118 @@
+package init
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := map[string]string{
+		"Coding Style":        "coding-style",
+		"  Testing & CI!  ":   "testing-ci",
+		"Go 1.24 の規約":         "go-1-24-の規約",
+		"!!!":                 "section",
+		"API/REST guidelines": "api-rest-guidelines",
+	}
+	for title, expected := range tests {
+		assert.Equal(t, expected, slugify(title), title)
+	}
+}
+
+func TestSplitSections(t *testing.T) {
+	t.Run("top-level headings", func(t *testing.T) {
+		sections := splitSections(ExistingFile{
+			Path:    ".clinerules",
+			Content: "Intro text\n\n# Coding Style\n\nUse gofmt.\n\n## Details\n\nMore.\n\n# Testing\n\nRun tests.",
+		})
+
+		require.Len(t, sections, 3)
+		assert.Equal(t, Section{Title: "clinerules", Content: "Intro text", Source: ".clinerules"}, sections[0])
+		assert.Equal(t, Section{Title: "Coding Style", Content: "# Coding Style\n\nUse gofmt.\n\n## Details\n\nMore.", Source: ".clinerules"}, sections[1])
+		assert.Equal(t, Section{Title: "Testing", Content: "# Testing\n\nRun tests.", Source: ".clinerules"}, sections[2])
+	})
+
+	t.Run("title heading", func(t *testing.T) {
+		sections := splitSections(ExistingFile{
+			Path:    "CLAUDE.md",
+			Content: "# Project Rules\n\n## Coding Style\n\n```sh\n# comment, not a heading\n```\n\n## Testing\n\nRun tests.",
+		})
+
+		require.Len(t, sections, 2)
+		assert.Equal(t, "Coding Style", sections[0].Title)
+		assert.Equal(t, "## Coding Style\n\n```sh\n# comment, not a heading\n```", sections[0].Content)
+		assert.Equal(t, "Testing", sections[1].Title)
+	})
+
+	t.Run("title heading with introduction", func(t *testing.T) {
+		sections := splitSections(ExistingFile{
+			Path:    "CLAUDE.md",
+			Content: "# Project Rules\n\nIntro.\n\n## Testing\n\nRun tests.",
+		})
+
+		require.Len(t, sections, 2)
+		assert.Equal(t, Section{Title: "Project Rules", Content: "# Project Rules\n\nIntro.", Source: "CLAUDE.md"}, sections[0])
+	})
+
+	t.Run("no headings", func(t *testing.T) {
+		sections := splitSections(ExistingFile{Path: "AGENTS.md", Content: "Just text."})
+		assert.Equal(t, []Section{{Title: "AGENTS", Content: "Just text.", Source: "AGENTS.md"}}, sections)
+	})
+}
+
+func TestPlanSplitFiles(t *testing.T) {
+	files := []ExistingFile{
+		{Path: "CLAUDE.md", Content: "# Coding Style\n\nUse gofmt.\n\n# Testing\n\nRun tests."},
+		{Path: "AGENTS.md", Content: "# Coding Style  \n\n\nUse gofmt.\n\n# Testing\n\nRun all tests."},
+	}
+
+	splitFiles, duplicates := PlanSplitFiles(files)
+
+	assert.Equal(t, []SplitFile{
+		{Name: "010_coding-style.md", Content: "# Coding Style\n\nUse gofmt.", Sources: []string{"CLAUDE.md", "AGENTS.md"}},
+		{Name: "020_testing.md", Content: "# Testing\n\nRun tests.", Sources: []string{"CLAUDE.md"}},
+		{Name: "030_testing-2.md", Content: "# Testing\n\nRun all tests.", Sources: []string{"AGENTS.md"}},
+	}, splitFiles)
+	assert.Equal(t, []Section{{Title: "Coding Style", Content: "# Coding Style  \n\n\nUse gofmt.", Source: "AGENTS.md"}}, duplicates)
+}
+
+func TestPlanSplitFilesNumberWidth(t *testing.T) {
+	var content string
+	for i := 0; i < 100; i++ {
+		content += "# Section\n\nBody " + string(rune('a'+i%26)) + string(rune('a'+i/26)) + "\n\n"
+	}
+
+	splitFiles, _ := PlanSplitFiles([]ExistingFile{{Path: "CLAUDE.md", Content: content}})
+	require.Len(t, splitFiles, 100)
+	assert.Equal(t, "0010_section.md", splitFiles[0].Name)
+	assert.Equal(t, "1000_section-100.md", splitFiles[99].Name)
+}
+
+func TestApplySplitByHeading(t *testing.T) {
+	tempDir := t.TempDir()
+	state := &InitState{
+		WorkDir:         tempDir,
+		SystemPromptDir: filepath.Join(tempDir, ".system_prompt"),
+		SelectedFiles: []ExistingFile{
+			{Path: "CLAUDE.md", ToolName: "claude", Content: "# Coding Style\n\nUse gofmt."},
+			{Path: ".clinerules", ToolName: "cline", Content: "# Coding Style\n\nUse gofmt."},
+		},
+		SplitByHeading: true,
+	}
+
+	created, err := state.Apply()
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(".system_prompt", "010_coding-style.md"),
+		filepath.Join(".system_prompt", "settings.toml"),
+	}, created)
+	assert.Len(t, state.DuplicateSections, 1)
+
+	content, err := os.ReadFile(filepath.Join(state.SystemPromptDir, "010_coding-style.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# Coding Style\n\nUse gofmt.\n", string(content))
+	assert.NoFileExists(t, filepath.Join(state.SystemPromptDir, "001_default.md"))
+}
@@ -0,0 +1,230 @@
+package init
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/cateiru/system-prompt-gen/internal/util"
+)
+
+//go:embed templates
+var builtinTemplates embed.FS
+
+// DefaultUserTemplateDir はユーザーのテンプレートを探すディレクトリ
+const DefaultUserTemplateDir = "~/.config/system-prompt-gen/templates"
+
+// templateSuffix はプロジェクトの変数を埋め込むテンプレートファイルの拡張子（書き込む際に取り除く）
+const templateSuffix = ".tmpl"
+
+// Template はサンプルのプロンプトファイルと settings.toml をまとめたテンプレート
+type Template struct {
+	Name string
+	// Dir はユーザーのテンプレートのディレクトリ（組み込みのテンプレートの場合は空）
+	Dir  string
+	fsys fs.FS
+}
+
+// TemplateFile はテンプレートから作成するファイル
+type TemplateFile struct {
+	Name    string
+	Content string
+}
+
+// TemplateVars はテンプレートに埋め込むプロジェクトの情報
+type TemplateVars struct {
+	// ProjectName は作業ディレクトリの名前
+	ProjectName string
+	// ModuleName は go.mod のモジュール名
+	ModuleName string
+	// GoVersion は go.mod の go ディレクティブのバージョン
+	GoVersion string
+	// PackageName は package.json の name
+	PackageName string
+}
+
+// BuiltIn は組み込みのテンプレートかどうかを返す
+func (t *Template) BuiltIn() bool {
+	return t.Dir == ""
+}
+
+// ListTemplates は組み込みのテンプレートと userDir のテンプレートを名前順に返す。
+// userDir に組み込みのテンプレートと同じ名前のテンプレートがある場合は userDir のものを使用する。
+func ListTemplates(userDir string) ([]*Template, error) {
+	templates := make(map[string]*Template)
+
+	entries, err := fs.ReadDir(builtinTemplates, "templates")
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			fsys, err := fs.Sub(builtinTemplates, "templates/"+entry.Name())
+			if err != nil {
+				return nil, err
+			}
+			templates[entry.Name()] = &Template{Name: entry.Name(), fsys: fsys}
+		}
+	}
+
+	if userDir != "" {
+		userDir = util.ExpandHome(userDir)
+		entries, err := os.ReadDir(userDir)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read template directory %s: %w", userDir, err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				templates[entry.Name()] = newDirTemplate(filepath.Join(userDir, entry.Name()))
+			}
+		}
+	}
+
+	var list []*Template
+	for _, t := range templates {
+		list = append(list, t)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list, nil
+}
+
+// FindTemplate は name のテンプレートを返す。name がディレクトリのパスの場合はそのディレクトリをテンプレートとして使用する。
+func FindTemplate(name string, userDir string) (*Template, error) {
+	if info, err := os.Stat(util.ExpandHome(name)); err == nil && info.IsDir() && strings.ContainsAny(name, `/\`) {
+		return newDirTemplate(util.ExpandHome(name)), nil
+	}
+
+	templates, err := ListTemplates(userDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, t := range templates {
+		if t.Name == name {
+			return t, nil
+		}
+		names = append(names, t.Name)
+	}
+	return nil, fmt.Errorf("unknown template %q (available: %s)", name, strings.Join(names, ", "))
+}
+
+func newDirTemplate(dir string) *Template {
+	return &Template{Name: filepath.Base(dir), Dir: dir, fsys: os.DirFS(dir)}
+}
+
+// Render はテンプレートのファイルを vars で展開して返す。
+// .tmpl で終わるファイルは text/template として展開し、拡張子を取り除く。それ以外のファイルはそのまま返す。
+// サブディレクトリは使用しない。
+func (t *Template) Render(vars TemplateVars) ([]TemplateFile, error) {
+	entries, err := fs.ReadDir(t.fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template %s: %w", t.Name, err)
+	}
+
+	var files []TemplateFile
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		content, err := fs.ReadFile(t.fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template %s: %w", t.Name, err)
+		}
+
+		name := entry.Name()
+		if strings.HasSuffix(name, templateSuffix) {
+			name = strings.TrimSuffix(name, templateSuffix)
+			tmpl, err := template.New(entry.Name()).Option("missingkey=error").Parse(string(content))
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s in template %s: %w", entry.Name(), t.Name, err)
+			}
+			var buf bytes.Buffer
+			if err := tmpl.Execute(&buf, vars); err != nil {
+				return nil, fmt.Errorf("failed to render %s in template %s: %w", entry.Name(), t.Name, err)
+			}
+			content = buf.Bytes()
+		}
+
+		files = append(files, TemplateFile{Name: name, Content: string(content)})
+	}
+	return files, nil
+}
+
+// EnabledTools はテンプレートの settings.toml で生成が有効なツールを名前順に返す。
+// settings.toml がない場合は空を返す。
+func (t *Template) EnabledTools(vars TemplateVars) ([]string, error) {
+	files, err := t.Render(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	var tools []string
+	for _, file := range files {
+		if file.Name != "settings.toml" {
+			continue
+		}
+		var settings struct {
+			Tools map[string]struct {
+				Generate bool `toml:"generate"`
+			} `toml:"tools"`
+		}
+		if _, err := toml.Decode(file.Content, &settings); err != nil {
+			return nil, fmt.Errorf("failed to read settings.toml in template %s: %w", t.Name, err)
+		}
+		for name, tool := range settings.Tools {
+			if tool.Generate {
+				tools = append(tools, name)
+			}
+		}
+	}
+	sort.Strings(tools)
+	return tools, nil
+}
+
+// DetectTemplateVars は workDir の go.mod と package.json からテンプレートに埋め込む情報を取得する。
+// ファイルがない場合や読み込めない場合、その値は空になる。
+func DetectTemplateVars(workDir string) TemplateVars {
+	vars := TemplateVars{ProjectName: filepath.Base(workDir)}
+
+	if goMod, err := os.Open(filepath.Join(workDir, "go.mod")); err == nil {
+		defer goMod.Close()
+		scanner := bufio.NewScanner(goMod)
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) != 2 {
+				continue
+			}
+			switch fields[0] {
+			case "module":
+				vars.ModuleName = strings.Trim(fields[1], `"`)
+			case "go":
+				vars.GoVersion = fields[1]
+			}
+		}
+	}
+
+	if content, err := os.ReadFile(filepath.Join(workDir, "package.json")); err == nil {
+		var pkg struct {
+			Name string `json:"name"`
+		}
+		if json.Unmarshal(content, &pkg) == nil {
+			vars.PackageName = pkg.Name
+		}
+	}
+
+	return vars
+}
@@ -0,0 +1,180 @@
+package init
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cateiru/system-prompt-gen/internal/config"
+)
+
+func TestListTemplates(t *testing.T) {
+	userDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(userDir, "minimal"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(userDir, "team"), 0755))
+
+	templates, err := ListTemplates(userDir)
+	require.NoError(t, err)
+
+	var names []string
+	for _, tmpl := range templates {
+		names = append(names, tmpl.Name)
+	}
+	assert.Equal(t, []string{"frontend", "go-service", "library", "minimal", "team"}, names)
+
+	// ユーザーのテンプレートは同じ名前の組み込みのテンプレートより優先される
+	assert.True(t, templates[0].BuiltIn())
+	assert.Equal(t, filepath.Join(userDir, "minimal"), templates[3].Dir)
+}
+
+func TestBuiltinTemplatesRender(t *testing.T) {
+	templates, err := ListTemplates("")
+	require.NoError(t, err)
+
+	for _, tmpl := range templates {
+		for _, vars := range []TemplateVars{{ProjectName: "app"}, {ProjectName: "app", ModuleName: "example.com/app", GoVersion: "1.24", PackageName: "app"}} {
+			files, err := tmpl.Render(vars)
+			require.NoError(t, err, tmpl.Name)
+
+			var hasSettings bool
+			for _, file := range files {
+				assert.NotContains(t, file.Name, templateSuffix)
+				assert.NotContains(t, file.Content, "{{", file.Name)
+				hasSettings = hasSettings || file.Name == "settings.toml"
+			}
+			assert.True(t, hasSettings, tmpl.Name)
+		}
+	}
+}
+
+func TestBuiltinTemplateSettings(t *testing.T) {
+	// 各テンプレートはプロジェクトの種類に合わせたツールを有効にする
+	expected := map[string][]string{
+		"frontend":   {"claude", "cline", "github_copilot"},
+		"go-service": {"agents", "claude", "github_copilot"},
+		"library":    {"agents", "claude"},
+		"minimal":    {"agents", "claude"},
+	}
+
+	for name, tools := range expected {
+		t.Run(name, func(t *testing.T) {
+			tmpl, err := FindTemplate(name, "")
+			require.NoError(t, err)
+			files, err := tmpl.Render(TemplateVars{ProjectName: "app"})
+			require.NoError(t, err)
+
+			dir := t.TempDir()
+			for _, file := range files {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, file.Name), []byte(file.Content), 0644))
+			}
+
+			// 除外するファイルはテンプレートに含まれている
+			settings, err := config.LoadSettings(filepath.Join(dir, "settings.toml"))
+			require.NoError(t, err)
+			for _, tool := range settings.Tools {
+				for _, exclude := range tool.Exclude {
+					assert.FileExists(t, filepath.Join(dir, exclude))
+				}
+			}
+
+			enabled, err := enabledTools(dir)
+			require.NoError(t, err)
+			assert.Equal(t, tools, enabled)
+		})
+	}
+}
+
+func TestTemplateEnabledTools(t *testing.T) {
+	tmpl, err := FindTemplate("frontend", "")
+	require.NoError(t, err)
+
+	tools, err := tmpl.EnabledTools(TemplateVars{ProjectName: "app"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"claude", "cline", "github_copilot"}, tools)
+}
+
+func TestFindTemplate(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "custom")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "010_intro.md.tmpl"), []byte("# {{.ProjectName}} ({{.ModuleName}})\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "020_raw.md"), []byte("Keep {{ braces }}\n"), 0644))
+
+	tmpl, err := FindTemplate(dir, "")
+	require.NoError(t, err)
+	assert.Equal(t, "custom", tmpl.Name)
+
+	files, err := tmpl.Render(TemplateVars{ProjectName: "shop", ModuleName: "example.com/shop"})
+	require.NoError(t, err)
+	assert.Equal(t, []TemplateFile{
+		{Name: "010_intro.md", Content: "# shop (example.com/shop)\n"},
+		{Name: "020_raw.md", Content: "Keep {{ braces }}\n"},
+	}, files)
+
+	_, err = FindTemplate("unknown", "")
+	assert.ErrorContains(t, err, `unknown template "unknown" (available: frontend, go-service, library, minimal)`)
+}
+
+func TestDetectTemplateVars(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "shop")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	assert.Equal(t, TemplateVars{ProjectName: "shop"}, DetectTemplateVars(dir))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/shop\n\ngo 1.24.6\n\nrequire (\n\tgithub.com/a/b v1.0.0\n)\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"name": "@shop/web", "version": "1.0.0"}`), 0644))
+	assert.Equal(t, TemplateVars{
+		ProjectName: "shop",
+		ModuleName:  "example.com/shop",
+		GoVersion:   "1.24.6",
+		PackageName: "@shop/web",
+	}, DetectTemplateVars(dir))
+}
+
+func TestRunNonInteractiveTemplate(t *testing.T) {
+	t.Run("template settings", func(t *testing.T) {
+		state := newTestInitState(t)
+
+		summary, err := state.runNonInteractive(Options{Template: "minimal"})
+		require.NoError(t, err)
+
+		assert.Equal(t, "minimal", summary.Template)
+		assert.Equal(t, []string{"agents", "claude"}, summary.Tools)
+		assert.Equal(t, []string{
+			filepath.Join(".system_prompt", "010_project.md"),
+			filepath.Join(".system_prompt", "settings.toml"),
+		}, summary.CreatedFiles)
+
+		content, err := os.ReadFile(filepath.Join(state.SystemPromptDir, "010_project.md"))
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "# "+filepath.Base(state.WorkDir)+"\n")
+	})
+
+	t.Run("selected tools and imports", func(t *testing.T) {
+		state := newTestInitState(t)
+		require.NoError(t, os.WriteFile(filepath.Join(state.WorkDir, ".clinerules"), []byte("Cline rules\n"), 0644))
+
+		summary, err := state.runNonInteractive(Options{Template: "minimal", Tools: []string{"cline"}, Import: []string{".clinerules"}})
+		require.NoError(t, err)
+
+		// 選択したツールを有効にし、選択しなかったテンプレートのツールは無効にする
+		assert.Equal(t, []string{"cline"}, summary.Tools)
+		assert.Contains(t, summary.CreatedFiles, filepath.Join(".system_prompt", "001_default.md"))
+		assert.Empty(t, summary.BackupDir)
+
+		content, err := os.ReadFile(filepath.Join(state.SystemPromptDir, "settings.toml"))
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "[tools.cline]\ngenerate = true\n")
+		assert.Contains(t, string(content), "[tools.agents]\ngenerate = false\n")
+		assert.Contains(t, string(content), "[tools.claude]\ngenerate = false\n")
+	})
+
+	t.Run("keeps a selected template tool", func(t *testing.T) {
+		state := newTestInitState(t)
+
+		summary, err := state.runNonInteractive(Options{Template: "minimal", Tools: []string{"claude", "cline"}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"claude", "cline"}, summary.Tools)
+	})
+}
@@ -2,6 +2,8 @@ package init
 
 import (
 	"fmt"
+	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 
@@ -36,14 +38,34 @@ var (
 
 	errorStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#FF5733"))
+
+	previewStyle = lipgloss.NewStyle().
+			BorderStyle(lipgloss.NormalBorder()).
+			BorderForeground(lipgloss.Color("#666666")).
+			Padding(1, 2).
+			Width(60)
 )
 
+// draftPreviewLines は下書きの確認で表示する各ファイルの最大行数
+const draftPreviewLines = 12
+
+// filePreviewLines はファイルの選択のプレビューで表示する最大行数
+const filePreviewLines = 20
+
+// customToolFields はカスタムツールの入力欄のメッセージ ID（名前、ディレクトリ、ファイル名の順）
+var customToolFields = []string{"init_custom_tool_name", "init_custom_tool_dir", "init_custom_tool_file"}
+
 type uiState int
 
 const (
 	stateOverwriteConfirm uiState = iota
 	stateFileSelection
+	stateImportMode
+	stateDraftReview
+	stateTemplateSelection
 	stateToolSelection
+	stateCustomTools
+	stateCustomToolInput
 	stateConfirmation
 	stateProcessing
 	stateSuccess
@@ -57,11 +79,35 @@ type initModel struct {
 	fileSelection map[int]bool
 	toolSelection map[int]bool
 	allTools      []string
-	err           error
+	templates     []*Template
+	// drafts はプロジェクトの解析から作成したプロンプトファイルの下書き
+	drafts []TemplateFile
+	// history は戻る操作のために、これまでに表示した状態を記録する
+	history []uiState
+	// customInput はカスタムツールの入力欄の値、customField は入力中の欄
+	customInput [3]string
+	customField int
+	customErr   string
+	err         error
+	// verification は init 後の生成結果と元のファイルの比較結果
+	verification []VerifyResult
+	verifyErr    error
 }
 
 // runInteractiveInit はインタラクティブな初期化UIを実行する
 func runInteractiveInit(initState *InitState) error {
+	templates, err := ListTemplates(DefaultUserTemplateDir)
+	if err != nil {
+		return err
+	}
+
+	p := tea.NewProgram(newInitModel(initState, templates))
+	_, err = p.Run()
+	return err
+}
+
+// newInitModel は initState の既存のファイルに合わせて初期状態を設定した initModel を作成する
+func newInitModel(initState *InitState, templates []*Template) initModel {
 	// DefaultKnownToolFileNamesからツール名を取得してソート
 	var allTools []string
 	for toolName := range config.DefaultKnownToolFileNames {
@@ -74,6 +120,13 @@ func runInteractiveInit(initState *InitState) error {
 		fileSelection: make(map[int]bool),
 		toolSelection: make(map[int]bool),
 		allTools:      allTools,
+		templates:     templates,
+		drafts:        AnalyzeProject(initState.WorkDir).Drafts(),
+	}
+
+	// 既存のファイルを検出したツールを選択済みにする
+	for i, tool := range allTools {
+		model.toolSelection[i] = model.detectedTool(tool)
 	}
 
 	// 初期状態を設定
@@ -81,15 +134,13 @@ func runInteractiveInit(initState *InitState) error {
 		if len(initState.ExistingFiles) > 0 {
 			model.state = stateFileSelection
 		} else {
-			model.state = stateToolSelection
+			model.state = model.draftOrTemplateSelection()
 		}
 	} else {
 		model.state = stateOverwriteConfirm
 	}
 
-	p := tea.NewProgram(model)
-	_, err := p.Run()
-	return err
+	return model
 }
 
 func (m initModel) Init() tea.Cmd {
@@ -99,6 +150,10 @@ func (m initModel) Init() tea.Cmd {
 func (m initModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.state == stateCustomToolInput {
+			return m.updateCustomToolInput(msg)
+		}
+
 		switch msg.String() {
 		case "ctrl+c", "q":
 			return m, tea.Quit
@@ -117,6 +172,12 @@ func (m initModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case " ":
 			m.toggleSelection()
 
+		case "a":
+			m.toggleAll()
+
+		case "left", "esc":
+			m.back()
+
 		case "enter":
 			return m.handleEnter()
 		}
@@ -125,14 +186,102 @@ func (m initModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// updateCustomToolInput はカスタムツールの入力欄のキー操作を処理する
+func (m initModel) updateCustomToolInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.back()
+	case tea.KeyTab, tea.KeyDown:
+		m.customField = (m.customField + 1) % len(customToolFields)
+	case tea.KeyShiftTab, tea.KeyUp:
+		m.customField = (m.customField + len(customToolFields) - 1) % len(customToolFields)
+	case tea.KeyBackspace:
+		if runes := []rune(m.customInput[m.customField]); len(runes) > 0 {
+			m.customInput[m.customField] = string(runes[:len(runes)-1])
+		}
+	case tea.KeySpace:
+		m.customInput[m.customField] += " "
+	case tea.KeyRunes:
+		m.customInput[m.customField] += string(msg.Runes)
+	case tea.KeyEnter:
+		if m.customField < len(customToolFields)-1 {
+			m.customField++
+			break
+		}
+		tool := CustomTool{
+			Name: strings.TrimSpace(m.customInput[0]),
+			AIToolPaths: config.AIToolPaths{
+				DirName:  config.DirName(strings.TrimSpace(m.customInput[1])),
+				FileName: config.FileName(strings.TrimSpace(m.customInput[2])),
+			},
+		}
+		if m.customErr = m.validateCustomTool(tool); m.customErr != "" {
+			break
+		}
+		m.initState.CustomTools = append(m.initState.CustomTools, tool)
+		m.back()
+	}
+	return m, nil
+}
+
+// validateCustomTool は tool を追加できない場合にその理由を返す
+func (m initModel) validateCustomTool(tool CustomTool) string {
+	if config.ValidateToolName(tool.Name) != nil {
+		return i18n.T("init_custom_tool_invalid_name")
+	}
+	duplicate := false
+	for _, custom := range m.initState.CustomTools {
+		duplicate = duplicate || custom.Name == tool.Name
+	}
+	if _, ok := config.DefaultKnownToolFileNames[tool.Name]; ok || duplicate {
+		return i18n.T("init_custom_tool_duplicate", map[string]any{"Name": tool.Name})
+	}
+	if tool.FileName == "" {
+		return i18n.T("init_custom_tool_missing_file")
+	}
+	path := filepath.Clean(filepath.Join(string(tool.DirName), string(tool.FileName)))
+	if filepath.IsAbs(string(tool.DirName)) || filepath.IsAbs(string(tool.FileName)) || path == ".." || strings.HasPrefix(path, ".."+string(filepath.Separator)) {
+		return i18n.T("init_custom_tool_invalid_path")
+	}
+	return ""
+}
+
+// moveTo は現在の状態を history に記録して state に移る
+func (m *initModel) moveTo(state uiState) {
+	m.history = append(m.history, m.state)
+	m.state = state
+	m.cursor = 0
+}
+
+// back は一つ前の状態に戻る。最初の状態と、init を実行した後は戻らない
+func (m *initModel) back() {
+	if len(m.history) == 0 || m.state >= stateProcessing {
+		return
+	}
+	m.state = m.history[len(m.history)-1]
+	m.history = m.history[:len(m.history)-1]
+	m.cursor = 0
+	m.customErr = ""
+}
+
 func (m initModel) getMaxCursor() int {
 	switch m.state {
 	case stateOverwriteConfirm:
-		return 1 // Yes/No
+		return 2 // Overwrite/Merge/Cancel
 	case stateFileSelection:
 		return len(m.initState.ExistingFiles) - 1
+	case stateImportMode:
+		return len(m.importModes()) - 1
+	case stateDraftReview:
+		return 1 // Use/Skip
+	case stateTemplateSelection:
+		return len(m.templates) // None + templates
 	case stateToolSelection:
 		return len(m.allTools) - 1
+	case stateCustomTools:
+		return 1 // Add/Continue
 	case stateConfirmation:
 		return 1 // Proceed/Cancel
 	default:
@@ -149,43 +298,106 @@ func (m initModel) toggleSelection() {
 	}
 }
 
+// toggleAll は全ての項目を選択する。全て選択済みの場合は全ての選択を解除する
+func (m initModel) toggleAll() {
+	var selection map[int]bool
+	var count int
+	switch m.state {
+	case stateFileSelection:
+		selection, count = m.fileSelection, len(m.initState.ExistingFiles)
+	case stateToolSelection:
+		selection, count = m.toolSelection, len(m.allTools)
+	default:
+		return
+	}
+
+	all := true
+	for i := 0; i < count; i++ {
+		all = all && selection[i]
+	}
+	for i := 0; i < count; i++ {
+		selection[i] = !all
+	}
+}
+
 func (m initModel) handleEnter() (tea.Model, tea.Cmd) {
 	switch m.state {
 	case stateOverwriteConfirm:
-		if m.cursor == 0 { // Yes
-			m.initState.OverwriteConfirmed = true
-			if len(m.initState.ExistingFiles) > 0 {
-				m.state = stateFileSelection
-			} else {
-				m.state = stateToolSelection
-			}
-		} else { // No
+		if m.cursor == 2 { // Cancel
 			return m, tea.Quit
 		}
+		m.initState.OverwriteConfirmed = true
+		m.initState.Merge = m.cursor == 1
+		m.initState.Drafts = nil
+		if len(m.initState.ExistingFiles) > 0 {
+			m.moveTo(stateFileSelection)
+		} else {
+			m.moveTo(m.draftOrTemplateSelection())
+		}
 
 	case stateFileSelection:
-		// 選択されたファイルを収集
+		// 選択されたファイルを一覧の順に収集
 		var selectedFiles []ExistingFile
-		for i, selected := range m.fileSelection {
-			if selected && i < len(m.initState.ExistingFiles) {
-				selectedFiles = append(selectedFiles, m.initState.ExistingFiles[i])
+		for i, file := range m.initState.ExistingFiles {
+			if m.fileSelection[i] {
+				selectedFiles = append(selectedFiles, file)
 			}
 		}
 		m.initState.SelectedFiles = selectedFiles
-		m.state = stateToolSelection
-		m.cursor = 0
+		m.initState.Drafts = nil
+		if len(selectedFiles) > 0 {
+			m.moveTo(stateImportMode)
+		} else {
+			m.moveTo(m.draftOrTemplateSelection())
+		}
+
+	case stateImportMode:
+		mode := m.importModes()[m.cursor]
+		m.initState.SplitByHeading = mode == "init_import_mode_split"
+		m.initState.DetectShared = mode == "init_import_mode_shared"
+		m.moveTo(m.templateOrToolSelection())
+
+	case stateDraftReview:
+		m.initState.Drafts = nil
+		if m.cursor == 0 {
+			m.initState.Drafts = m.drafts
+		}
+		m.moveTo(m.templateOrToolSelection())
+
+	case stateTemplateSelection:
+		m.initState.Template = nil
+		var templateTools []string
+		if m.cursor > 0 {
+			m.initState.Template = m.templates[m.cursor-1]
+			// 読み込めないテンプレートは作成時にエラーとなるため、ここでは選択済みにしないだけとする
+			templateTools, _ = m.initState.Template.EnabledTools(DetectTemplateVars(m.initState.WorkDir))
+		}
+		// 選択されなかったテンプレートのツールは無効になるため、テンプレートで有効なツールを選択済みにする
+		for i, tool := range m.allTools {
+			m.toolSelection[i] = m.detectedTool(tool) || slices.Contains(templateTools, tool)
+		}
+		m.moveTo(stateToolSelection)
 
 	case stateToolSelection:
-		// 選択されたツールを収集
-		var selectedTools []string
-		for i, selected := range m.toolSelection {
-			if selected && i < len(m.allTools) {
-				selectedTools = append(selectedTools, m.allTools[i])
+		// 選択されたツールを名前順に収集する（テンプレートの選択されなかったツールを無効にするため nil にしない）
+		selectedTools := []string{}
+		for i, tool := range m.allTools {
+			if m.toolSelection[i] {
+				selectedTools = append(selectedTools, tool)
 			}
 		}
 		m.initState.SelectedTools = selectedTools
-		m.state = stateConfirmation
-		m.cursor = 0
+		m.moveTo(stateCustomTools)
+
+	case stateCustomTools:
+		if m.cursor == 0 { // Add
+			m.customInput = [3]string{}
+			m.customField = 0
+			m.customErr = ""
+			m.moveTo(stateCustomToolInput)
+		} else { // Continue
+			m.moveTo(stateConfirmation)
+		}
 
 	case stateConfirmation:
 		if m.cursor == 0 { // Proceed
@@ -201,26 +413,13 @@ func (m initModel) handleEnter() (tea.Model, tea.Cmd) {
 func (m initModel) processInit() (tea.Model, tea.Cmd) {
 	m.state = stateProcessing
 
-	// ディレクトリ作成
-	if err := m.initState.CreateSystemPromptDir(); err != nil {
-		m.state = stateError
-		m.err = err
-		return m, nil
-	}
-
-	// ファイル作成
-	if err := m.initState.WriteDefaultFile(); err != nil {
-		m.state = stateError
-		m.err = err
-		return m, nil
-	}
-
-	if err := m.initState.WriteSettingsFile(); err != nil {
+	if _, err := m.initState.Apply(); err != nil {
 		m.state = stateError
 		m.err = err
 		return m, nil
 	}
 
+	m.verification, m.verifyErr = m.initState.Verify()
 	m.state = stateSuccess
 	return m, tea.Quit
 }
@@ -231,8 +430,18 @@ func (m initModel) View() string {
 		return m.renderOverwriteConfirm()
 	case stateFileSelection:
 		return m.renderFileSelection()
+	case stateImportMode:
+		return m.renderImportMode()
+	case stateDraftReview:
+		return m.renderDraftReview()
+	case stateTemplateSelection:
+		return m.renderTemplateSelection()
 	case stateToolSelection:
 		return m.renderToolSelection()
+	case stateCustomTools:
+		return m.renderCustomTools()
+	case stateCustomToolInput:
+		return m.renderCustomToolInput()
 	case stateConfirmation:
 		return m.renderConfirmation()
 	case stateProcessing:
@@ -249,8 +458,9 @@ func (m initModel) View() string {
 func (m initModel) renderOverwriteConfirm() string {
 	options := []string{
 		fmt.Sprintf("%s\n", i18n.T("init_overwrite_message")),
-		fmt.Sprintf("%s %s", m.getCursor(0), i18n.T("yes")),
-		fmt.Sprintf("%s %s", m.getCursor(1), i18n.T("no")),
+		fmt.Sprintf("%s %s", m.getCursor(0), i18n.T("init_overwrite_option_overwrite")),
+		fmt.Sprintf("%s %s", m.getCursor(1), i18n.T("init_overwrite_option_merge")),
+		fmt.Sprintf("%s %s", m.getCursor(2), i18n.T("cancel")),
 	}
 
 	content := strings.Join(options, "\n")
@@ -277,17 +487,157 @@ func (m initModel) renderFileSelection() string {
 		} else {
 			selected = " "
 		}
-		options = append(options, fmt.Sprintf("%s [%s] %s (%s)", m.getCursor(i), selected, file.Path, file.ToolName))
+		label := file.ToolName
+		if file.Kind != "" && file.Kind != FileKindPrimary {
+			label = fmt.Sprintf("%s, %s", file.ToolName, i18n.T("init_file_kind_"+string(file.Kind)))
+		}
+		options = append(options, fmt.Sprintf("%s [%s] %s (%s)", m.getCursor(i), selected, file.Path, label))
 	}
 
 	content := strings.Join(options, "\n")
 
 	return fmt.Sprintf("%s\n\n%s\n",
-		listStyle.Render(content),
+		lipgloss.JoinHorizontal(lipgloss.Top, listStyle.Render(content), m.renderFilePreview()),
 		i18n.T("init_selection_help"),
 	)
 }
 
+// renderFilePreview はカーソルのあるファイルの内容の先頭を表示する
+func (m initModel) renderFilePreview() string {
+	if m.cursor >= len(m.initState.ExistingFiles) {
+		return ""
+	}
+	file := m.initState.ExistingFiles[m.cursor]
+
+	lines := strings.Split(file.Content, "\n")
+	if len(lines) > filePreviewLines {
+		lines = append(lines[:filePreviewLines], unselectedStyle.Render(i18n.T("init_preview_more_lines", map[string]any{"Count": len(lines) - filePreviewLines})))
+	}
+	return previewStyle.Render(fmt.Sprintf("%s\n\n%s", selectedStyle.Render(file.Path), strings.Join(lines, "\n")))
+}
+
+// importModes は選択できる取り込み方法のメッセージ ID を返す。
+// 共通の内容の検出は、複数の組み込みのツールの出力先のファイルのみを選択した場合に選択できる。
+func (m initModel) importModes() []string {
+	modes := []string{"init_import_mode_single", "init_import_mode_split"}
+
+	if checkSharedFiles(m.initState.SelectedFiles) == nil {
+		modes = append(modes, "init_import_mode_shared")
+	}
+	return modes
+}
+
+func (m initModel) renderImportMode() string {
+	options := []string{
+		fmt.Sprintf("%s\n", i18n.T("init_import_mode_message")),
+	}
+	for i, mode := range m.importModes() {
+		options = append(options, fmt.Sprintf("%s %s", m.getCursor(i), i18n.T(mode)))
+	}
+
+	content := strings.Join(options, "\n")
+
+	return fmt.Sprintf("%s\n\n%s\n",
+		listStyle.Render(content),
+		i18n.T("init_navigation_help"),
+	)
+}
+
+// draftOrTemplateSelection は取り込むファイルがなく、プロジェクトの解析から下書きを作成できた場合は下書きの確認、
+// それ以外の場合は templateOrToolSelection の状態を返す
+func (m initModel) draftOrTemplateSelection() uiState {
+	if len(m.drafts) > 0 && len(m.initState.SelectedFiles) == 0 {
+		return stateDraftReview
+	}
+	return m.templateOrToolSelection()
+}
+
+func (m initModel) renderDraftReview() string {
+	options := []string{
+		fmt.Sprintf("%s\n", i18n.T("init_draft_review_message")),
+	}
+	for _, draft := range m.drafts {
+		options = append(options, selectedStyle.Render(draft.Name))
+		lines := strings.Split(strings.TrimRight(draft.Content, "\n"), "\n")
+		if len(lines) > draftPreviewLines {
+			lines = append(lines[:draftPreviewLines], unselectedStyle.Render(i18n.T("init_preview_more_lines", map[string]any{"Count": len(lines) - draftPreviewLines})))
+		}
+		options = append(options, strings.Join(lines, "\n"), "")
+	}
+	options = append(options,
+		fmt.Sprintf("%s %s", m.getCursor(0), i18n.T("init_draft_use")),
+		fmt.Sprintf("%s %s", m.getCursor(1), i18n.T("init_draft_skip")),
+	)
+
+	content := strings.Join(options, "\n")
+
+	return fmt.Sprintf("%s\n\n%s\n",
+		listStyle.Render(content),
+		i18n.T("init_navigation_help"),
+	)
+}
+
+// templateOrToolSelection はテンプレートがある場合はテンプレートの選択、ない場合はツールの選択の状態を返す
+func (m initModel) templateOrToolSelection() uiState {
+	if len(m.templates) > 0 {
+		return stateTemplateSelection
+	}
+	return stateToolSelection
+}
+
+func (m initModel) renderTemplateSelection() string {
+	none := i18n.T("init_template_none")
+	if len(m.initState.SelectedFiles) > 0 {
+		none = i18n.T("init_template_none_with_import")
+	}
+
+	options := []string{
+		fmt.Sprintf("%s\n", i18n.T("init_template_selection_message")),
+		fmt.Sprintf("%s %s", m.getCursor(0), none),
+	}
+	for i, t := range m.templates {
+		description := i18n.T("init_template_user", map[string]any{"Path": t.Dir})
+		if t.BuiltIn() {
+			description = i18n.T("init_template_" + t.Name)
+		}
+		options = append(options, fmt.Sprintf("%s %s - %s", m.getCursor(i+1), t.Name, description))
+	}
+
+	content := strings.Join(options, "\n")
+
+	return fmt.Sprintf("%s\n\n%s\n",
+		listStyle.Render(content),
+		i18n.T("init_navigation_help"),
+	)
+}
+
+// detectedTool は tool のファイルを検出したかどうかを返す
+func (m initModel) detectedTool(tool string) bool {
+	for _, file := range m.initState.ExistingFiles {
+		if file.ToolName == tool {
+			return true
+		}
+	}
+	return false
+}
+
+// outputFileWarnings は出力先のファイルがあるにもかかわらず選択されていないツールの警告を返す。
+// 選択しない場合、そのファイルは生成されなくなる。
+func (m initModel) outputFileWarnings() []string {
+	var warnings []string
+	for i, tool := range m.allTools {
+		if m.toolSelection[i] {
+			continue
+		}
+		for _, file := range m.initState.ExistingFiles {
+			if file.ToolName == tool && file.Kind == FileKindPrimary {
+				warnings = append(warnings, i18n.T("init_tool_unselected_warning", map[string]any{"Tool": tool, "Path": file.Path}))
+			}
+		}
+	}
+	return warnings
+}
+
 func (m initModel) renderToolSelection() string {
 	options := []string{
 		fmt.Sprintf("%s\n", i18n.T("init_tool_selection_message")),
@@ -300,9 +650,21 @@ func (m initModel) renderToolSelection() string {
 		} else {
 			selected = " "
 		}
+		if m.detectedTool(tool) {
+			tool = fmt.Sprintf("%s %s", tool, successStyle.Render(i18n.T("init_tool_detected")))
+		} else {
+			tool = fmt.Sprintf("%s %s", tool, unselectedStyle.Render(i18n.T("init_tool_not_detected")))
+		}
 		options = append(options, fmt.Sprintf("%s [%s] %s", m.getCursor(i), selected, tool))
 	}
 
+	if warnings := m.outputFileWarnings(); len(warnings) > 0 {
+		options = append(options, "")
+		for _, warning := range warnings {
+			options = append(options, errorStyle.Render("⚠ "+warning))
+		}
+	}
+
 	content := strings.Join(options, "\n")
 
 	return fmt.Sprintf("%s\n\n%s\n",
@@ -311,6 +673,51 @@ func (m initModel) renderToolSelection() string {
 	)
 }
 
+func (m initModel) renderCustomTools() string {
+	options := []string{
+		fmt.Sprintf("%s\n", i18n.T("init_custom_tools_message")),
+	}
+	for _, tool := range m.initState.CustomTools {
+		options = append(options, fmt.Sprintf("\t● %s (%s)", tool.Name, filepath.Join(string(tool.DirName), string(tool.FileName))))
+	}
+	if len(m.initState.CustomTools) > 0 {
+		options = append(options, "")
+	}
+	options = append(options,
+		fmt.Sprintf("%s %s", m.getCursor(0), i18n.T("init_custom_tool_add")),
+		fmt.Sprintf("%s %s", m.getCursor(1), i18n.T("init_custom_tool_continue")),
+	)
+
+	return fmt.Sprintf("%s\n\n%s\n",
+		listStyle.Render(strings.Join(options, "\n")),
+		i18n.T("init_navigation_help"),
+	)
+}
+
+func (m initModel) renderCustomToolInput() string {
+	// 入力中の欄にカーソルを表示する
+	m.cursor = m.customField
+
+	options := []string{
+		fmt.Sprintf("%s\n", i18n.T("init_custom_tool_input_message")),
+	}
+	for i, field := range customToolFields {
+		value := m.customInput[i]
+		if i == m.customField {
+			value += "█"
+		}
+		options = append(options, fmt.Sprintf("%s %s: %s", m.getCursor(i), i18n.T(field), value))
+	}
+	if m.customErr != "" {
+		options = append(options, "", errorStyle.Render(m.customErr))
+	}
+
+	return fmt.Sprintf("%s\n\n%s\n",
+		listStyle.Render(strings.Join(options, "\n")),
+		i18n.T("init_custom_tool_input_help"),
+	)
+}
+
 func (m initModel) renderConfirmation() string {
 	var details []string
 
@@ -320,10 +727,29 @@ func (m initModel) renderConfirmation() string {
 		for _, file := range m.initState.SelectedFiles {
 			details = append(details, fmt.Sprintf("\t◯ %s", file.Path))
 		}
+		if m.initState.SplitByHeading {
+			details = append(details, i18n.T("init_import_mode_split"))
+		}
+		if m.initState.DetectShared {
+			details = append(details, i18n.T("init_import_mode_shared"))
+		}
 	} else {
 		details = append(details, i18n.T("init_no_files_selected"))
 	}
 
+	if len(m.initState.Drafts) > 0 {
+		details = append(details, i18n.T("init_selected_drafts")+":")
+		for _, draft := range m.initState.Drafts {
+			details = append(details, fmt.Sprintf("\t◯ %s", draft.Name))
+		}
+	}
+	if m.initState.Template != nil {
+		details = append(details, i18n.T("init_selected_template", map[string]any{"Template": m.initState.Template.Name}))
+	}
+	if m.initState.Merge {
+		details = append(details, i18n.T("init_merge_mode"))
+	}
+
 	details = append(details, "")
 
 	// 選択されたツール
@@ -335,6 +761,33 @@ func (m initModel) renderConfirmation() string {
 	} else {
 		details = append(details, i18n.T("init_no_tools_selected"))
 	}
+	if len(m.initState.CustomTools) > 0 {
+		details = append(details, i18n.T("init_custom_tools")+":")
+		for _, tool := range m.initState.CustomTools {
+			details = append(details, fmt.Sprintf("\t● %s (%s)", tool.Name, filepath.Join(string(tool.DirName), string(tool.FileName))))
+		}
+	}
+
+	for _, warning := range m.outputFileWarnings() {
+		details = append(details, errorStyle.Render("⚠ "+warning))
+	}
+
+	// 作成するファイル
+	details = append(details, "")
+	if planned, err := m.initState.PlannedFiles(); err != nil {
+		details = append(details, errorStyle.Render(i18n.T("init_error_message")+": "+err.Error()))
+	} else {
+		details = append(details, i18n.T("init_files_to_create")+":")
+		details = append(details, renderFileTree(planned, func(path string) string {
+			if !fileExists(filepath.Join(m.initState.WorkDir, path)) {
+				return ""
+			}
+			if m.initState.Merge {
+				return unselectedStyle.Render(" " + i18n.T("init_tree_updated"))
+			}
+			return unselectedStyle.Render(" " + i18n.T("init_tree_overwritten"))
+		})...)
+	}
 
 	options := []string{
 		fmt.Sprintf("%s\n", i18n.T("init_confirmation_message")),
@@ -357,7 +810,27 @@ func (m initModel) renderProcessing() string {
 
 func (m initModel) renderSuccess() string {
 	title := successStyle.Render(i18n.T("init_success_title"))
-	return fmt.Sprintf("%s\n\n%s\n", title, i18n.T("init_success_message"))
+	view := fmt.Sprintf("%s\n\n%s\n", title, i18n.T("init_success_message"))
+	if m.initState.BackupDir != "" {
+		view += fmt.Sprintf("%s\n", i18n.T("init_backup_created", map[string]any{"Path": m.initState.relativePath(m.initState.BackupDir)}))
+	}
+
+	// 元のファイルを削除する前に確認できるよう、生成結果の検証結果を表示する
+	if m.verifyErr != nil {
+		return view + fmt.Sprintf("\n%s\n", errorStyle.Render(i18n.T("init_verify_error", map[string]any{"Error": m.verifyErr})))
+	}
+	if len(m.verification) == 0 {
+		return view
+	}
+	var lines []string
+	for _, result := range m.verification {
+		line := fmt.Sprintf("%s: %s", result.Tool, result.Message())
+		if result.ContentLoss {
+			line = errorStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	return view + fmt.Sprintf("\n%s\n\n%s\n", strings.Join(lines, "\n"), i18n.T("init_verify_hint"))
 }
 
 func (m initModel) renderError() string {
@@ -365,6 +838,53 @@ func (m initModel) renderError() string {
 	return fmt.Sprintf("%s\n\n%s: %v\n", title, i18n.T("init_error_message"), m.err)
 }
 
+// fileTreeNode は renderFileTree で表示するディレクトリまたはファイル
+type fileTreeNode struct {
+	name     string
+	path     string
+	children []*fileTreeNode
+}
+
+// renderFileTree は paths をディレクトリごとにまとめた木構造の行を返す。label はファイルの後ろに表示する文字列を返す
+func renderFileTree(paths []string, label func(path string) string) []string {
+	root := &fileTreeNode{}
+	for _, path := range paths {
+		node := root
+		for _, name := range strings.Split(filepath.ToSlash(path), "/") {
+			var child *fileTreeNode
+			for _, c := range node.children {
+				if c.name == name {
+					child = c
+				}
+			}
+			if child == nil {
+				child = &fileTreeNode{name: name, path: filepath.Join(node.path, name)}
+				node.children = append(node.children, child)
+			}
+			node = child
+		}
+	}
+
+	var lines []string
+	var walk func(node *fileTreeNode, prefix string)
+	walk = func(node *fileTreeNode, prefix string) {
+		for i, child := range node.children {
+			branch, indent := "├── ", "│   "
+			if i == len(node.children)-1 {
+				branch, indent = "└── ", "    "
+			}
+			if len(child.children) > 0 {
+				lines = append(lines, prefix+branch+child.name+"/")
+				walk(child, prefix+indent)
+			} else {
+				lines = append(lines, prefix+branch+child.name+label(child.path))
+			}
+		}
+	}
+	walk(root, "")
+	return lines
+}
+
 func (m initModel) getCursor(index int) string {
 	if m.cursor == index {
 		return selectedStyle.Render("►")
@@ -0,0 +1,265 @@
+package init
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cateiru/system-prompt-gen/internal/config"
+	"github.com/cateiru/system-prompt-gen/internal/i18n"
+)
+
+func newTestInitModel(t *testing.T, files []ExistingFile) initModel {
+	t.Helper()
+	state := newTestInitState(t)
+	state.ExistingFiles = files
+	return initModel{
+		state:         stateFileSelection,
+		initState:     state,
+		fileSelection: make(map[int]bool),
+		toolSelection: make(map[int]bool),
+		allTools:      knownToolNames(),
+	}
+}
+
+// sendKeys は keys を順に Update に渡した後の model を返す
+func sendKeys(t *testing.T, m initModel, keys ...tea.KeyMsg) initModel {
+	t.Helper()
+	for _, key := range keys {
+		model, _ := m.Update(key)
+		m = model.(initModel)
+	}
+	return m
+}
+
+func runeKeys(s string) []tea.KeyMsg {
+	var keys []tea.KeyMsg
+	for _, r := range s {
+		keys = append(keys, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	return keys
+}
+
+var (
+	keyEnter = tea.KeyMsg{Type: tea.KeyEnter}
+	keyEsc   = tea.KeyMsg{Type: tea.KeyEsc}
+	keyLeft  = tea.KeyMsg{Type: tea.KeyLeft}
+	keyDown  = tea.KeyMsg{Type: tea.KeyDown}
+	keyAll   = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}}
+)
+
+func TestInitModelSelectAll(t *testing.T) {
+	i18n.TestSetupI18n(t)
+	m := newTestInitModel(t, []ExistingFile{
+		{Path: "CLAUDE.md", ToolName: "claude", Content: "Claude"},
+		{Path: "AGENTS.md", ToolName: "agents", Content: "Agents"},
+	})
+
+	m = sendKeys(t, m, keyAll)
+	assert.Equal(t, map[int]bool{0: true, 1: true}, m.fileSelection)
+
+	// 全て選択済みの場合は選択を解除する
+	m = sendKeys(t, m, keyAll)
+	assert.Equal(t, map[int]bool{0: false, 1: false}, m.fileSelection)
+
+	m = sendKeys(t, m, keyAll, keyEnter)
+	assert.Equal(t, stateImportMode, m.state)
+	assert.Equal(t, "CLAUDE.md", m.initState.SelectedFiles[0].Path)
+	assert.Equal(t, "AGENTS.md", m.initState.SelectedFiles[1].Path)
+}
+
+func TestInitModelImportModes(t *testing.T) {
+	claude := ExistingFile{Path: "CLAUDE.md", ToolName: "claude", Content: "Claude", Kind: FileKindPrimary}
+	tests := []struct {
+		name   string
+		files  []ExistingFile
+		shared bool
+	}{
+		{"built-in tools", []ExistingFile{claude, {Path: ".clinerules", ToolName: "cline", Content: "Cline", Kind: FileKindPrimary}}, true},
+		{"single tool", []ExistingFile{claude}, false},
+		{"non built-in tool", []ExistingFile{claude, {Path: ".cursorrules", ToolName: "cursor", Content: "Cursor", Kind: FileKindLegacy}}, false},
+		{"nested file", []ExistingFile{claude, {Path: filepath.Join("packages", "api", "CLAUDE.md"), ToolName: "claude", Content: "API", Kind: FileKindNested}}, false},
+		{"rule file", []ExistingFile{claude, {Path: filepath.Join(".github", "instructions", "go.instructions.md"), ToolName: "github_copilot", Content: "Go", Kind: FileKindRule}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestInitModel(t, tt.files)
+			m.initState.SelectedFiles = tt.files
+			assert.Equal(t, tt.shared, slices.Contains(m.importModes(), "init_import_mode_shared"))
+		})
+	}
+}
+
+func TestInitModelTemplateTools(t *testing.T) {
+	i18n.TestSetupI18n(t)
+	templates, err := ListTemplates("")
+	require.NoError(t, err)
+
+	m := newTestInitModel(t, []ExistingFile{{Path: ".clinerules", ToolName: "cline", Content: "Cline", Kind: FileKindPrimary}})
+	m.templates = templates
+	m.state = stateTemplateSelection
+	m.cursor = slices.IndexFunc(templates, func(tmpl *Template) bool { return tmpl.Name == "minimal" }) + 1
+
+	// テンプレートで有効なツールと検出したツールを選択済みにする
+	m = sendKeys(t, m, keyEnter)
+	require.Equal(t, stateToolSelection, m.state)
+	m = sendKeys(t, m, keyEnter)
+	assert.Equal(t, []string{"agents", "claude", "cline"}, m.initState.SelectedTools)
+
+	// 何も選択しない場合も、テンプレートのツールを無効にするため nil にしない
+	m.state = stateToolSelection
+	m.toolSelection = make(map[int]bool)
+	m = sendKeys(t, m, keyEnter)
+	assert.NotNil(t, m.initState.SelectedTools)
+	assert.Empty(t, m.initState.SelectedTools)
+}
+
+func TestInitModelBack(t *testing.T) {
+	i18n.TestSetupI18n(t)
+	m := newTestInitModel(t, []ExistingFile{{Path: "CLAUDE.md", ToolName: "claude", Content: "Claude"}})
+
+	// 最初の状態からは戻らない
+	m = sendKeys(t, m, keyEsc)
+	assert.Equal(t, stateFileSelection, m.state)
+
+	m = sendKeys(t, m, tea.KeyMsg{Type: tea.KeySpace}, keyEnter, keyDown, keyEnter)
+	assert.Equal(t, stateToolSelection, m.state)
+	assert.True(t, m.initState.SplitByHeading)
+
+	m = sendKeys(t, m, keyLeft)
+	assert.Equal(t, stateImportMode, m.state)
+	assert.Equal(t, 0, m.cursor)
+
+	// 戻った後に選び直した内容で置き換える
+	m = sendKeys(t, m, keyEnter)
+	assert.Equal(t, stateToolSelection, m.state)
+	assert.False(t, m.initState.SplitByHeading)
+
+	m = sendKeys(t, m, keyEsc, keyEsc)
+	assert.Equal(t, stateFileSelection, m.state)
+	assert.True(t, m.fileSelection[0])
+	assert.Empty(t, m.history)
+}
+
+func TestInitModelCustomTool(t *testing.T) {
+	i18n.TestSetupI18n(t)
+	m := newTestInitModel(t, nil)
+	m.state = stateToolSelection
+
+	m = sendKeys(t, m, keyEnter)
+	require.Equal(t, stateCustomTools, m.state)
+
+	m = sendKeys(t, m, keyEnter)
+	require.Equal(t, stateCustomToolInput, m.state)
+
+	// 入力欄では q や a もそのまま入力する
+	m = sendKeys(t, m, runeKeys("aq")...)
+	m = sendKeys(t, m, keyEnter, keyEnter, keyEnter)
+	assert.Equal(t, stateCustomToolInput, m.state)
+	assert.NotEmpty(t, m.customErr)
+
+	m = sendKeys(t, m, tea.KeyMsg{Type: tea.KeyShiftTab})
+	m = sendKeys(t, m, runeKeys(".aq")...)
+	m = sendKeys(t, m, tea.KeyMsg{Type: tea.KeyTab})
+	m = sendKeys(t, m, runeKeys("rules.mdx")...)
+	m = sendKeys(t, m, tea.KeyMsg{Type: tea.KeyBackspace}, keyEnter)
+
+	assert.Equal(t, stateCustomTools, m.state)
+	assert.Equal(t, []CustomTool{{Name: "aq", AIToolPaths: config.AIToolPaths{DirName: ".aq", FileName: "rules.md"}}}, m.initState.CustomTools)
+
+	m = sendKeys(t, m, keyDown, keyEnter)
+	assert.Equal(t, stateConfirmation, m.state)
+	assert.Contains(t, m.View(), filepath.Join(".aq", "rules.md"))
+
+	// 確認画面から戻ると、入力画面を飛ばしてカスタムツールの一覧に戻る
+	m = sendKeys(t, m, keyEsc)
+	assert.Equal(t, stateCustomTools, m.state)
+}
+
+func TestValidateCustomTool(t *testing.T) {
+	i18n.TestSetupI18n(t)
+	m := newTestInitModel(t, nil)
+	m.initState.CustomTools = []CustomTool{{Name: "zed", AIToolPaths: config.AIToolPaths{FileName: "rules.md"}}}
+
+	tests := []struct {
+		name  string
+		tool  CustomTool
+		valid bool
+	}{
+		{name: "valid", tool: CustomTool{Name: "aider", AIToolPaths: config.AIToolPaths{FileName: "CONVENTIONS.md"}}, valid: true},
+		{name: "invalid name", tool: CustomTool{Name: "Aider", AIToolPaths: config.AIToolPaths{FileName: "CONVENTIONS.md"}}},
+		{name: "built-in tool", tool: CustomTool{Name: "claude", AIToolPaths: config.AIToolPaths{FileName: "CLAUDE.md"}}},
+		{name: "duplicate", tool: CustomTool{Name: "zed", AIToolPaths: config.AIToolPaths{FileName: "other.md"}}},
+		{name: "missing file name", tool: CustomTool{Name: "aider"}},
+		{name: "outside the project", tool: CustomTool{Name: "aider", AIToolPaths: config.AIToolPaths{DirName: "..", FileName: "rules.md"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.valid, m.validateCustomTool(tt.tool) == "")
+		})
+	}
+}
+
+func TestRenderFileTree(t *testing.T) {
+	lines := renderFileTree([]string{
+		filepath.Join(".system_prompt", "001_default.md"),
+		filepath.Join(".system_prompt", "settings.toml"),
+		"CLAUDE.md",
+	}, func(path string) string {
+		if path == filepath.Join(".system_prompt", "settings.toml") {
+			return " (update)"
+		}
+		return ""
+	})
+
+	assert.Equal(t, []string{
+		"├── .system_prompt/",
+		"│   ├── 001_default.md",
+		"│   └── settings.toml (update)",
+		"└── CLAUDE.md",
+	}, lines)
+}
+
+func TestNewInitModelDetectedTools(t *testing.T) {
+	i18n.TestSetupI18n(t)
+	state := newTestInitState(t)
+	state.OverwriteConfirmed = true
+	state.ExistingFiles = []ExistingFile{
+		{Path: ".clinerules", ToolName: "cline", Content: "Cline", Kind: FileKindPrimary},
+		{Path: "CLAUDE.md", ToolName: "claude", Content: "Claude", Kind: FileKindPrimary},
+		{Path: filepath.Join("api", "AGENTS.md"), ToolName: "agents", Content: "Agents", Kind: FileKindNested},
+	}
+
+	m := newInitModel(state, nil)
+	assert.Equal(t, stateFileSelection, m.state)
+
+	var selected []string
+	for i, tool := range m.allTools {
+		if m.toolSelection[i] {
+			selected = append(selected, tool)
+		}
+	}
+	assert.Equal(t, []string{"agents", "claude", "cline"}, selected)
+	assert.Empty(t, m.outputFileWarnings())
+
+	m.state = stateToolSelection
+	view := m.View()
+	assert.Contains(t, view, "claude (detected)")
+	assert.Contains(t, view, "github_copilot (not detected)")
+
+	// 出力先のファイルがあるツールの選択を解除すると警告する
+	m.cursor = 1
+	m = sendKeys(t, m, tea.KeyMsg{Type: tea.KeySpace})
+	assert.Equal(t, []string{"CLAUDE.md exists, but claude is not selected. It will no longer be generated."}, m.outputFileWarnings())
+	assert.Contains(t, m.View(), "⚠ CLAUDE.md exists")
+
+	// サブディレクトリのファイルは出力先ではないため警告しない
+	m.cursor = 0
+	m = sendKeys(t, m, tea.KeyMsg{Type: tea.KeySpace})
+	assert.Len(t, m.outputFileWarnings(), 1)
+}
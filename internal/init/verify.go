@@ -0,0 +1,210 @@
+package init
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/cateiru/system-prompt-gen/internal/config"
+	"github.com/cateiru/system-prompt-gen/internal/generator"
+	"github.com/cateiru/system-prompt-gen/internal/i18n"
+)
+
+// SignificantLossRatio は元のファイルの行のうち、生成結果に含まれない行の割合がこの値以上の場合に内容の欠落とみなす
+const SignificantLossRatio = 0.1
+
+// DiffOp は差分の行の種類
+type DiffOp string
+
+const (
+	// DiffRemoved は元のファイルにのみ含まれる行
+	DiffRemoved DiffOp = "-"
+	// DiffAdded は生成結果にのみ含まれる行
+	DiffAdded DiffOp = "+"
+)
+
+// DiffLine は差分の 1 行
+type DiffLine struct {
+	Op   DiffOp `json:"op"`
+	Text string `json:"text"`
+}
+
+// VerifyResult は 1 つのツールの元のファイルと生成結果の比較結果
+type VerifyResult struct {
+	Tool string `json:"tool"`
+	// Path は元のファイル（作業ディレクトリからの相対パス）
+	Path string `json:"path"`
+	// Generated が false の場合、ツールが生成の対象になっておらず、元のファイルの内容は全て失われる
+	Generated bool `json:"generated"`
+	// Diff は空行と生成時に追加されるファイル名の見出しを除いた行単位の差分
+	Diff []DiffLine `json:"diff"`
+	// OriginalLines は元のファイルの空行を除いた行数
+	OriginalLines int `json:"original_lines"`
+	// MissingLines は生成結果に含まれない元のファイルの行数
+	MissingLines int `json:"missing_lines"`
+	// ContentLoss は欠落した行の割合が SignificantLossRatio 以上かどうか
+	ContentLoss bool `json:"content_loss"`
+}
+
+// HasContentLoss は results に内容の欠落があるかどうかを返す
+func HasContentLoss(results []VerifyResult) bool {
+	for _, result := range results {
+		if result.ContentLoss {
+			return true
+		}
+	}
+	return false
+}
+
+// Message は比較結果の概要を表すメッセージを返す
+func (result VerifyResult) Message() string {
+	data := map[string]any{"Tool": result.Tool, "Path": result.Path, "Missing": result.MissingLines, "Total": result.OriginalLines}
+	switch {
+	case !result.Generated:
+		return i18n.T("init_verify_not_generated", data)
+	case result.ContentLoss:
+		return i18n.T("init_verify_content_loss", data)
+	case result.MissingLines > 0:
+		return i18n.T("init_verify_missing", data)
+	default:
+		return i18n.T("init_verify_ok", data)
+	}
+}
+
+// RunVerify は作業ディレクトリの .system_prompt の設定で生成した結果と元のファイルを比較する
+func RunVerify() ([]VerifyResult, error) {
+	state, err := NewInitState()
+	if err != nil {
+		return nil, err
+	}
+	return state.Verify()
+}
+
+// Verify は .system_prompt の設定でプロンプトを生成し（ファイルには書き込まない）、
+// FileScanner が見つけた各ツールの出力先のファイルと比較する
+func (state *InitState) Verify() ([]VerifyResult, error) {
+	originals, err := NewFileScanner(state.WorkDir).ScanExistingFiles()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(originals, func(i, j int) bool {
+		return originals[i].ToolName < originals[j].ToolName
+	})
+
+	settings, err := config.LoadSettingsWithOptions(filepath.Join(state.SystemPromptDir, "settings.toml"), config.LoadOptions{ProjectRoot: state.WorkDir})
+	if err != nil {
+		return nil, err
+	}
+	gen := generator.New(settings)
+	targets, err := gen.BuildTargets()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []VerifyResult
+	for _, original := range originals {
+		// 生成結果と同じパスのファイルのみを比較する
+		if original.Kind != FileKindPrimary {
+			continue
+		}
+
+		// 既に生成済みのファイルと比較する場合も同じ見出しを除く
+		target, ok := findTarget(targets, original, state.WorkDir)
+		originalLines := verifyLines(original.Content, target.Files)
+		result := VerifyResult{
+			Tool:          original.ToolName,
+			Path:          original.Path,
+			Generated:     ok,
+			OriginalLines: len(originalLines),
+		}
+
+		if ok {
+			result.Diff = diffLines(originalLines, verifyLines(gen.GeneratePrompt(target.Files), target.Files))
+		} else {
+			for _, line := range originalLines {
+				result.Diff = append(result.Diff, DiffLine{Op: DiffRemoved, Text: line})
+			}
+		}
+
+		for _, line := range result.Diff {
+			if line.Op == DiffRemoved {
+				result.MissingLines++
+			}
+		}
+		result.ContentLoss = result.MissingLines > 0 &&
+			float64(result.MissingLines) >= float64(result.OriginalLines)*SignificantLossRatio
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// findTarget は original と同じパスに出力する original のツールの出力先を返す
+func findTarget(targets []generator.OutputTarget, original ExistingFile, workDir string) (generator.OutputTarget, bool) {
+	originalPath := filepath.Join(workDir, original.Path)
+	for _, target := range targets {
+		targetPath, err := filepath.Abs(target.Path)
+		if err != nil {
+			continue
+		}
+		if target.ToolName == original.ToolName && targetPath == originalPath {
+			return target, true
+		}
+	}
+	return generator.OutputTarget{}, false
+}
+
+// verifyLines は比較に使用する行を返す。空行と、files のファイル名から生成される見出しは除く
+func verifyLines(content string, files []generator.PromptFile) []string {
+	headings := make(map[string]bool)
+	for _, file := range files {
+		headings[fmt.Sprintf("# %s", strings.TrimSuffix(file.Filename, ".md"))] = true
+	}
+
+	var lines []string
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimRightFunc(line, unicode.IsSpace)
+		if line == "" || headings[line] {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// diffLines は最長共通部分列で original と generated を対応付け、一致しない行を返す
+func diffLines(original, generated []string) []DiffLine {
+	// lcs[i][j] は original[i:] と generated[j:] の最長共通部分列の長さ
+	lcs := make([][]int, len(original)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(generated)+1)
+	}
+	for i := len(original) - 1; i >= 0; i-- {
+		for j := len(generated) - 1; j >= 0; j-- {
+			if original[i] == generated[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var diff []DiffLine
+	i, j := 0, 0
+	for i < len(original) || j < len(generated) {
+		switch {
+		case i < len(original) && j < len(generated) && original[i] == generated[j]:
+			i++
+			j++
+		case j < len(generated) && (i == len(original) || lcs[i][j+1] >= lcs[i+1][j]):
+			diff = append(diff, DiffLine{Op: DiffAdded, Text: generated[j]})
+			j++
+		default:
+			diff = append(diff, DiffLine{Op: DiffRemoved, Text: original[i]})
+			i++
+		}
+	}
+	return diff
+}
@@ -0,0 +1,75 @@
+package init
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffLines(t *testing.T) {
+	diff := diffLines([]string{"a", "b", "c"}, []string{"a", "x", "c", "d"})
+
+	assert.Equal(t, []DiffLine{
+		{Op: DiffAdded, Text: "x"},
+		{Op: DiffRemoved, Text: "b"},
+		{Op: DiffAdded, Text: "d"},
+	}, diff)
+}
+
+func TestVerify(t *testing.T) {
+	state := newTestInitState(t)
+	t.Chdir(state.WorkDir)
+	require.NoError(t, os.WriteFile(filepath.Join(state.WorkDir, "CLAUDE.md"), []byte("# Rules\n\nUse gofmt.\n\nClaude only.\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(state.WorkDir, ".clinerules"), []byte("# Rules\n\nUse gofmt.\n\nCline only.\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(state.WorkDir, "AGENTS.md"), []byte("Agents only.\n"), 0644))
+
+	_, err := state.runNonInteractive(Options{
+		Tools:  []string{"claude", "cline"},
+		Import: []string{"CLAUDE.md", ".clinerules"},
+		Shared: true,
+	})
+	require.NoError(t, err)
+
+	results, err := state.Verify()
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	// agents は生成の対象ではないため、内容が全て失われる
+	assert.Equal(t, VerifyResult{
+		Tool:          "agents",
+		Path:          "AGENTS.md",
+		Diff:          []DiffLine{{Op: DiffRemoved, Text: "Agents only."}},
+		OriginalLines: 1,
+		MissingLines:  1,
+		ContentLoss:   true,
+	}, results[0])
+
+	// 共通の内容を分けて取り込んだツールは元のファイルと同じ内容になる
+	assert.Equal(t, VerifyResult{Tool: "claude", Path: "CLAUDE.md", Generated: true, OriginalLines: 3}, results[1])
+	assert.Equal(t, VerifyResult{Tool: "cline", Path: ".clinerules", Generated: true, OriginalLines: 3}, results[2])
+	assert.True(t, HasContentLoss(results))
+	assert.False(t, HasContentLoss(results[1:]))
+}
+
+func TestVerifyMinorChanges(t *testing.T) {
+	state := newTestInitState(t)
+	t.Chdir(state.WorkDir)
+	content := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n"
+	require.NoError(t, os.WriteFile(filepath.Join(state.WorkDir, "CLAUDE.md"), []byte(content), 0644))
+
+	_, err := state.runNonInteractive(Options{Tools: []string{"claude"}, Import: []string{"CLAUDE.md"}})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(state.WorkDir, "CLAUDE.md"), []byte(content+"12\n"), 0644))
+
+	results, err := state.Verify()
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+
+	// 欠落した行の割合が SignificantLossRatio 未満の場合は内容の欠落とみなさない
+	assert.Equal(t, 1, results[0].MissingLines)
+	assert.Equal(t, 12, results[0].OriginalLines)
+	assert.False(t, results[0].ContentLoss)
+}
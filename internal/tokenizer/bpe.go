@@ -0,0 +1,232 @@
+package tokenizer
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+//go:generate go run gen.go -o bpe_merges.txt $GOROOT/doc $GOROOT/src/fmt $GOROOT/src/go $GOROOT/src/net/http $GOROOT/src/os $GOROOT/src/strings
+
+// bpeMerges は英語の文書と Go のソースコードから学習した BPE のマージ規則（gen.go で生成する）
+//
+//go:embed bpe_merges.txt
+var bpeMerges []byte
+
+// contractions は英語の短縮形（'s、'll など）として 1 つの単語に分ける接尾辞
+var contractions = []string{"s", "t", "re", "ve", "m", "ll", "d"}
+
+// BPE はバイト単位の BPE でトークン数を数えるトークナイザー。
+// 実際のモデルの語彙とは異なるため、トークン数は近似値となる。
+type BPE struct {
+	ranks map[[2]string]int
+
+	mu    sync.Mutex
+	cache map[string]int
+}
+
+var (
+	defaultBPEOnce sync.Once
+	defaultBPEData *BPE
+	defaultBPEErr  error
+)
+
+// defaultBPE は組み込みのマージ規則の BPE を返す
+func defaultBPE() (*BPE, error) {
+	defaultBPEOnce.Do(func() {
+		defaultBPEData, defaultBPEErr = NewBPE(bpeMerges)
+	})
+	return defaultBPEData, defaultBPEErr
+}
+
+// NewBPE はマージ規則から BPE を作成する。
+// merges の各行は結合する 2 つのトークンを 16 進数で表したもので、先に書かれた規則ほど優先される。
+// 空行と # で始まる行は無視する。
+func NewBPE(merges []byte) (*BPE, error) {
+	bpe := &BPE{
+		ranks: make(map[[2]string]int),
+		cache: make(map[string]int),
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(merges))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid BPE merge at line %d: %q", line, text)
+		}
+		left, err := hex.DecodeString(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid BPE merge at line %d: %w", line, err)
+		}
+		right, err := hex.DecodeString(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid BPE merge at line %d: %w", line, err)
+		}
+
+		pair := [2]string{string(left), string(right)}
+		if _, ok := bpe.ranks[pair]; !ok {
+			bpe.ranks[pair] = len(bpe.ranks)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return bpe, nil
+}
+
+// Count は text のトークン数を返す
+func (bpe *BPE) Count(text string) int {
+	count := 0
+	for _, word := range Split(text) {
+		count += bpe.countWord(word)
+	}
+	return count
+}
+
+func (bpe *BPE) countWord(word string) int {
+	bpe.mu.Lock()
+	count, ok := bpe.cache[word]
+	bpe.mu.Unlock()
+	if ok {
+		return count
+	}
+
+	parts := make([]string, len(word))
+	for i := range len(word) {
+		parts[i] = word[i : i+1]
+	}
+
+	// 優先度の最も高い隣り合うトークンの組を結合できなくなるまで繰り返す
+	for len(parts) > 1 {
+		best, bestRank := -1, 0
+		for i := 0; i < len(parts)-1; i++ {
+			if rank, ok := bpe.ranks[[2]string{parts[i], parts[i+1]}]; ok && (best < 0 || rank < bestRank) {
+				best, bestRank = i, rank
+			}
+		}
+		if best < 0 {
+			break
+		}
+		parts[best] += parts[best+1]
+		parts = append(parts[:best+1], parts[best+2:]...)
+	}
+
+	// 文字の途中のバイトのみのトークンは数えない。学習データにない文字（日本語など）は、
+	// UTF-8 のバイト数ではなくおおよそ 1 文字を 1 トークンとして数える
+	count = 0
+	for _, part := range parts {
+		if containsRuneStart(part) {
+			count++
+		}
+	}
+
+	bpe.mu.Lock()
+	bpe.cache[word] = count
+	bpe.mu.Unlock()
+	return count
+}
+
+// containsRuneStart は s が UTF-8 の文字の先頭のバイトを含むかを返す
+func containsRuneStart(s string) bool {
+	for i := range len(s) {
+		if utf8.RuneStart(s[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Split は BPE を適用する前に text を単語、数字、記号、空白に分ける。
+// 分け方は一般的な言語モデルのトークナイザーの前処理に合わせている。
+func Split(text string) []string {
+	var words []string
+	for len(text) > 0 {
+		n := nextWord(text)
+		words = append(words, text[:n])
+		text = text[n:]
+	}
+	return words
+}
+
+// nextWord は s の先頭の単語のバイト数を返す
+func nextWord(s string) int {
+	r, size := utf8.DecodeRuneInString(s)
+
+	// 短縮形（'s、'll など）
+	if r == '\'' {
+		for _, suffix := range contractions {
+			if len(s) > len(suffix) && strings.EqualFold(s[1:1+len(suffix)], suffix) {
+				return 1 + len(suffix)
+			}
+		}
+	}
+
+	// 単語（先頭の 1 文字の空白または記号を含む）
+	if unicode.IsLetter(r) {
+		return size + runLength(s[size:], unicode.IsLetter, -1)
+	}
+	if !isNewline(r) && !unicode.IsNumber(r) {
+		if next, _ := utf8.DecodeRuneInString(s[size:]); unicode.IsLetter(next) {
+			return size + runLength(s[size:], unicode.IsLetter, -1)
+		}
+	}
+
+	// 3 桁までの数字
+	if unicode.IsNumber(r) {
+		return size + runLength(s[size:], unicode.IsNumber, 2)
+	}
+
+	// 記号の連続（先頭の 1 文字の空白と末尾の改行を含む）
+	n := 0
+	if r == ' ' {
+		n = size
+	}
+	if punct := runLength(s[n:], isPunct, -1); punct > 0 {
+		n += punct
+		return n + runLength(s[n:], isNewline, -1)
+	}
+
+	// 空白の連続。改行を含む場合は最後の改行まで、続く単語がある場合は最後の 1 文字を単語に含める
+	space := runLength(s, unicode.IsSpace, -1)
+	if last := strings.LastIndexAny(s[:space], "\r\n"); last >= 0 {
+		return last + 1
+	}
+	if space > 1 && space < len(s) {
+		_, lastSize := utf8.DecodeLastRuneInString(s[:space])
+		return space - lastSize
+	}
+	return space
+}
+
+// runLength は s の先頭から match に合致する文字が続くバイト数を返す。limit が 0 以上の場合は最大 limit 文字とする
+func runLength(s string, match func(rune) bool, limit int) int {
+	n := 0
+	for count := 0; n < len(s) && count != limit; count++ {
+		r, size := utf8.DecodeRuneInString(s[n:])
+		if !match(r) {
+			break
+		}
+		n += size
+	}
+	return n
+}
+
+func isNewline(r rune) bool {
+	return r == '\r' || r == '\n'
+}
+
+func isPunct(r rune) bool {
+	return !unicode.IsSpace(r) && !unicode.IsLetter(r) && !unicode.IsNumber(r)
+}
@@ -0,0 +1,182 @@
+//go:build ignore
+
+// gen.go は引数のファイルとディレクトリ（.go、.md、.html、.txt のファイル）から BPE のマージ規則を学習する。
+//
+//	go run gen.go -o bpe_merges.txt [-merges N] PATH...
+package main
+
+import (
+	"container/heap"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cateiru/system-prompt-gen/internal/tokenizer"
+)
+
+var corpusExtensions = map[string]bool{".go": true, ".md": true, ".html": true, ".txt": true}
+
+type pair [2]string
+
+type word struct {
+	parts []string
+	count int
+}
+
+// pairItem は出現回数の多い組を取り出すためのヒープの要素。count が古い要素は取り出す際に捨てる
+type pairItem struct {
+	pair  pair
+	count int
+}
+
+type pairHeap []pairItem
+
+func (h pairHeap) Len() int { return len(h) }
+func (h pairHeap) Less(i, j int) bool {
+	if h[i].count != h[j].count {
+		return h[i].count > h[j].count
+	}
+	if h[i].pair[0] != h[j].pair[0] {
+		return h[i].pair[0] < h[j].pair[0]
+	}
+	return h[i].pair[1] < h[j].pair[1]
+}
+func (h pairHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *pairHeap) Push(x any)   { *h = append(*h, x.(pairItem)) }
+func (h *pairHeap) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+func main() {
+	output := flag.String("o", "bpe_merges.txt", "output file")
+	merges := flag.Int("merges", 8000, "number of merges to learn")
+	minCount := flag.Int("min-count", 3, "ignore words that appear fewer times than this")
+	flag.Parse()
+
+	counts := make(map[string]int)
+	for _, root := range flag.Args() {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if name := d.Name(); path != root && (name == "testdata" || strings.HasPrefix(name, ".")) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !corpusExtensions[filepath.Ext(path)] {
+				return nil
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			for _, w := range tokenizer.Split(string(content)) {
+				counts[w]++
+			}
+			return nil
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	var keys []string
+	for w, count := range counts {
+		if count >= *minCount && len(w) > 1 {
+			keys = append(keys, w)
+		}
+	}
+	sort.Strings(keys)
+
+	words := make([]word, len(keys))
+	pairCounts := make(map[pair]int)
+	pairWords := make(map[pair]map[int]bool)
+	for i, w := range keys {
+		parts := make([]string, len(w))
+		for j := range len(w) {
+			parts[j] = w[j : j+1]
+		}
+		words[i] = word{parts: parts, count: counts[w]}
+		for j := 0; j < len(parts)-1; j++ {
+			p := pair{parts[j], parts[j+1]}
+			pairCounts[p] += counts[w]
+			if pairWords[p] == nil {
+				pairWords[p] = make(map[int]bool)
+			}
+			pairWords[p][i] = true
+		}
+	}
+
+	h := &pairHeap{}
+	for p, count := range pairCounts {
+		*h = append(*h, pairItem{pair: p, count: count})
+	}
+	heap.Init(h)
+
+	var learned []pair
+	for len(learned) < *merges && h.Len() > 0 {
+		item := heap.Pop(h).(pairItem)
+		if pairCounts[item.pair] != item.count || item.count < 2 {
+			continue
+		}
+		best := item.pair
+		learned = append(learned, best)
+
+		changed := make(map[pair]bool)
+		for i := range pairWords[best] {
+			w := &words[i]
+			for j := 0; j < len(w.parts)-1; j++ {
+				pairCounts[pair{w.parts[j], w.parts[j+1]}] -= w.count
+				changed[pair{w.parts[j], w.parts[j+1]}] = true
+			}
+
+			var merged []string
+			for j := 0; j < len(w.parts); j++ {
+				if j < len(w.parts)-1 && w.parts[j] == best[0] && w.parts[j+1] == best[1] {
+					merged = append(merged, best[0]+best[1])
+					j++
+				} else {
+					merged = append(merged, w.parts[j])
+				}
+			}
+			w.parts = merged
+
+			for j := 0; j < len(w.parts)-1; j++ {
+				p := pair{w.parts[j], w.parts[j+1]}
+				pairCounts[p] += w.count
+				changed[p] = true
+				if pairWords[p] == nil {
+					pairWords[p] = make(map[int]bool)
+				}
+				pairWords[p][i] = true
+			}
+		}
+		delete(pairWords, best)
+
+		for p := range changed {
+			if pairCounts[p] > 0 {
+				heap.Push(h, pairItem{pair: p, count: pairCounts[p]})
+			}
+		}
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "# BPE merges generated by gen.go (%d merges). Do not edit.\n", len(learned))
+	for _, p := range learned {
+		fmt.Fprintf(&out, "%s %s\n", hex.EncodeToString([]byte(p[0])), hex.EncodeToString([]byte(p[1])))
+	}
+	if err := os.WriteFile(*output, []byte(out.String()), 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,88 @@
+// Package tokenizer はプロンプトのトークン数を見積もる
+package tokenizer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// DefaultName は名前を指定しない場合に使用するトークナイザー
+const DefaultName = "bpe"
+
+// Tokenizer はテキストのトークン数を数える
+type Tokenizer interface {
+	// Count は text のトークン数を返す
+	Count(text string) int
+}
+
+// Factory は Tokenizer を作成する
+type Factory func() (Tokenizer, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Factory{
+		"bpe":   func() (Tokenizer, error) { return defaultBPE() },
+		"chars": func() (Tokenizer, error) { return charsTokenizer{}, nil },
+	}
+)
+
+// Register は name でトークナイザーを登録する。同じ名前のトークナイザーは置き換える
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = factory
+}
+
+// Names は登録されているトークナイザーの名前を名前順に返す
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New は name のトークナイザーを作成する。name が空の場合は DefaultName を使用する
+func New(name string) (Tokenizer, error) {
+	if name == "" {
+		name = DefaultName
+	}
+
+	registryMu.RLock()
+	factory, ok := registry[name]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown tokenizer %q (available: %s)", name, strings.Join(Names(), ", "))
+	}
+	return factory()
+}
+
+// Stats はテキストの大きさ
+type Stats struct {
+	Tokens int `json:"tokens"`
+	Words  int `json:"words"`
+	Bytes  int `json:"bytes"`
+}
+
+// Measure は tokenizer で text のトークン数、単語数、バイト数を数える
+func Measure(tokenizer Tokenizer, text string) Stats {
+	return Stats{
+		Tokens: tokenizer.Count(text),
+		Words:  len(strings.Fields(text)),
+		Bytes:  len(text),
+	}
+}
+
+// charsTokenizer は 4 文字を 1 トークンとして数える簡易的なトークナイザー
+type charsTokenizer struct{}
+
+func (charsTokenizer) Count(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
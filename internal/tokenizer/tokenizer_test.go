@@ -0,0 +1,91 @@
+package tokenizer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "words", text: "Hello, world!", want: []string{"Hello", ",", " world", "!"}},
+		{name: "contractions", text: "it's we'll", want: []string{"it", "'s", " we", "'ll"}},
+		{name: "numbers", text: "v12345", want: []string{"v", "123", "45"}},
+		{name: "newlines", text: "# Title\n\n- item", want: []string{"#", " Title", "\n\n", "-", " item"}},
+		{name: "indent", text: "\n    return nil", want: []string{"\n", "   ", " return", " nil"}},
+		{name: "punctuation", text: "foo() {}\n", want: []string{"foo", "()", " {}\n"}},
+		{name: "japanese", text: "日本語のテキスト", want: []string{"日本語のテキスト"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Split(tt.text))
+		})
+	}
+}
+
+func TestNewBPE(t *testing.T) {
+	// "ab" を先に結合し、"abc" は 2 番目の規則で結合する
+	bpe, err := NewBPE([]byte("# comment\n61 62\n\n6162 63\n"))
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, bpe.Count("abc"))
+	assert.Equal(t, 2, bpe.Count("abd"))
+	assert.Equal(t, 3, bpe.Count("abc abc"))
+	assert.Equal(t, 0, bpe.Count(""))
+
+	_, err = NewBPE([]byte("61\n"))
+	assert.Error(t, err)
+	_, err = NewBPE([]byte("61 zz\n"))
+	assert.Error(t, err)
+}
+
+func TestDefaultBPE(t *testing.T) {
+	tokenizer, err := New("")
+	require.NoError(t, err)
+
+	count := tokenizer.Count("Hello, world! This is a test of the tokenizer.")
+	assert.Greater(t, count, 8)
+	assert.Less(t, count, 20)
+
+	// 学習データにない日本語は UTF-8 のバイト数ではなく、おおよそ文字数で数える
+	count = tokenizer.Count("このプロジェクトではGoを使用します。テストを書いてください。")
+	assert.Greater(t, count, 15)
+	assert.LessOrEqual(t, count, 30)
+	assert.LessOrEqual(t, tokenizer.Count("日本語のテキスト"), 8)
+}
+
+func TestNew(t *testing.T) {
+	assert.Equal(t, []string{"bpe", "chars"}, Names())
+
+	chars, err := New("chars")
+	require.NoError(t, err)
+	assert.Equal(t, 2, chars.Count("abcdefg"))
+	assert.Equal(t, 1, chars.Count("日本語"))
+
+	_, err = New("unknown")
+	assert.EqualError(t, err, `unknown tokenizer "unknown" (available: bpe, chars)`)
+}
+
+type wordTokenizer struct{}
+
+func (wordTokenizer) Count(text string) int {
+	return len(Split(text))
+}
+
+func TestRegister(t *testing.T) {
+	Register("test-words", func() (Tokenizer, error) { return wordTokenizer{}, nil })
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "test-words")
+		registryMu.Unlock()
+	})
+
+	tokenizer, err := New("test-words")
+	require.NoError(t, err)
+	assert.Equal(t, Stats{Tokens: 4, Words: 2, Bytes: 13}, Measure(tokenizer, "Hello, world!"))
+}
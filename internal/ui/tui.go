@@ -37,13 +37,19 @@ const (
 	stateError
 )
 
+// ProfileLoader は指定されたプロファイルを適用した設定を読み込む
+type ProfileLoader func(name string) (*config.Settings, error)
+
 type model struct {
-	settings  *config.Settings
-	generator *generator.Generator
-	files     []generator.PromptFile
-	state     state
-	err       error
-	content   string
+	settings    *config.Settings
+	generator   *generator.Generator
+	files       []generator.PromptFile
+	state       state
+	err         error
+	outputFiles []string
+	loadProfile ProfileLoader
+	// budgetWarnings は max_tokens/max_bytes を超える出力先
+	budgetWarnings []generator.BudgetViolation
 }
 
 type generateMsg struct {
@@ -78,7 +84,8 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Quit
 		case "enter", " ":
 			if m.state == stateSuccess {
-				if err := m.generator.WriteOutputFiles(m.content); err != nil {
+				// ツールごとの include/exclude やディレクトリマッピングを反映するため Run で書き込む
+				if err := m.generator.Run(); err != nil {
 					m.state = stateError
 					m.err = err
 					return m, nil
@@ -90,15 +97,25 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.state = stateLoading
 				return m, generatePrompts(m.generator)
 			}
+		case "p":
+			if m.state != stateLoading && m.canSwitchProfile() {
+				return m.switchProfile()
+			}
 		}
 
 	case generateMsg:
-		if msg.err != nil {
+		// 出力先を決定できない場合は、成功として表示せずにエラーとする
+		err := msg.err
+		if err == nil {
+			m.outputFiles, err = m.generator.GetGeneratedTargets()
+		}
+		if err != nil {
 			m.state = stateError
-			m.err = msg.err
+			m.err = err
 		} else {
 			m.files = msg.files
-			m.content = m.generator.GeneratePrompt(msg.files)
+			// budget_policy が error の場合は書き込み時にエラーとなる
+			m.budgetWarnings, _ = m.generator.CheckBudgets()
 			m.state = stateSuccess
 		}
 	}
@@ -106,6 +123,39 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// canSwitchProfile はプロファイルの切り替えが可能かを返す
+func (m model) canSwitchProfile() bool {
+	return m.loadProfile != nil && len(m.settings.Profiles) > 0
+}
+
+// switchProfile は次のプロファイルの設定を読み込み、プロンプトファイルを再収集する。
+// プロファイル未選択の状態と、定義されている各プロファイルを順番に切り替える。
+func (m model) switchProfile() (tea.Model, tea.Cmd) {
+	profiles := append([]string{""}, m.settings.ProfileNames()...)
+
+	next := profiles[0]
+	for i, name := range profiles {
+		if name == m.settings.Profile {
+			next = profiles[(i+1)%len(profiles)]
+			break
+		}
+	}
+
+	settings, err := m.loadProfile(next)
+	if err != nil {
+		m.state = stateError
+		m.err = err
+		return m, nil
+	}
+
+	m.settings = settings
+	m.generator = generator.New(settings)
+	m.files = nil
+	m.outputFiles = nil
+	m.state = stateLoading
+	return m, generatePrompts(m.generator)
+}
+
 func (m model) View() string {
 	var s strings.Builder
 
@@ -118,27 +168,43 @@ func (m model) View() string {
 		s.WriteString(i18n.T("processing"))
 
 	case stateSuccess:
-		// 出力ファイル一覧の取得
-		outputFiles := m.generator.GetGeneratedTargets()
-
-		// 相対パスに変換
-		relativeOutputFiles := make([]string, len(outputFiles))
-		for i, file := range outputFiles {
+		// 出力ファイル一覧を相対パスに変換
+		relativeOutputFiles := make([]string, len(m.outputFiles))
+		for i, file := range m.outputFiles {
 			relativeOutputFiles[i] = util.ToRelativePath(file)
 		}
 
+		// 入力ディレクトリ（レイヤー）を相対パスに変換
+		layers := m.settings.App.Layers()
+		relativeLayers := make([]string, len(layers))
+		for i, layer := range layers {
+			relativeLayers[i] = util.ToRelativePath(layer)
+		}
+
 		s.WriteString(infoStyle.Render(i18n.T("files_found", map[string]any{
 			"Count":       len(m.files),
-			"InputDir":    util.ToRelativePath(m.settings.App.InputDir),
+			"InputDir":    strings.Join(relativeLayers, ", "),
 			"OutputFiles": strings.Join(relativeOutputFiles, "\n\t- "),
 		})))
 		s.WriteString("\n\n")
 
+		if m.canSwitchProfile() {
+			s.WriteString(m.renderProfile())
+			s.WriteString("\n\n")
+		}
+
 		s.WriteString(i18n.T("detected_files") + "\n")
 		for _, file := range m.files {
 			s.WriteString(fmt.Sprintf("  • %s\n", file.Filename))
 		}
 
+		if len(m.budgetWarnings) > 0 {
+			s.WriteString("\n")
+			for _, warning := range m.budgetWarnings {
+				s.WriteString(fmt.Sprintf("⚠️ %s\n", warning))
+			}
+		}
+
 	case stateError:
 		s.WriteString(errorStyle.Render(i18n.T("error_occurred")))
 		s.WriteString("\n\n")
@@ -150,8 +216,23 @@ func (m model) View() string {
 	return s.String()
 }
 
-func RunInteractive(settings *config.Settings) error {
-	p := tea.NewProgram(initialModel(settings))
+func (m model) renderProfile() string {
+	profile := m.settings.Profile
+	if profile == "" {
+		profile = i18n.T("default_profile")
+	}
+	return i18n.T("current_profile", map[string]any{
+		"Profile": profile,
+	})
+}
+
+// RunInteractive はインタラクティブモードを実行する。
+// loadProfile が指定されている場合、書き込み前にプロファイルを切り替えられる。
+func RunInteractive(settings *config.Settings, loadProfile ProfileLoader) error {
+	m := initialModel(settings)
+	m.loadProfile = loadProfile
+
+	p := tea.NewProgram(m)
 	_, err := p.Run()
 	return err
 }
@@ -25,7 +25,7 @@ func TestInitialModel(t *testing.T) {
 		assert.Equal(t, stateLoading, model.state)
 		assert.Nil(t, model.files)
 		assert.Nil(t, model.err)
-		assert.Empty(t, model.content)
+		assert.Nil(t, model.outputFiles)
 	})
 
 	t.Run("creatable cmd from model", func(t *testing.T) {
@@ -133,7 +133,6 @@ func TestModelUpdate_KeyMessages(t *testing.T) {
 				m.files = []generator.PromptFile{
 					{Filename: "test.md", Content: "test"},
 				}
-				m.content = "test content"
 
 				// 書き込みエラーを発生させるために無効なパスを設定
 				m.settings.App.OutputDir = "/invalid/path/"
@@ -238,7 +237,7 @@ func TestModelUpdate_GenerateMsg(t *testing.T) {
 				} else {
 					assert.Nil(t, m.err)
 					assert.Equal(t, tt.msg.files, m.files)
-					assert.NotEmpty(t, m.content)
+					assert.NotEmpty(t, m.outputFiles)
 				}
 			} else {
 				t.Errorf("Expected model type, got %T", newModel)
@@ -375,3 +374,112 @@ func TestPromptFileStruct(t *testing.T) {
 	assert.Equal(t, "file.md", files[0].Filename)
 	assert.Equal(t, "content", files[0].Content)
 }
+
+func TestModelUpdate_SwitchProfile(t *testing.T) {
+	i18n.TestSetupI18n(t)
+
+	settings := config.TestSettings(t)
+	settings.Profiles = map[string]config.ProfileSettings{
+		"public": {},
+	}
+
+	m := initialModel(settings)
+	m.state = stateSuccess
+
+	var loaded []string
+	m.loadProfile = func(name string) (*config.Settings, error) {
+		loaded = append(loaded, name)
+		next := config.TestSettings(t)
+		next.Profiles = settings.Profiles
+		next.Profile = name
+		return next, nil
+	}
+
+	keyMsg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")}
+
+	newModel, cmd := m.Update(keyMsg)
+	switched, ok := newModel.(model)
+	assert.True(t, ok)
+	assert.NotNil(t, cmd)
+	assert.Equal(t, stateLoading, switched.state)
+	assert.Equal(t, "public", switched.settings.Profile)
+
+	// 最後のプロファイルの次はプロファイル未選択に戻る
+	switched.state = stateSuccess
+	newModel, _ = switched.Update(keyMsg)
+	switched, ok = newModel.(model)
+	assert.True(t, ok)
+	assert.Empty(t, switched.settings.Profile)
+
+	assert.Equal(t, []string{"public", ""}, loaded)
+}
+
+func TestModelUpdate_SwitchProfileWithoutProfiles(t *testing.T) {
+	i18n.TestSetupI18n(t)
+
+	m := initialModel(config.TestSettings(t))
+	m.state = stateSuccess
+	m.loadProfile = func(name string) (*config.Settings, error) {
+		t.Fatal("loadProfile should not be called")
+		return nil, nil
+	}
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	assert.Nil(t, cmd)
+}
+
+// TestModelUpdate_WriteUsesRun は書き込みがツールごとの exclude とディレクトリマッピングを反映することを確認する
+func TestModelUpdate_WriteUsesRun(t *testing.T) {
+	i18n.TestSetupI18n(t)
+
+	settings := config.TestSettings(t)
+	settings.App.DirectoryMapping = true
+	settings.Tools["public"] = config.AIToolSettings{
+		Generate:    true,
+		Exclude:     []string{"internal.md"},
+		AIToolPaths: config.AIToolPaths{FileName: "public.md"},
+	}
+	testutil.CreateTestFile(t, filepath.Join(settings.App.InputDir, "rules.md"), "Root rules\n")
+	testutil.CreateTestFile(t, filepath.Join(settings.App.InputDir, "internal.md"), "Internal rules\n")
+	testutil.CreateTestFile(t, filepath.Join(settings.App.InputDir, "api", "api.md"), "API rules\n")
+
+	var m tea.Model = initialModel(settings)
+	m, _ = m.Update(m.Init()())
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Nil(t, m.(model).err)
+	if assert.NotNil(t, cmd) {
+		assert.IsType(t, tea.QuitMsg{}, cmd())
+	}
+
+	outputDir := settings.App.OutputDir
+	testContent := testutil.ReadTestFile(t, filepath.Join(outputDir, "test.md"))
+	assert.Contains(t, testContent, "Internal rules")
+	assert.NotContains(t, testContent, "API rules")
+
+	publicContent := testutil.ReadTestFile(t, filepath.Join(outputDir, "public.md"))
+	assert.Contains(t, publicContent, "Root rules")
+	assert.NotContains(t, publicContent, "Internal rules")
+
+	assert.Contains(t, testutil.ReadTestFile(t, filepath.Join(outputDir, "api", "test.md")), "API rules")
+}
+
+// TestModelUpdate_WriteValidationError は設定に問題がある場合に何も書き込まずエラー状態になることを確認する
+func TestModelUpdate_WriteValidationError(t *testing.T) {
+	i18n.TestSetupI18n(t)
+
+	settings := config.TestSettings(t)
+	settings.Tools["broken"] = config.AIToolSettings{
+		Generate:    true,
+		AIToolPaths: config.AIToolPaths{DirName: "/etc", FileName: "broken.md"},
+	}
+	testutil.CreateTestFile(t, filepath.Join(settings.App.InputDir, "rules.md"), "Root rules\n")
+
+	var m tea.Model = initialModel(settings)
+	m, _ = m.Update(m.Init()())
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	assert.Nil(t, cmd)
+	assert.Equal(t, stateError, m.(model).state)
+	assert.ErrorContains(t, m.(model).err, "SPG001")
+	testutil.AssertFileNotExists(t, filepath.Join(settings.App.OutputDir, "test.md"))
+}
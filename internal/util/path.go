@@ -3,6 +3,7 @@ package util
 import (
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // ToRelativePath converts a full path to a relative path from the current directory.
@@ -22,3 +23,19 @@ func ToRelativePath(fullPath string) string {
 	}
 	return relPath
 }
+
+// ExpandHome expands a leading "~" in the path to the user's home directory.
+// If the home directory cannot be determined, it returns the original path.
+func ExpandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		// フォールバック: ホームディレクトリ取得に失敗した場合は元のパスを返す
+		return path
+	}
+
+	return filepath.Join(homeDir, strings.TrimPrefix(path, "~"))
+}
@@ -147,3 +147,41 @@ func TestToRelativePathWithDotFiles(t *testing.T) {
 		})
 	}
 }
+
+func TestExpandHome(t *testing.T) {
+	homeDir, err := os.UserHomeDir()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{
+			name: "home directory itself",
+			path: "~",
+			want: homeDir,
+		},
+		{
+			name: "path under home directory",
+			path: "~/.config/system-prompt-gen/prompts",
+			want: filepath.Join(homeDir, ".config", "system-prompt-gen", "prompts"),
+		},
+		{
+			name: "absolute path is unchanged",
+			path: "/tmp/prompts",
+			want: "/tmp/prompts",
+		},
+		{
+			name: "tilde in the middle is unchanged",
+			path: "shared/~prompts",
+			want: "shared/~prompts",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, ExpandHome(tt.path))
+		})
+	}
+}